@@ -46,21 +46,43 @@ type EFKStackSpec struct {
 	// Configuration globale
 	// +optional
 	Global GlobalSpec `json:"global,omitempty"`
+
+	// Politique appliquée à la suppression de l'EFKStack :
+	// "Delete" désinstalle les releases Helm et supprime les PVCs Elasticsearch,
+	// "RetainData" désinstalle les releases mais conserve les PVCs,
+	// "Retain" conserve les releases et les données
+	// +kubebuilder:validation:Enum=Delete;Retain;RetainData
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
+const (
+	// DeletionPolicyDelete supprime les releases Helm et les données
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyRetain conserve les releases Helm et les données
+	DeletionPolicyRetain = "Retain"
+	// DeletionPolicyRetainData supprime les releases Helm mais conserve les PVCs
+	DeletionPolicyRetainData = "RetainData"
+)
+
 // ElasticsearchSpec defines the Elasticsearch configuration
 type ElasticsearchSpec struct {
 	// Version d'Elasticsearch
 	// +kubebuilder:validation:Required
 	Version string `json:"version"`
 
+	// Image du conteneur (dépôt, tag, digest), par défaut l'image officielle de la version
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
 	// Mode de déploiement : "singleton" (single node) ou "cluster" (multi-node)
 	// +kubebuilder:validation:Enum=singleton;cluster
 	// +kubebuilder:default=cluster
 	// +optional
 	Mode string `json:"mode,omitempty"`
 
-	// Nombre de replicas (ignoré en mode singleton, forcé à 1)
+	// Nombre de replicas (ignoré en mode singleton, forcé à 1, et lorsque nodeSets est renseigné)
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=3
 	Replicas int32 `json:"replicas"`
@@ -69,18 +91,66 @@ type ElasticsearchSpec struct {
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// Part de la mémoire des nœuds (limite, à défaut requête) allouée au heap de la JVM, en pourcentage.
+	// Le heap est plafonné à 31g pour conserver les compressed oops
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=90
+	// +optional
+	HeapPercent int32 `json:"heapPercent,omitempty"`
+
+	// Options ajoutées à ES_JAVA_OPTS (e.g., -XX:+HeapDumpOnOutOfMemoryError). Une paire -Xms/-Xmx
+	// de même taille remplace le heap calculé à partir de heapPercent
+	// +optional
+	JVMOptions []string `json:"jvmOptions,omitempty"`
+
 	// Configuration du stockage
 	// +optional
 	Storage StorageSpec `json:"storage,omitempty"`
 
+	// Groupes de nœuds (master, hot, warm, cold, ingest...), chacun déployé dans son propre StatefulSet.
+	// Remplace replicas en mode cluster ; resources, storage, nodeSelector et tolerations servent de valeurs par défaut
+	// +optional
+	NodeSets []NodeSetSpec `json:"nodeSets,omitempty"`
+
 	// Configuration de sécurité
 	// +optional
 	Security SecuritySpec `json:"security,omitempty"`
 
-	// Configuration additionnelle (clés-valeurs)
+	// Settings ajoutés à elasticsearch.yml (e.g., indices.memory.index_buffer_size: "20%"), les listes
+	// en valeurs séparées par des virgules. Les settings gérés par l'opérateur (cluster.name, node.name,
+	// node.roles, discovery.*, network.host, path.*, xpack.security.*) sont refusés.
+	// Une modification redémarre les nœuds un par un
 	// +optional
 	Config map[string]string `json:"config,omitempty"`
 
+	// Settings dynamiques du cluster (e.g., cluster.routing.allocation.disk.watermark.high: "90%"),
+	// appliqués en persistent par l'API _cluster/settings sans redémarrage et rétablis lorsqu'ils
+	// sont modifiés hors de l'opérateur. Les settings retirés reprennent leur valeur par défaut
+	// +optional
+	ClusterSettings map[string]string `json:"clusterSettings,omitempty"`
+
+	// Secrets dont les clés sont ajoutées au keystore de chaque nœud (credentials de dépôts, secrets
+	// SAML/OIDC). Un changement du contenu des Secrets est rechargé par _nodes/reload_secure_settings,
+	// les settings non rechargeables prenant effet au prochain redémarrage des nœuds
+	// +optional
+	SecureSettings []SecureSettingSource `json:"secureSettings,omitempty"`
+
+	// Politique de cycle de vie (ILM) des logs écrits par Fluent Bit
+	// +optional
+	ILM *IndexLifecycleSpec `json:"ilm,omitempty"`
+
+	// Component templates gérés par l'opérateur
+	// +optional
+	ComponentTemplates []ComponentTemplateSpec `json:"componentTemplates,omitempty"`
+
+	// Index templates gérés par l'opérateur
+	// +optional
+	IndexTemplates []IndexTemplateSpec `json:"indexTemplates,omitempty"`
+
+	// Dépôt de snapshots et snapshots planifiés (SLM)
+	// +optional
+	Snapshot *SnapshotSpec `json:"snapshot,omitempty"`
+
 	// NodeSelector pour planifier les pods sur des nœuds spécifiques
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
@@ -96,11 +166,15 @@ type FluentBitSpec struct {
 	// +kubebuilder:validation:Required
 	Version string `json:"version"`
 
+	// Image du conteneur (dépôt, tag, digest), par défaut l'image officielle de la version
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
 	// Ressources (CPU, mémoire)
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
-	// Configuration Fluent Bit
+	// Pipeline Fluent Bit (inputs, filtres, outputs) rendu par l'opérateur dans fluent-bit.conf
 	// +optional
 	Config FluentBitConfig `json:"config,omitempty"`
 
@@ -114,31 +188,16 @@ type FluentBitSpec struct {
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 }
 
-// FluentBitConfig defines Fluent Bit configuration options
-type FluentBitConfig struct {
-	// Input configuration (JSON string)
-	// +optional
-	Input string `json:"input,omitempty"`
-
-	// Filter configuration (JSON string)
-	// +optional
-	Filter string `json:"filter,omitempty"`
-
-	// Output configuration (JSON string)
-	// +optional
-	Output string `json:"output,omitempty"`
-
-	// Service configuration (JSON string)
-	// +optional
-	Service string `json:"service,omitempty"`
-}
-
 // KibanaSpec defines the Kibana configuration
 type KibanaSpec struct {
 	// Version de Kibana
 	// +kubebuilder:validation:Required
 	Version string `json:"version"`
 
+	// Image du conteneur (dépôt, tag, digest), par défaut l'image officielle de la version
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
 	// Nombre de replicas
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=2
@@ -193,13 +252,43 @@ type SecuritySpec struct {
 	// +kubebuilder:default=true
 	AuthEnabled bool `json:"authEnabled,omitempty"`
 
-	// Secret contenant les certificats TLS
+	// Secret contenant les certificats TLS (keystore elasticsearch.p12 pour la couche transport).
+	// S'il est vide, l'opérateur génère une CA et les certificats HTTP et transport, et les renouvelle
 	// +optional
 	TLSSecretName string `json:"tlsSecretName,omitempty"`
 
-	// Secret contenant les credentials
+	// Issuer cert-manager signant les certificats d'Elasticsearch et de Kibana à la place de la CA de l'opérateur
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// Secret contenant le mot de passe de l'utilisateur elastic (clé password).
+	// S'il est vide, l'opérateur le génère ; les mots de passe de Kibana et Fluent Bit sont toujours générés
 	// +optional
 	AuthSecretName string `json:"authSecretName,omitempty"`
+
+	// Intervalle de rotation des mots de passe générés (ex. 720h). Sans intervalle, ils ne sont
+	// renouvelés qu'à la demande, avec l'annotation efk.crds.io/rotate-credentials
+	// +optional
+	PasswordRotationInterval *metav1.Duration `json:"passwordRotationInterval,omitempty"`
+
+	// Namespaces autorisés à déclarer des ElasticsearchUsers et ElasticsearchRoles pour cette stack,
+	// en plus du namespace de la stack
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowsNamespace reports whether the ElasticsearchUsers and ElasticsearchRoles of a namespace can
+// use the security API of a stack in stackNamespace
+func (s *SecuritySpec) AllowsNamespace(stackNamespace, namespace string) bool {
+	if namespace == stackNamespace {
+		return true
+	}
+	for _, allowed := range s.AllowedNamespaces {
+		if allowed == namespace {
+			return true
+		}
+	}
+	return false
 }
 
 // IngressSpec defines Ingress configuration
@@ -232,16 +321,23 @@ type IngressTLS struct {
 	SecretName string `json:"secretName,omitempty"`
 }
 
-// GlobalSpec defines global configuration
+// GlobalSpec defines the configuration shared by the components. A setting of a component
+// always takes precedence over the global one
 type GlobalSpec struct {
-	// Storage class par défaut
+	// Storage class par défaut des volumes Elasticsearch, surchargée par spec.elasticsearch.storage.storageClassName
+	// et par le stockage des nodeSets
 	// +optional
 	StorageClass string `json:"storageClass,omitempty"`
 
-	// Registry d'images
+	// Registry d'images de tous les composants (e.g., registry.example.com), à la place de docker.elastic.co
+	// et docker.io. Ignorée lorsque le dépôt d'une image surchargée commence par un registry
 	// +optional
 	ImageRegistry string `json:"imageRegistry,omitempty"`
 
+	// Secrets d'authentification au registry, ajoutés aux pods de tous les composants
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// Configuration TLS globale
 	// +optional
 	TLS TLSSpec `json:"tls,omitempty"`
@@ -249,13 +345,41 @@ type GlobalSpec struct {
 
 // TLSSpec defines TLS configuration
 type TLSSpec struct {
-	// Activer TLS
+	// Servir Kibana en HTTPS avec un certificat signé par la CA de la stack (ou par issuerRef).
+	// Nécessite les certificats gérés par l'opérateur ; TLS d'Elasticsearch reste piloté par
+	// spec.elasticsearch.security.tlsEnabled
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
 
-	// Secret contenant les certificats
+	// Secret TLS de l'Ingress Kibana lorsque spec.kibana.ingress.tls est vide
 	// +optional
 	SecretName string `json:"secretName,omitempty"`
+
+	// Issuer cert-manager par défaut des composants, surchargé par spec.elasticsearch.security.issuerRef
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+}
+
+// ImageSpec overrides the container image of a component
+type ImageSpec struct {
+	// Dépôt de l'image (e.g., elasticsearch/elasticsearch ou registry.example.com/elastic/elasticsearch).
+	// Un dépôt commençant par un registry n'est pas préfixé par spec.global.imageRegistry
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Tag de l'image, par défaut la version du composant. Le tag doit correspondre à cette version
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest de l'image, prioritaire sur le tag
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Politique de téléchargement de l'image
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
 }
 
 // EFKStackStatus defines the observed state of EFKStack
@@ -299,6 +423,50 @@ type ElasticsearchStatus struct {
 	// +optional
 	URL string `json:"url,omitempty"`
 
+	// Santé du cluster remontée par _cluster/health (green, yellow, red)
+	// +optional
+	Health string `json:"health,omitempty"`
+
+	// Nombre de shards actifs
+	// +optional
+	ActiveShards int32 `json:"activeShards,omitempty"`
+
+	// Nombre de shards non assignés
+	// +optional
+	UnassignedShards int32 `json:"unassignedShards,omitempty"`
+
+	// État des templates déclarés dans la spec
+	// +optional
+	Templates []TemplateStatus `json:"templates,omitempty"`
+
+	// Settings dynamiques du cluster appliqués par l'opérateur
+	// +optional
+	ClusterSettings *ClusterSettingsStatus `json:"clusterSettings,omitempty"`
+
+	// Rechargements des secure settings du keystore
+	// +optional
+	SecureSettings *SecureSettingsStatus `json:"secureSettings,omitempty"`
+
+	// État des snapshots
+	// +optional
+	Snapshot *SnapshotStatus `json:"snapshot,omitempty"`
+
+	// Pods de chaque groupe de nœuds
+	// +optional
+	NodeSets []NodeSetStatus `json:"nodeSets,omitempty"`
+
+	// Retrait de nœuds en cours, présent jusqu'à la réduction du StatefulSet
+	// +optional
+	ScaleDown *ScaleDownStatus `json:"scaleDown,omitempty"`
+
+	// Certificats TLS générés par l'opérateur
+	// +optional
+	Certificates *CertificatesStatus `json:"certificates,omitempty"`
+
+	// Credentials des utilisateurs intégrés gérés par l'opérateur
+	// +optional
+	Credentials *CredentialsStatus `json:"credentials,omitempty"`
+
 	// Message d'erreur ou d'information
 	// +optional
 	Message string `json:"message,omitempty"`
@@ -350,6 +518,7 @@ type KibanaStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 //+kubebuilder:printcolumn:name="Elasticsearch",type="string",JSONPath=".status.elasticsearch.state"
+//+kubebuilder:printcolumn:name="Health",type="string",JSONPath=".status.elasticsearch.health"
 //+kubebuilder:printcolumn:name="FluentBit",type="string",JSONPath=".status.fluentBit.state"
 //+kubebuilder:printcolumn:name="Kibana",type="string",JSONPath=".status.kibana.state"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
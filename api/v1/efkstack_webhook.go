@@ -0,0 +1,250 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var efkstacklog = logf.Log.WithName("efkstack-resource")
+
+const (
+	// DefaultElasticsearchReplicas est le nombre de nœuds Elasticsearch en mode cluster
+	DefaultElasticsearchReplicas int32 = 3
+	// DefaultKibanaReplicas est le nombre de replicas Kibana
+	DefaultKibanaReplicas int32 = 2
+	// DefaultStorageSize est la taille du volume Elasticsearch (valeur par défaut du chart)
+	DefaultStorageSize = "100Gi"
+)
+
+// DefaultFluentBitTolerations returns the tolerations applied to the Fluent Bit DaemonSet
+// when none are specified, so that logs are collected on every node including tainted ones
+func DefaultFluentBitTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		{
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+		{
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectPreferNoSchedule,
+		},
+	}
+}
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of EFKStack
+func (r *EFKStack) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-logging-efk-crds-io-v1-efkstack,mutating=true,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=efkstacks,verbs=create;update,versions=v1,name=mefkstack.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &EFKStack{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *EFKStack) Default() {
+	efkstacklog.Info("default", "name", r.Name)
+
+	es := &r.Spec.Elasticsearch
+	if es.Mode == "" {
+		es.Mode = "cluster"
+	}
+	if es.Mode == "singleton" {
+		es.Replicas = 1
+	} else if es.Replicas == 0 {
+		es.Replicas = DefaultElasticsearchReplicas
+	}
+	if es.Storage.Size == "" {
+		es.Storage.Size = DefaultStorageSize
+	}
+
+	if r.Spec.Kibana.Replicas == 0 {
+		r.Spec.Kibana.Replicas = DefaultKibanaReplicas
+	}
+
+	if len(r.Spec.FluentBit.Tolerations) == 0 {
+		r.Spec.FluentBit.Tolerations = DefaultFluentBitTolerations()
+	}
+
+	if r.Spec.DeletionPolicy == "" {
+		r.Spec.DeletionPolicy = DeletionPolicyDelete
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-efkstack,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=efkstacks,verbs=create;update,versions=v1,name=vefkstack.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &EFKStack{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *EFKStack) ValidateCreate() (admission.Warnings, error) {
+	efkstacklog.Info("validate create", "name", r.Name)
+
+	return r.warnings(), r.toInvalidError(r.validateSpec())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *EFKStack) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	efkstacklog.Info("validate update", "name", r.Name)
+
+	// Le retrait du finalizer ne doit jamais être bloqué, même par une spec devenue invalide
+	if r.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	oldStack, ok := old.(*EFKStack)
+	if !ok {
+		return nil, fmt.Errorf("expected an EFKStack but got a %T", old)
+	}
+
+	allErrs := r.validateSpec()
+	allErrs = append(allErrs, r.validateTransition(oldStack)...)
+	return r.warnings(), r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *EFKStack) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSpec checks the constraints that do not depend on the previous object
+func (r *EFKStack) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	esPath := specPath.Child("elasticsearch")
+	kibanaPath := specPath.Child("kibana")
+
+	esVersion, err := semver.NewVersion(r.Spec.Elasticsearch.Version)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(esPath.Child("version"), r.Spec.Elasticsearch.Version, "must be a semantic version"))
+	}
+	kibanaVersion, err := semver.NewVersion(r.Spec.Kibana.Version)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(kibanaPath.Child("version"), r.Spec.Kibana.Version, "must be a semantic version"))
+	}
+	// Kibana doit tourner sur la même version majeure et mineure qu'Elasticsearch
+	if esVersion != nil && kibanaVersion != nil &&
+		(esVersion.Major() != kibanaVersion.Major() || esVersion.Minor() != kibanaVersion.Minor()) {
+		allErrs = append(allErrs, field.Invalid(kibanaPath.Child("version"), r.Spec.Kibana.Version,
+			fmt.Sprintf("must match the major and minor version of Elasticsearch (%d.%d)", esVersion.Major(), esVersion.Minor())))
+	}
+
+	// Un nombre pair de nœuds éligibles master ne tolère pas plus de pannes et risque le split-brain
+	// (vérifié par groupe de nœuds lorsque nodeSets est renseigné)
+	if r.Spec.Elasticsearch.Mode != "singleton" && len(r.Spec.Elasticsearch.NodeSets) == 0 && r.Spec.Elasticsearch.Replicas%2 == 0 {
+		allErrs = append(allErrs, field.Invalid(esPath.Child("replicas"), r.Spec.Elasticsearch.Replicas,
+			"must be odd in cluster mode to keep a master quorum"))
+	}
+
+	if r.Spec.Elasticsearch.Storage.Size != "" {
+		if _, err := resource.ParseQuantity(r.Spec.Elasticsearch.Storage.Size); err != nil {
+			allErrs = append(allErrs, field.Invalid(esPath.Child("storage", "size"), r.Spec.Elasticsearch.Storage.Size, err.Error()))
+		}
+	}
+
+	allErrs = append(allErrs, validateNodeSets(esPath, &r.Spec.Elasticsearch)...)
+	allErrs = append(allErrs, validateElasticsearchConfig(esPath.Child("config"), r.Spec.Elasticsearch.Config)...)
+	allErrs = append(allErrs, validateClusterSettings(esPath.Child("clusterSettings"), &r.Spec.Elasticsearch)...)
+	allErrs = append(allErrs, validateSecureSettings(esPath.Child("secureSettings"), &r.Spec.Elasticsearch)...)
+	allErrs = append(allErrs, validateJVM(esPath, &r.Spec.Elasticsearch)...)
+	allErrs = append(allErrs, validateSecurity(esPath.Child("security"), &r.Spec.Elasticsearch.Security)...)
+	allErrs = append(allErrs, r.Spec.Elasticsearch.ILM.Validate(esPath.Child("ilm"))...)
+	allErrs = append(allErrs, validateTemplates(esPath, &r.Spec.Elasticsearch)...)
+	allErrs = append(allErrs, r.Spec.Elasticsearch.Snapshot.Validate(esPath.Child("snapshot"))...)
+	allErrs = append(allErrs, r.Spec.FluentBit.Config.Validate(specPath.Child("fluentBit", "config"))...)
+
+	if r.Spec.Kibana.Ingress.Enabled && r.Spec.Kibana.Ingress.Host == "" {
+		allErrs = append(allErrs, field.Required(kibanaPath.Child("ingress", "host"), "is required when the ingress is enabled"))
+	}
+
+	return allErrs
+}
+
+// warnings returns the accepted settings that have no effect
+func (r *EFKStack) warnings() admission.Warnings {
+	var warnings admission.Warnings
+	// Le certificat de Kibana est signé par la CA des certificats gérés par l'opérateur
+	security := r.Spec.Elasticsearch.Security
+	if r.Spec.Global.TLS.Enabled && (!security.TLSEnabled || !security.AuthEnabled || security.TLSSecretName != "") {
+		warnings = append(warnings, "spec.global.tls.enabled is ignored: Kibana only serves HTTPS with the certificates "+
+			"generated by the operator (spec.elasticsearch.security.tlsEnabled and authEnabled, without tlsSecretName)")
+	}
+	if warning := heapWarning(&r.Spec.Elasticsearch); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// validateTransition checks the constraints between the previous and the new object
+func (r *EFKStack) validateTransition(old *EFKStack) field.ErrorList {
+	var allErrs field.ErrorList
+	esPath := field.NewPath("spec", "elasticsearch")
+
+	// Pendant une montée de version, les nœuds n'ont pas tous quitté la version enregistrée dans le
+	// status : le contrôleur vérifie le chemin depuis celle-ci, le webhook fait de même
+	from := old.Spec.Elasticsearch.Version
+	if deployed := old.Status.Elasticsearch.Version; deployed != "" {
+		from = deployed
+	}
+	// Les versions invalides sont déjà signalées par validateSpec, et la cible en cours reste toujours acceptée
+	_, fromErr := semver.NewVersion(from)
+	_, newErr := semver.NewVersion(r.Spec.Elasticsearch.Version)
+	if fromErr == nil && newErr == nil && r.Spec.Elasticsearch.Version != old.Spec.Elasticsearch.Version {
+		if err := CheckElasticsearchUpgrade(from, r.Spec.Elasticsearch.Version); err != nil {
+			allErrs = append(allErrs, field.Forbidden(esPath.Child("version"), err.Error()))
+		}
+	}
+
+	// Les PVCs ne peuvent pas être réduits
+	if old.Spec.Elasticsearch.Storage.Size != "" && r.Spec.Elasticsearch.Storage.Size != "" {
+		oldSize, oldErr := resource.ParseQuantity(old.Spec.Elasticsearch.Storage.Size)
+		newSize, newErr := resource.ParseQuantity(r.Spec.Elasticsearch.Storage.Size)
+		if oldErr == nil && newErr == nil && newSize.Cmp(oldSize) < 0 {
+			allErrs = append(allErrs, field.Forbidden(esPath.Child("storage", "size"),
+				fmt.Sprintf("shrinking storage from %s to %s is not supported", old.Spec.Elasticsearch.Storage.Size, r.Spec.Elasticsearch.Storage.Size)))
+		}
+	}
+	allErrs = append(allErrs, validateNodeSetsTransition(esPath, old.Name, &old.Spec.Elasticsearch, &r.Spec.Elasticsearch, &old.Status.Elasticsearch)...)
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *EFKStack) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "EFKStack"}, r.Name, allErrs)
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEFKStackWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "EFKStack Webhook Suite")
+}
+
+var _ = Describe("EFKStack Webhook", func() {
+	var efkStack *EFKStack
+
+	BeforeEach(func() {
+		efkStack = &EFKStack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-efk-stack",
+				Namespace: "default",
+			},
+			Spec: EFKStackSpec{
+				Elasticsearch: ElasticsearchSpec{
+					Version:  "8.11.0",
+					Replicas: 3,
+				},
+				FluentBit: FluentBitSpec{
+					Version: "2.2.0",
+				},
+				Kibana: KibanaSpec{
+					Version:  "8.11.0",
+					Replicas: 1,
+				},
+			},
+		}
+	})
+
+	Context("When defaulting an EFKStack", func() {
+		It("Should fill the unset fields", func() {
+			efkStack.Spec.Elasticsearch.Replicas = 0
+			efkStack.Spec.Kibana.Replicas = 0
+
+			efkStack.Default()
+
+			Expect(efkStack.Spec.Elasticsearch.Mode).To(Equal("cluster"))
+			Expect(efkStack.Spec.Elasticsearch.Replicas).To(Equal(DefaultElasticsearchReplicas))
+			Expect(efkStack.Spec.Elasticsearch.Storage.Size).To(Equal(DefaultStorageSize))
+			Expect(efkStack.Spec.Kibana.Replicas).To(Equal(DefaultKibanaReplicas))
+			Expect(efkStack.Spec.FluentBit.Tolerations).To(Equal(DefaultFluentBitTolerations()))
+			Expect(efkStack.Spec.DeletionPolicy).To(Equal(DeletionPolicyDelete))
+		})
+
+		It("Should force a single replica in singleton mode", func() {
+			efkStack.Spec.Elasticsearch.Mode = "singleton"
+
+			efkStack.Default()
+
+			Expect(efkStack.Spec.Elasticsearch.Replicas).To(Equal(int32(1)))
+		})
+	})
+
+	Context("When validating an EFKStack", func() {
+		It("Should accept a valid stack", func() {
+			efkStack.Default()
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject a Kibana version that does not match Elasticsearch", func() {
+			efkStack.Spec.Kibana.Version = "8.10.4"
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.kibana.version"))
+		})
+
+		It("Should reject an even number of Elasticsearch replicas in cluster mode", func() {
+			efkStack.Spec.Elasticsearch.Replicas = 2
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.replicas"))
+		})
+
+		It("Should reject an enabled ingress without host", func() {
+			efkStack.Spec.Kibana.Ingress.Enabled = true
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.kibana.ingress.host"))
+		})
+
+		It("Should reject an ILM warm phase starting after the retention", func() {
+			efkStack.Spec.Elasticsearch.ILM = &IndexLifecycleSpec{
+				Hot:           ILMHotPhase{Rollover: ILMRollover{MaxAge: "1d", MaxPrimaryShardSize: "50GB"}},
+				Warm:          &ILMWarmPhase{MinAge: "30d"},
+				RetentionDays: 14,
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.ilm.warm.minAge"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.ilm.hot.rollover.maxPrimaryShardSize"))
+
+			efkStack.Spec.Elasticsearch.ILM.Hot.Rollover.MaxPrimaryShardSize = "50gb"
+			efkStack.Spec.Elasticsearch.ILM.Warm.MinAge = "72h"
+			_, err = efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject templates composing an undeclared component template", func() {
+			efkStack.Spec.Elasticsearch.ComponentTemplates = []ComponentTemplateSpec{{Name: "mappings"}, {Name: "mappings"}}
+			efkStack.Spec.Elasticsearch.IndexTemplates = []IndexTemplateSpec{{
+				Name:          "app-logs",
+				IndexPatterns: []string{"app-*"},
+				ComposedOf:    []string{"settings"},
+			}}
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.componentTemplates[1].name"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.indexTemplates[0].composedOf[0]"))
+		})
+
+		It("Should reject a snapshot repository without exactly one type", func() {
+			efkStack.Spec.Elasticsearch.Snapshot = &SnapshotSpec{
+				Repository: SnapshotRepositorySpec{
+					S3:  &S3RepositorySpec{Bucket: "backups"},
+					GCS: &GCSRepositorySpec{Bucket: "backups"},
+				},
+				Policies: []SnapshotPolicySpec{{Name: "nightly", Schedule: "@daily"}},
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("exactly one of s3, gcs, azure, fs"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.snapshot.policies[0].schedule"))
+		})
+
+		It("Should reject an Elasticsearch downgrade", func() {
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.Version = "8.10.0"
+			newStack.Spec.Kibana.Version = "8.10.0"
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("downgrading Elasticsearch"))
+		})
+
+		It("Should only accept supported Elasticsearch upgrade paths", func() {
+			Expect(CheckElasticsearchUpgrade("8.11.0", "8.15.1")).To(Succeed())
+			Expect(CheckElasticsearchUpgrade("7.17.10", "8.11.0")).To(Succeed())
+			Expect(CheckElasticsearchUpgrade("7.10.2", "8.11.0")).To(MatchError(ContainSubstring("requires 7.17 or later")))
+			Expect(CheckElasticsearchUpgrade("7.17.10", "9.0.0")).To(MatchError(ContainSubstring("skips a major version")))
+
+			efkStack.Spec.Elasticsearch.Version = "7.10.2"
+			efkStack.Spec.Kibana.Version = "7.10.2"
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.Version = "8.11.0"
+			newStack.Spec.Kibana.Version = "8.11.0"
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.version"))
+		})
+
+		It("Should check the upgrade path from the version deployed on every node", func() {
+			setVersion := func(stack *EFKStack, version string) {
+				stack.Spec.Elasticsearch.Version = version
+				stack.Spec.Kibana.Version = version
+			}
+			// Montée de version 7.17 -> 8.18 en cours
+			setVersion(efkStack, "8.18.0")
+			efkStack.Status.Elasticsearch.Version = "7.17.0"
+			newStack := efkStack.DeepCopy()
+			setVersion(newStack, "9.0.0")
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("skips a major version"))
+
+			// Une spec déjà passée à 9.0 peut revenir à la cible en cours
+			setVersion(efkStack, "9.0.0")
+			newStack = efkStack.DeepCopy()
+			setVersion(newStack, "8.18.0")
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Les autres modifications d'une stack bloquée restent possibles
+			newStack = efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.Replicas += 2
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should not block the updates of a stack being deleted", func() {
+			// A stack created before a stricter validation, whose finalizer is removed
+			efkStack.Spec.Elasticsearch.Storage.Size = "100Gi"
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.Storage.Size = "50Gi"
+			newStack.Spec.Elasticsearch.Version = "not-a-version"
+			now := metav1.Now()
+			newStack.DeletionTimestamp = &now
+			newStack.Finalizers = nil
+			warnings, err := newStack.ValidateUpdate(efkStack)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("Should reject shrinking the Elasticsearch storage", func() {
+			efkStack.Spec.Elasticsearch.Storage.Size = "100Gi"
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.Storage.Size = "50Gi"
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("shrinking storage"))
+		})
+
+		It("Should accept node tiers keeping a master quorum and the content and hot tiers", func() {
+			efkStack.Spec.Elasticsearch.Replicas = 2
+			efkStack.Spec.Elasticsearch.NodeSets = []NodeSetSpec{
+				{Name: "master", Roles: []NodeRole{NodeRoleMaster}, Replicas: 3},
+				{Name: "hot", Roles: []NodeRole{NodeRoleDataHot, NodeRoleDataContent, NodeRoleIngest}, Replicas: 2},
+				{Name: "warm", Roles: []NodeRole{NodeRoleDataWarm}, Replicas: 2},
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject node sets without master quorum or hot tier", func() {
+			efkStack.Spec.Elasticsearch.NodeSets = []NodeSetSpec{
+				{Name: "master", Roles: []NodeRole{NodeRoleMaster}, Replicas: 2},
+				{Name: "master", Roles: []NodeRole{NodeRoleDataContent, NodeRoleDataWarm}, Replicas: 2},
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.nodeSets[1].name"))
+			Expect(err.Error()).To(ContainSubstring("odd number of master-eligible nodes"))
+			Expect(err.Error()).To(ContainSubstring("data_hot"))
+
+			efkStack.Spec.Elasticsearch.Mode = "singleton"
+			_, err = efkStack.ValidateCreate()
+			Expect(err.Error()).To(ContainSubstring("not supported in singleton mode"))
+		})
+
+		It("Should only remove a node set scaled to zero", func() {
+			efkStack.Spec.Elasticsearch.NodeSets = []NodeSetSpec{
+				{Name: "all", Replicas: 3},
+				{Name: "warm", Roles: []NodeRole{NodeRoleDataWarm}, Replicas: 2, Storage: StorageSpec{Size: "500Gi"}},
+			}
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.NodeSets[1].Storage.Size = "200Gi"
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.nodeSets[warm].storage.size"))
+
+			newStack.Spec.Elasticsearch.NodeSets = newStack.Spec.Elasticsearch.NodeSets[:1]
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err.Error()).To(ContainSubstring("must be scaled to 0 replicas"))
+
+			efkStack.Spec.Elasticsearch.NodeSets[1].Replicas = 0
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err).NotTo(HaveOccurred())
+
+			newStack.Spec.Elasticsearch.NodeSets = nil
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err.Error()).To(ContainSubstring("cannot be added to or removed"))
+		})
+
+		It("Should keep a node set scaled to zero until its nodes are drained and stopped", func() {
+			efkStack.Spec.Elasticsearch.NodeSets = []NodeSetSpec{
+				{Name: "all", Replicas: 3},
+				{Name: "warm", Roles: []NodeRole{NodeRoleDataWarm}, Replicas: 0},
+			}
+			newStack := efkStack.DeepCopy()
+			newStack.Spec.Elasticsearch.NodeSets = newStack.Spec.Elasticsearch.NodeSets[:1]
+
+			// The operator is still moving the shards off the nodes of the set
+			efkStack.Status.Elasticsearch.ScaleDown = &ScaleDownStatus{
+				Nodes:           []string{efkStack.Name + "-elasticsearch-warm-0", efkStack.Name + "-elasticsearch-warm-1"},
+				RemainingShards: 4,
+			}
+			efkStack.Status.Elasticsearch.NodeSets = []NodeSetStatus{{Name: "all", Replicas: 3}, {Name: "warm", Replicas: 2}}
+			_, err := newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("node set warm is being drained"))
+
+			// Drained, but the pods are still stopping
+			efkStack.Status.Elasticsearch.ScaleDown = nil
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("node set warm still runs 2 pods"))
+
+			// The nodes of another set with the same prefix do not block the removal
+			efkStack.Status.Elasticsearch.ScaleDown = &ScaleDownStatus{Nodes: []string{efkStack.Name + "-elasticsearch-warm-2-0"}}
+			efkStack.Status.Elasticsearch.NodeSets[1].Replicas = 0
+			_, err = newStack.ValidateUpdate(efkStack)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should reject a cert-manager issuer together with a provided keystore", func() {
+			efkStack.Spec.Elasticsearch.Security.IssuerRef = &IssuerReference{Name: "platform-ca", Kind: ClusterIssuerKind}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.Security.TLSSecretName = "elasticsearch-certs"
+			efkStack.Spec.Elasticsearch.Security.IssuerRef.Name = ""
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.security.issuerRef.name"))
+			Expect(err.Error()).To(ContainSubstring("cannot be set together with tlsSecretName"))
+		})
+
+		It("Should reject a password rotation interval shorter than an hour", func() {
+			efkStack.Spec.Elasticsearch.Security.PasswordRotationInterval = &metav1.Duration{Duration: 720 * time.Hour}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.Security.PasswordRotationInterval.Duration = 10 * time.Minute
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.security.passwordRotationInterval"))
+		})
+
+		It("Should reject the elasticsearch.yml settings managed by the operator", func() {
+			efkStack.Spec.Elasticsearch.Config = map[string]string{
+				"indices.memory.index_buffer_size": "20%",
+				"action.destructive_requires_name": "true",
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.Config["cluster.name"] = "other"
+			efkStack.Spec.Elasticsearch.Config["discovery.seed_hosts"] = "10.0.0.1"
+			efkStack.Spec.Elasticsearch.Config["node"] = "{roles: [data]}"
+			efkStack.Spec.Elasticsearch.Config["indices memory"] = "20%"
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.config[cluster.name]: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.config[discovery.seed_hosts]: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.config[node]: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.config[indices memory]: Invalid value"))
+			Expect(err.Error()).NotTo(ContainSubstring("config[indices.memory.index_buffer_size]"))
+		})
+
+		It("Should reject the cluster settings managed by the operator or set in config", func() {
+			efkStack.Spec.Elasticsearch.ClusterSettings = map[string]string{
+				"cluster.routing.allocation.disk.watermark.low": "90%",
+				"search.max_buckets":                            "20000",
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.ClusterSettings["cluster.routing.allocation.enable"] = "primaries"
+			efkStack.Spec.Elasticsearch.ClusterSettings["max buckets"] = "10"
+			efkStack.Spec.Elasticsearch.Config = map[string]string{"search.max_buckets": "10000"}
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.clusterSettings[cluster.routing.allocation.enable]: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.clusterSettings[max buckets]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.clusterSettings[search.max_buckets]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("is already set in spec.elasticsearch.config"))
+			Expect(err.Error()).NotTo(ContainSubstring("clusterSettings[cluster.routing.allocation.disk.watermark.low]"))
+		})
+
+		It("Should reject the secure settings filled twice or by Elasticsearch", func() {
+			efkStack.Spec.Elasticsearch.SecureSettings = []SecureSettingSource{
+				{SecretName: "oidc-secrets"},
+				{SecretName: "backup-credentials", Entries: []SecureSettingEntry{{Key: "access_key", Setting: "s3.client.backup.access_key"}}},
+			}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.Snapshot = &SnapshotSpec{Repository: SnapshotRepositorySpec{
+				Name:              "backups",
+				S3:                &S3RepositorySpec{Bucket: "backups"},
+				CredentialsSecret: &corev1.LocalObjectReference{Name: "snapshot-credentials"},
+			}}
+			efkStack.Spec.Elasticsearch.SecureSettings = append(efkStack.Spec.Elasticsearch.SecureSettings,
+				SecureSettingSource{SecretName: "oidc-secrets"},
+				SecureSettingSource{SecretName: "more-credentials", Entries: []SecureSettingEntry{
+					{Key: "access_key", Setting: "s3.client.backup.access_key"},
+					{Key: "bootstrap.password"},
+					{Key: "key", Setting: "s3.client.default.access_key"},
+					{Key: "bad/key"},
+				}},
+			)
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.secureSettings[2].secretName: Duplicate value"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.secureSettings[3].entries[0].setting: Duplicate value"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.secureSettings[3].entries[1].setting: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.secureSettings[3].entries[2].setting: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.secureSettings[3].entries[3].key: Invalid value"))
+		})
+
+		It("Should reject a heap that does not fit the memory of the nodes", func() {
+			efkStack.Spec.Elasticsearch.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}
+			efkStack.Spec.Elasticsearch.JVMOptions = []string{"-XX:+HeapDumpOnOutOfMemoryError"}
+			_, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+
+			efkStack.Spec.Elasticsearch.NodeSets = []NodeSetSpec{
+				{Name: "masters", Roles: []NodeRole{NodeRoleMaster}, Replicas: 3, Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				}},
+				{Name: "data", Replicas: 2},
+			}
+			efkStack.Spec.Elasticsearch.JVMOptions = []string{"-Xms1g", "-Xmx2g", "-XX:+UseG1GC -Xss1m", "-Xmx4x"}
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.jvmOptions[2]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.jvmOptions[3]: Invalid value"))
+			Expect(err.Error()).To(ContainSubstring("-Xms and -Xmx must be set together to the same size"))
+
+			efkStack.Spec.Elasticsearch.JVMOptions = []string{"-Xms2g", "-Xmx2g"}
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.resources: Invalid value: \"2Gi\": the heap of 2048m must leave memory"))
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.nodeSets[0].resources: Invalid value: \"256Mi\": the heap of 2048m"))
+
+			efkStack.Spec.Elasticsearch.JVMOptions = nil
+			_, err = efkStack.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.elasticsearch.nodeSets[0].resources: Invalid value: \"256Mi\": the heap of 128m is below the minimum"))
+			Expect(err.Error()).NotTo(ContainSubstring("spec.elasticsearch.resources"))
+		})
+
+		It("Should warn about a heap above 31g", func() {
+			efkStack.Spec.Elasticsearch.JVMOptions = []string{"-Xms32g", "-Xmx32g"}
+			warnings, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("compressed ordinary object pointers")))
+		})
+
+		It("Should warn when global TLS cannot apply to Kibana", func() {
+			efkStack.Spec.Global.TLS.Enabled = true
+			efkStack.Spec.Elasticsearch.Security = SecuritySpec{TLSEnabled: true, AuthEnabled: true}
+			warnings, err := efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+
+			efkStack.Spec.Elasticsearch.Security.TLSSecretName = "elasticsearch-keystore"
+			warnings, err = efkStack.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(ContainSubstring("spec.global.tls.enabled is ignored")))
+		})
+	})
+})
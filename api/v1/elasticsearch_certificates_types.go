@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ConditionCertificatesReady indique si les certificats TLS générés par l'opérateur sont émis et à jour
+const ConditionCertificatesReady = "CertificatesReady"
+
+// CertificatesStatus reports the expiry of the TLS certificates generated by the operator
+type CertificatesStatus struct {
+	// Secret contenant le certificat de la CA (ca.crt), à monter par les clients d'Elasticsearch
+	CASecretName string `json:"caSecretName"`
+
+	// Expiration de la CA
+	// +optional
+	CANotAfter *metav1.Time `json:"caNotAfter,omitempty"`
+
+	// Expiration du certificat HTTP
+	// +optional
+	HTTPNotAfter *metav1.Time `json:"httpNotAfter,omitempty"`
+
+	// Expiration la plus proche des certificats transport des nœuds
+	// +optional
+	TransportNotAfter *metav1.Time `json:"transportNotAfter,omitempty"`
+}
+
+// Kinds of cert-manager issuers
+const (
+	IssuerKind        = "Issuer"
+	ClusterIssuerKind = "ClusterIssuer"
+)
+
+// IssuerReference references a cert-manager issuer signing the certificates of the stack
+type IssuerReference struct {
+	// Nom de l'issuer
+	Name string `json:"name"`
+
+	// Type de l'issuer : Issuer (dans le namespace de la stack) ou ClusterIssuer
+	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +kubebuilder:default=Issuer
+	Kind string `json:"kind,omitempty"`
+
+	// Groupe API de l'issuer, cert-manager.io par défaut (issuers externes)
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// CertificateIssuer returns the cert-manager issuer of the stack certificates: the one of
+// spec.elasticsearch.security, or else the one of spec.global.tls. It returns nil when the
+// operator signs the certificates with its own CA
+func (s *EFKStackSpec) CertificateIssuer() *IssuerReference {
+	if s.Elasticsearch.Security.IssuerRef != nil {
+		return s.Elasticsearch.Security.IssuerRef
+	}
+	return s.Global.TLS.IssuerRef
+}
+
+// validateSecurity checks the TLS sources and the password rotation of the security settings
+func validateSecurity(securityPath *field.Path, security *SecuritySpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if security.IssuerRef != nil {
+		if security.IssuerRef.Name == "" {
+			allErrs = append(allErrs, field.Required(securityPath.Child("issuerRef", "name"), "is required"))
+		}
+		// Un keystore fourni et un issuer cert-manager sont deux sources concurrentes des certificats
+		if security.TLSSecretName != "" {
+			allErrs = append(allErrs, field.Forbidden(securityPath.Child("issuerRef"), "cannot be set together with tlsSecretName"))
+		}
+	}
+	if interval := security.PasswordRotationInterval; interval != nil && interval.Duration < MinPasswordRotationInterval {
+		allErrs = append(allErrs, field.Invalid(securityPath.Child("passwordRotationInterval"), interval.Duration.String(),
+			fmt.Sprintf("must be at least %s", MinPasswordRotationInterval)))
+	}
+	return allErrs
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ConditionClusterSettingsReady indique si les settings dynamiques du cluster sont appliqués
+const ConditionClusterSettingsReady = "ClusterSettingsReady"
+
+// operatorManagedClusterSettings are the dynamic settings changed by the operator during
+// rolling restarts and scale-downs, which spec.elasticsearch.clusterSettings cannot set
+var operatorManagedClusterSettings = map[string]bool{
+	"cluster.routing.allocation.enable":        true,
+	"cluster.routing.allocation.exclude._name": true,
+}
+
+// ClusterSettingsStatus reports the persistent cluster settings applied by the operator
+type ClusterSettingsStatus struct {
+	// Valeurs écrites par l'opérateur, remises à leur valeur par défaut lorsqu'elles sont retirées de la spec
+	// +optional
+	Applied map[string]string `json:"applied,omitempty"`
+
+	// Settings modifiés hors de l'opérateur, rétablis lors de la dernière application
+	// +optional
+	Drifted []string `json:"drifted,omitempty"`
+
+	// Date de la dernière écriture des settings dans Elasticsearch
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
+// validateClusterSettings checks the names of spec.elasticsearch.clusterSettings. A setting
+// cannot be both static in elasticsearch.yml and persistent in the cluster state
+func validateClusterSettings(settingsPath *field.Path, spec *ElasticsearchSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make([]string, 0, len(spec.ClusterSettings))
+	for name := range spec.ClusterSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, inConfig := spec.Config[name]
+		switch {
+		case !settingNameRegexp.MatchString(name):
+			allErrs = append(allErrs, field.Invalid(settingsPath.Key(name), name, "must be a dotted setting name, such as cluster.max_shards_per_node"))
+		case operatorManagedClusterSettings[name]:
+			allErrs = append(allErrs, field.Forbidden(settingsPath.Key(name), "is managed by the operator"))
+		case inConfig:
+			allErrs = append(allErrs, field.Invalid(settingsPath.Key(name), name, "is already set in spec.elasticsearch.config"))
+		}
+	}
+	return allErrs
+}
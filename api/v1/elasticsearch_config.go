@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// operatorManagedSettings are the elasticsearch.yml settings set by the operator, which
+// spec.elasticsearch.config cannot override. An entry ending with a dot covers a namespace
+var operatorManagedSettings = []string{
+	"cluster.name",
+	"cluster.initial_master_nodes",
+	"node.name",
+	"node.roles",
+	"discovery.",
+	"network.host",
+	"path.",
+	"xpack.security.",
+}
+
+// settingNameRegexp matches the dotted name of an Elasticsearch setting
+var settingNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// IsOperatorManagedSetting returns true if an elasticsearch.yml setting is set by the operator:
+// the cluster name and discovery, the node name and roles, the paths and the security settings.
+// A parent of a managed setting, such as cluster, is managed as well
+func IsOperatorManagedSetting(name string) bool {
+	for _, managed := range operatorManagedSettings {
+		managed = strings.TrimSuffix(managed, ".")
+		if name == managed || strings.HasPrefix(name, managed+".") || strings.HasPrefix(managed, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateElasticsearchConfig checks the names of the settings of spec.elasticsearch.config
+func validateElasticsearchConfig(configPath *field.Path, config map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	// Ordre stable des erreurs
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case !settingNameRegexp.MatchString(name):
+			allErrs = append(allErrs, field.Invalid(configPath.Key(name), name, "must be a dotted setting name, such as indices.memory.index_buffer_size"))
+		case IsOperatorManagedSetting(name):
+			allErrs = append(allErrs, field.Forbidden(configPath.Key(name), "is managed by the operator"))
+		}
+	}
+	return allErrs
+}
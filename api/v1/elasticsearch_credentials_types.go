@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionCredentialsReady indique si les utilisateurs intégrés d'Elasticsearch ont leurs mots de passe à jour
+const ConditionCredentialsReady = "CredentialsReady"
+
+// RotateCredentialsAnnotation requests a rotation of the generated passwords when its value changes
+const RotateCredentialsAnnotation = "efk.crds.io/rotate-credentials"
+
+// Keys of the credential Secrets. A Secret referenced by authSecretName only needs PasswordKey
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+)
+
+// MinPasswordRotationInterval is the shortest accepted passwordRotationInterval
+const MinPasswordRotationInterval = time.Hour
+
+// CredentialsStatus reports the Secrets holding the credentials of the built-in users
+type CredentialsStatus struct {
+	// Secret contenant le mot de passe de l'utilisateur elastic
+	ElasticSecretName string `json:"elasticSecretName"`
+
+	// Secret contenant les credentials de Kibana (kibana_system)
+	KibanaSecretName string `json:"kibanaSecretName"`
+
+	// Secret contenant les credentials de Fluent Bit
+	FluentBitSecretName string `json:"fluentBitSecretName"`
+
+	// Date de la dernière génération des mots de passe
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// Dernière valeur traitée de l'annotation efk.crds.io/rotate-credentials
+	// +optional
+	RotationRequest string `json:"rotationRequest,omitempty"`
+}
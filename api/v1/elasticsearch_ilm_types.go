@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ConditionIndexLifecycleReady indique si la politique ILM et l'index template sont appliqués
+const ConditionIndexLifecycleReady = "IndexLifecycleReady"
+
+var (
+	esDurationRegexp = regexp.MustCompile(`^([0-9]+)(d|h|m|s|ms)$`)
+	esSizeRegexp     = regexp.MustCompile(`^[0-9]+(b|kb|mb|gb|tb|pb)$`)
+)
+
+// IndexLifecycleSpec defines the Index Lifecycle Management policy of the logs written
+// by Fluent Bit. When set, Fluent Bit writes to a data stream managed by the policy
+type IndexLifecycleSpec struct {
+	// Phase hot : rollover de l'index d'écriture
+	// +optional
+	Hot ILMHotPhase `json:"hot,omitempty"`
+
+	// Phase warm : optimisation des index qui ne reçoivent plus d'écritures
+	// +optional
+	Warm *ILMWarmPhase `json:"warm,omitempty"`
+
+	// Component templates (mappings, shards) composés dans le template du data stream
+	// +optional
+	ComposedOf []string `json:"composedOf,omitempty"`
+
+	// Nombre de jours de rétention après le rollover, avant suppression
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	RetentionDays int32 `json:"retentionDays"`
+}
+
+// ILMHotPhase defines the hot phase
+type ILMHotPhase struct {
+	// Conditions de rollover ; par défaut, 1 jour ou 50gb par shard primaire
+	// +optional
+	Rollover ILMRollover `json:"rollover,omitempty"`
+}
+
+// ILMRollover defines when the write index is rolled over, the first condition met wins
+type ILMRollover struct {
+	// Âge maximal de l'index (ex : 1d, 12h)
+	// +optional
+	MaxAge string `json:"maxAge,omitempty"`
+
+	// Taille maximale de l'index (ex : 100gb)
+	// +optional
+	MaxSize string `json:"maxSize,omitempty"`
+
+	// Taille maximale d'un shard primaire (ex : 50gb)
+	// +optional
+	MaxPrimaryShardSize string `json:"maxPrimaryShardSize,omitempty"`
+
+	// Nombre maximal de documents
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDocs int64 `json:"maxDocs,omitempty"`
+}
+
+// ILMWarmPhase defines the warm phase
+type ILMWarmPhase struct {
+	// Âge après le rollover à partir duquel l'index passe en warm (ex : 7d)
+	// +kubebuilder:validation:Required
+	MinAge string `json:"minAge"`
+
+	// Fusionner les segments de chaque shard (force merge)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ForceMergeSegments int32 `json:"forceMergeSegments,omitempty"`
+
+	// Réduire le nombre de shards primaires (shrink)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ShrinkShards int32 `json:"shrinkShards,omitempty"`
+
+	// Nombre de replicas des index warm
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// Validate checks the durations, sizes and the order of the phases
+func (s *IndexLifecycleSpec) Validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s == nil {
+		return allErrs
+	}
+
+	rollover := s.Hot.Rollover
+	rolloverPath := path.Child("hot", "rollover")
+	if rollover.MaxAge != "" && !esDurationRegexp.MatchString(rollover.MaxAge) {
+		allErrs = append(allErrs, field.Invalid(rolloverPath.Child("maxAge"), rollover.MaxAge, "must be a duration such as 1d or 12h"))
+	}
+	for name, size := range map[string]string{"maxSize": rollover.MaxSize, "maxPrimaryShardSize": rollover.MaxPrimaryShardSize} {
+		if size != "" && !esSizeRegexp.MatchString(size) {
+			allErrs = append(allErrs, field.Invalid(rolloverPath.Child(name), size, "must be a size such as 50gb"))
+		}
+	}
+
+	if s.RetentionDays < 1 {
+		allErrs = append(allErrs, field.Invalid(path.Child("retentionDays"), s.RetentionDays, "must be at least 1"))
+	}
+
+	if s.Warm != nil {
+		warmPath := path.Child("warm", "minAge")
+		if days, ok := durationDays(s.Warm.MinAge); !ok {
+			allErrs = append(allErrs, field.Invalid(warmPath, s.Warm.MinAge, "must be a duration such as 7d"))
+		} else if days >= float64(s.RetentionDays) {
+			allErrs = append(allErrs, field.Invalid(warmPath, s.Warm.MinAge,
+				fmt.Sprintf("must be lower than the retention of %d days", s.RetentionDays)))
+		}
+	}
+
+	return allErrs
+}
+
+// durationDays converts an Elasticsearch duration into days
+func durationDays(duration string) (float64, bool) {
+	match := esDurationRegexp.FindStringSubmatch(duration)
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	perDay := map[string]float64{"d": 1, "h": 24, "m": 24 * 60, "s": 24 * 3600, "ms": 24 * 3600 * 1000}
+	return value / perDay[match[2]], true
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DefaultHeapPercent is the share of the memory of a node given to the heap by default, the
+// rest being left to the off-heap memory of the JVM and to the filesystem cache
+const DefaultHeapPercent = 50
+
+const (
+	// maxHeapBytes keeps the heap below the threshold of compressed ordinary object pointers
+	maxHeapBytes = 31 << 30
+	// minHeapBytes is the smallest heap an Elasticsearch node runs reliably with
+	minHeapBytes = 256 << 20
+)
+
+// heapOptionRegexp matches the -Xms and -Xmx options, with an optional k, m or g unit
+var heapOptionRegexp = regexp.MustCompile(`^-Xm([sx])([0-9]+)([kKmMgG]?)$`)
+
+// JavaOpts returns the ES_JAVA_OPTS of the nodes with the given resources: the heap computed
+// from their memory followed by spec.elasticsearch.jvmOptions. An -Xms/-Xmx pair in jvmOptions
+// replaces the computed heap; without memory, Elasticsearch sizes the heap itself
+func (s *ElasticsearchSpec) JavaOpts(resources corev1.ResourceRequirements) string {
+	var opts []string
+	if _, _, explicit := explicitHeap(s.JVMOptions); !explicit {
+		if heap := s.heapBytes(resources); heap > 0 {
+			size := fmt.Sprintf("%dm", heap>>20)
+			opts = append(opts, "-Xms"+size, "-Xmx"+size)
+		}
+	}
+	return strings.Join(append(opts, s.JVMOptions...), " ")
+}
+
+// heapBytes returns heapPercent of the memory of the nodes, capped at 31g and rounded down
+// to the mebibyte, or 0 when their memory is not set
+func (s *ElasticsearchSpec) heapBytes(resources corev1.ResourceRequirements) int64 {
+	memory := nodeMemory(resources)
+	if memory == 0 {
+		return 0
+	}
+	percent := s.HeapPercent
+	if percent == 0 {
+		percent = DefaultHeapPercent
+	}
+	heap := memory * int64(percent) / 100
+	if heap > maxHeapBytes {
+		heap = maxHeapBytes
+	}
+	return heap &^ (1<<20 - 1)
+}
+
+// nodeMemory returns the memory limit of the nodes, or their memory request without limit
+func nodeMemory(resources corev1.ResourceRequirements) int64 {
+	if limit, ok := resources.Limits[corev1.ResourceMemory]; ok && !limit.IsZero() {
+		return limit.Value()
+	}
+	if request, ok := resources.Requests[corev1.ResourceMemory]; ok {
+		return request.Value()
+	}
+	return 0
+}
+
+// explicitHeap returns the sizes in bytes of the -Xms and -Xmx options, found is true when
+// either of them is set. The last occurrence wins, like for the JVM
+func explicitHeap(options []string) (xms, xmx int64, found bool) {
+	for _, option := range options {
+		match := heapOptionRegexp.FindStringSubmatch(option)
+		if match == nil {
+			continue
+		}
+		size, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(match[3]) {
+		case "k":
+			size <<= 10
+		case "m":
+			size <<= 20
+		case "g":
+			size <<= 30
+		}
+		if match[1] == "s" {
+			xms = size
+		} else {
+			xmx = size
+		}
+		found = true
+	}
+	return xms, xmx, found
+}
+
+// validateJVM checks spec.elasticsearch.jvmOptions and that the heap of every group of nodes
+// leaves memory to the rest of the JVM
+func validateJVM(esPath *field.Path, es *ElasticsearchSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	optionsPath := esPath.Child("jvmOptions")
+	for i, option := range es.JVMOptions {
+		switch {
+		case !strings.HasPrefix(option, "-") || strings.ContainsAny(option, " \t\n"):
+			allErrs = append(allErrs, field.Invalid(optionsPath.Index(i), option, "must be a single JVM option starting with -"))
+		case (strings.HasPrefix(option, "-Xms") || strings.HasPrefix(option, "-Xmx")) && !heapOptionRegexp.MatchString(option):
+			allErrs = append(allErrs, field.Invalid(optionsPath.Index(i), option, "must be a size such as -Xmx4g or -Xmx4096m"))
+		}
+	}
+	xms, xmx, explicit := explicitHeap(es.JVMOptions)
+	if explicit && xms != xmx {
+		// Vérification de démarrage d'Elasticsearch en production
+		allErrs = append(allErrs, field.Invalid(optionsPath, es.JVMOptions, "-Xms and -Xmx must be set together to the same size"))
+		return allErrs
+	}
+
+	// Chaque groupe de nœuds a sa propre mémoire
+	type nodeGroup struct {
+		path      *field.Path
+		resources corev1.ResourceRequirements
+	}
+	var groups []nodeGroup
+	inherited := len(es.NodeSets) == 0 || es.Mode == "singleton"
+	for i := range es.NodeSets {
+		set := &es.NodeSets[i]
+		if len(set.Resources.Requests) == 0 && len(set.Resources.Limits) == 0 {
+			inherited = true
+		} else {
+			groups = append(groups, nodeGroup{esPath.Child("nodeSets").Index(i).Child("resources"), set.Resources})
+		}
+	}
+	if inherited {
+		groups = append([]nodeGroup{{esPath.Child("resources"), es.Resources}}, groups...)
+	}
+
+	for _, group := range groups {
+		memory := nodeMemory(group.resources)
+		if memory == 0 {
+			continue
+		}
+		heap := es.heapBytes(group.resources)
+		if explicit {
+			heap = xmx
+		}
+		value := resource.NewQuantity(memory, resource.BinarySI).String()
+		switch {
+		case heap >= memory:
+			allErrs = append(allErrs, field.Invalid(group.path, value,
+				fmt.Sprintf("the heap of %dm must leave memory to the rest of the JVM", heap>>20)))
+		case heap < minHeapBytes:
+			allErrs = append(allErrs, field.Invalid(group.path, value,
+				fmt.Sprintf("the heap of %dm is below the minimum of 256m, raise the memory or heapPercent", heap>>20)))
+		}
+	}
+	return allErrs
+}
+
+// heapWarning returns a warning when jvmOptions sets a heap too large for compressed ordinary
+// object pointers
+func heapWarning(es *ElasticsearchSpec) string {
+	if _, xmx, explicit := explicitHeap(es.JVMOptions); explicit && xmx > maxHeapBytes {
+		return "spec.elasticsearch.jvmOptions sets a heap above 31g: the JVM loses compressed ordinary object pointers " +
+			"and the usable heap shrinks"
+	}
+	return ""
+}
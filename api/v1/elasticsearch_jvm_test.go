@@ -0,0 +1,61 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("JVM options", func() {
+	memory := func(request, limit string) corev1.ResourceRequirements {
+		resources := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+		if request != "" {
+			resources.Requests[corev1.ResourceMemory] = resource.MustParse(request)
+		}
+		if limit != "" {
+			resources.Limits[corev1.ResourceMemory] = resource.MustParse(limit)
+		}
+		return resources
+	}
+
+	It("Should size the heap from the memory limit in any unit", func() {
+		es := &ElasticsearchSpec{}
+		Expect(es.JavaOpts(memory("4Gi", "8Gi"))).To(Equal("-Xms4096m -Xmx4096m"))
+		Expect(es.JavaOpts(memory("4096Mi", ""))).To(Equal("-Xms2048m -Xmx2048m"))
+		Expect(es.JavaOpts(memory("", "4G"))).To(Equal("-Xms1907m -Xmx1907m"))
+		Expect(es.JavaOpts(corev1.ResourceRequirements{})).To(BeEmpty())
+
+		es.HeapPercent = 75
+		Expect(es.JavaOpts(memory("", "8Gi"))).To(Equal("-Xms6144m -Xmx6144m"))
+	})
+
+	It("Should cap the heap for compressed oops", func() {
+		es := &ElasticsearchSpec{}
+		Expect(es.JavaOpts(memory("", "128Gi"))).To(Equal("-Xms31744m -Xmx31744m"))
+	})
+
+	It("Should append jvmOptions and let an explicit heap replace the computed one", func() {
+		es := &ElasticsearchSpec{JVMOptions: []string{"-XX:+HeapDumpOnOutOfMemoryError"}}
+		Expect(es.JavaOpts(memory("", "8Gi"))).To(Equal("-Xms4096m -Xmx4096m -XX:+HeapDumpOnOutOfMemoryError"))
+
+		es.JVMOptions = []string{"-Xms3g", "-Xmx3g"}
+		Expect(es.JavaOpts(memory("", "8Gi"))).To(Equal("-Xms3g -Xmx3g"))
+	})
+})
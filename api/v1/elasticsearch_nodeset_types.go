@@ -0,0 +1,247 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Elasticsearch node roles
+const (
+	NodeRoleMaster              = "master"
+	NodeRoleData                = "data"
+	NodeRoleDataContent         = "data_content"
+	NodeRoleDataHot             = "data_hot"
+	NodeRoleDataWarm            = "data_warm"
+	NodeRoleDataCold            = "data_cold"
+	NodeRoleDataFrozen          = "data_frozen"
+	NodeRoleIngest              = "ingest"
+	NodeRoleML                  = "ml"
+	NodeRoleRemoteClusterClient = "remote_cluster_client"
+	NodeRoleTransform           = "transform"
+)
+
+// maxNodeSetNameLength keeps "<stack>-elasticsearch-<name>-<ordinal>" within the pod name limits
+const maxNodeSetNameLength = 20
+
+// NodeSetSpec defines a group of Elasticsearch nodes sharing the same roles and resources,
+// deployed as its own StatefulSet
+type NodeSetSpec struct {
+	// Nom du groupe, suffixe du StatefulSet <stack>-elasticsearch-<name>
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Rôles des nœuds ; par défaut, tous les rôles
+	// +optional
+	Roles []NodeRole `json:"roles,omitempty"`
+
+	// Nombre de nœuds ; 0 permet de vider le groupe avant de le retirer de la spec
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Required
+	Replicas int32 `json:"replicas"`
+
+	// Ressources (CPU, mémoire) ; par défaut, celles de spec.elasticsearch
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Stockage ; les champs non renseignés reprennent ceux de spec.elasticsearch
+	// +optional
+	Storage StorageSpec `json:"storage,omitempty"`
+
+	// NodeSelector ; par défaut, celui de spec.elasticsearch
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations ; par défaut, celles de spec.elasticsearch
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// NodeSetStatus reports the pods of a node set
+type NodeSetStatus struct {
+	// Nom du groupe
+	Name string `json:"name"`
+
+	// Nombre de pods du StatefulSet, y compris ceux en cours d'arrêt
+	Replicas int32 `json:"replicas"`
+
+	// Nombre de pods prêts
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+}
+
+// NodeRole is a role of an Elasticsearch node
+// +kubebuilder:validation:Enum=master;data;data_content;data_hot;data_warm;data_cold;data_frozen;ingest;ml;remote_cluster_client;transform
+type NodeRole string
+
+// HasRole returns true if the nodes of the set have the given role. A set without
+// roles gets every role, like an Elasticsearch node without node.roles
+func (s *NodeSetSpec) HasRole(role string) bool {
+	if len(s.Roles) == 0 {
+		return true
+	}
+	for _, r := range s.Roles {
+		if string(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNodeSets checks the names of the node sets and that the cluster keeps a master
+// quorum and nodes able to hold regular indices and data streams
+func validateNodeSets(esPath *field.Path, es *ElasticsearchSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(es.NodeSets) == 0 {
+		return allErrs
+	}
+	setsPath := esPath.Child("nodeSets")
+
+	if es.Mode == "singleton" {
+		allErrs = append(allErrs, field.Forbidden(setsPath, "is not supported in singleton mode"))
+	}
+
+	names := map[string]bool{}
+	var masters int32
+	content, hot := false, false
+	for i := range es.NodeSets {
+		set := &es.NodeSets[i]
+		setPath := setsPath.Index(i)
+		if set.Name == "" {
+			allErrs = append(allErrs, field.Required(setPath.Child("name"), ""))
+		} else if errs := validation.IsDNS1123Label(set.Name); len(errs) > 0 || len(set.Name) > maxNodeSetNameLength {
+			allErrs = append(allErrs, field.Invalid(setPath.Child("name"), set.Name,
+				fmt.Sprintf("must be a DNS label of at most %d characters", maxNodeSetNameLength)))
+		} else if names[set.Name] {
+			allErrs = append(allErrs, field.Duplicate(setPath.Child("name"), set.Name))
+		}
+		names[set.Name] = true
+
+		if set.Storage.Size != "" {
+			if _, err := resource.ParseQuantity(set.Storage.Size); err != nil {
+				allErrs = append(allErrs, field.Invalid(setPath.Child("storage", "size"), set.Storage.Size, err.Error()))
+			}
+		}
+
+		if set.Replicas == 0 {
+			continue
+		}
+		if set.HasRole(NodeRoleMaster) {
+			masters += set.Replicas
+		}
+		content = content || set.HasRole(NodeRoleData) || set.HasRole(NodeRoleDataContent)
+		hot = hot || set.HasRole(NodeRoleData) || set.HasRole(NodeRoleDataHot)
+	}
+
+	// Un nombre pair de nœuds éligibles master ne tolère pas plus de pannes et risque le split-brain
+	if masters%2 == 0 {
+		allErrs = append(allErrs, field.Invalid(setsPath, masters,
+			"must have an odd number of master-eligible nodes to keep a master quorum"))
+	}
+	// Les index classiques vont sur le tier content, les data streams sur le tier hot
+	if !content || !hot {
+		allErrs = append(allErrs, field.Invalid(setsPath, len(es.NodeSets),
+			"must have nodes with the data or data_content role and nodes with the data or data_hot role"))
+	}
+
+	return allErrs
+}
+
+// validateNodeSetsTransition forbids the changes losing data: switching between the single
+// StatefulSet and node sets, removing a set that still has nodes, shrinking the storage of a set.
+// A set scaled to 0 can only be removed once the operator drained its nodes and its pods are gone,
+// as reported by the status of the stack
+func validateNodeSetsTransition(esPath *field.Path, stackName string, old, es *ElasticsearchSpec, status *ElasticsearchStatus) field.ErrorList {
+	var allErrs field.ErrorList
+	setsPath := esPath.Child("nodeSets")
+
+	if (len(old.NodeSets) == 0) != (len(es.NodeSets) == 0) {
+		allErrs = append(allErrs, field.Forbidden(setsPath,
+			"cannot be added to or removed from an existing stack, the nodes would be replaced with empty volumes"))
+		return allErrs
+	}
+
+	current := map[string]*NodeSetSpec{}
+	for i := range es.NodeSets {
+		current[es.NodeSets[i].Name] = &es.NodeSets[i]
+	}
+	for i := range old.NodeSets {
+		oldSet := &old.NodeSets[i]
+		set, ok := current[oldSet.Name]
+		if !ok {
+			if oldSet.Replicas > 0 {
+				allErrs = append(allErrs, field.Forbidden(setsPath,
+					fmt.Sprintf("node set %s must be scaled to 0 replicas before being removed", oldSet.Name)))
+				continue
+			}
+			// Retiré de la spec, le groupe ne serait plus vidé par l'opérateur
+			if status.ScaleDown != nil && scaleDownIncludes(status.ScaleDown, stackName, oldSet.Name) {
+				allErrs = append(allErrs, field.Forbidden(setsPath,
+					fmt.Sprintf("node set %s is being drained, remove it once status.elasticsearch.scaleDown no longer lists its nodes", oldSet.Name)))
+				continue
+			}
+			for _, setStatus := range status.NodeSets {
+				if setStatus.Name == oldSet.Name && setStatus.Replicas > 0 {
+					allErrs = append(allErrs, field.Forbidden(setsPath,
+						fmt.Sprintf("node set %s still runs %d pods, remove it once they are stopped", oldSet.Name, setStatus.Replicas)))
+				}
+			}
+			continue
+		}
+		oldSize := oldSet.Storage.Size
+		if oldSize == "" {
+			oldSize = old.Storage.Size
+		}
+		newSize := set.Storage.Size
+		if newSize == "" {
+			newSize = es.Storage.Size
+		}
+		if oldSize != "" && newSize != "" {
+			oldQuantity, oldErr := resource.ParseQuantity(oldSize)
+			newQuantity, newErr := resource.ParseQuantity(newSize)
+			if oldErr == nil && newErr == nil && newQuantity.Cmp(oldQuantity) < 0 {
+				allErrs = append(allErrs, field.Forbidden(setsPath.Key(set.Name).Child("storage", "size"),
+					fmt.Sprintf("shrinking storage from %s to %s is not supported", oldSize, newSize)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// scaleDownIncludes returns true if the nodes being removed include a node of the given set
+func scaleDownIncludes(scaleDown *ScaleDownStatus, stackName, setName string) bool {
+	prefix := fmt.Sprintf("%s-elasticsearch-%s-", stackName, setName)
+	for _, node := range scaleDown.Nodes {
+		// Le suffixe doit être l'ordinal : le groupe "hot" ne contient pas les nœuds de "hot-2"
+		if ordinal, ok := strings.CutPrefix(node, prefix); ok {
+			if _, err := strconv.Atoi(ordinal); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
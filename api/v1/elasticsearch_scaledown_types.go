@@ -0,0 +1,38 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionElasticsearchScalingDown indique si des nœuds Elasticsearch sont vidés de leurs shards
+// avant la réduction du StatefulSet
+const ConditionElasticsearchScalingDown = "ElasticsearchScalingDown"
+
+// ScaleDownStatus reports the removal of Elasticsearch nodes
+type ScaleDownStatus struct {
+	// Nœuds retirés du cluster
+	Nodes []string `json:"nodes"`
+
+	// Nombre de shards restant à déplacer hors de ces nœuds
+	RemainingShards int32 `json:"remainingShards"`
+
+	// Début du retrait
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ConditionSecureSettingsReady indique si les secure settings du keystore sont rechargés
+const ConditionSecureSettingsReady = "SecureSettingsReady"
+
+// operatorManagedSecureSettings are the keystore settings written by Elasticsearch itself
+var operatorManagedSecureSettings = map[string]bool{
+	"bootstrap.password": true,
+	"keystore.seed":      true,
+}
+
+// SecureSettingSource references a Secret whose keys are written to the keystore of every node
+type SecureSettingSource struct {
+	// Nom du Secret, dans le namespace de l'EFKStack
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// Clés du Secret ajoutées au keystore, par défaut toutes les clés sous leur propre nom
+	// +optional
+	Entries []SecureSettingEntry `json:"entries,omitempty"`
+}
+
+// SecureSettingEntry maps a key of a Secret to a keystore setting
+type SecureSettingEntry struct {
+	// Clé du Secret
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Setting du keystore (e.g., xpack.security.authc.realms.oidc.oidc1.rp.client_secret), par défaut la clé
+	// +optional
+	Setting string `json:"setting,omitempty"`
+}
+
+// SettingName returns the keystore setting filled by the entry
+func (e SecureSettingEntry) SettingName() string {
+	if e.Setting == "" {
+		return e.Key
+	}
+	return e.Setting
+}
+
+// SecureSettingsStatus reports the reloads of the secure settings
+type SecureSettingsStatus struct {
+	// Empreinte du contenu des Secrets référencés
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// Date à laquelle l'opérateur a détecté le dernier changement des Secrets
+	// +optional
+	ChangeTime *metav1.Time `json:"changeTime,omitempty"`
+
+	// Date du dernier rechargement par l'API _nodes/reload_secure_settings
+	// +optional
+	LastReloadTime *metav1.Time `json:"lastReloadTime,omitempty"`
+}
+
+// validateSecureSettings checks the Secret keys and the keystore settings of
+// spec.elasticsearch.secureSettings. Each setting can only be filled once, the credentials of
+// the snapshot repository included
+func validateSecureSettings(settingsPath *field.Path, spec *ElasticsearchSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	snapshotPrefix := ""
+	if spec.Snapshot != nil && spec.Snapshot.Repository.CredentialsSecret != nil {
+		snapshotPrefix = spec.Snapshot.Repository.RepositoryType() + ".client.default."
+	}
+
+	secrets := map[string]bool{}
+	settings := map[string]bool{}
+	for i, source := range spec.SecureSettings {
+		sourcePath := settingsPath.Index(i)
+		if source.SecretName == "" {
+			allErrs = append(allErrs, field.Required(sourcePath.Child("secretName"), "secretName is required"))
+		} else if secrets[source.SecretName] {
+			// Les clés de deux Secrets entiers pourraient se recouvrir
+			allErrs = append(allErrs, field.Duplicate(sourcePath.Child("secretName"), source.SecretName))
+		}
+		secrets[source.SecretName] = true
+
+		for j, entry := range source.Entries {
+			entryPath := sourcePath.Child("entries").Index(j)
+			for _, msg := range validation.IsConfigMapKey(entry.Key) {
+				allErrs = append(allErrs, field.Invalid(entryPath.Child("key"), entry.Key, msg))
+			}
+			name := entry.SettingName()
+			switch {
+			case !settingNameRegexp.MatchString(name):
+				allErrs = append(allErrs, field.Invalid(entryPath.Child("setting"), name, "must be a dotted setting name, such as s3.client.backup.access_key"))
+			case operatorManagedSecureSettings[name]:
+				allErrs = append(allErrs, field.Forbidden(entryPath.Child("setting"), "is managed by Elasticsearch"))
+			case snapshotPrefix != "" && strings.HasPrefix(name, snapshotPrefix):
+				allErrs = append(allErrs, field.Forbidden(entryPath.Child("setting"), "is filled from spec.elasticsearch.snapshot.repository.credentialsSecret"))
+			case settings[name]:
+				allErrs = append(allErrs, field.Duplicate(entryPath.Child("setting"), name))
+			}
+			settings[name] = true
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ConditionSnapshotsReady indique si le dépôt et les politiques SLM sont appliqués
+const ConditionSnapshotsReady = "SnapshotsReady"
+
+// DefaultSnapshotRepositoryName is the name of the repository when none is set
+const DefaultSnapshotRepositoryName = "backups"
+
+// DefaultSnapshotMountPath is where a shared filesystem repository is mounted when none is set
+const DefaultSnapshotMountPath = "/usr/share/elasticsearch/snapshots"
+
+// Keys expected in the credentials Secret of a snapshot repository
+const (
+	S3AccessKeyKey    = "access_key"
+	S3SecretKeyKey    = "secret_key"
+	S3SessionTokenKey = "session_token"
+	GCSCredentialsKey = "credentials.json"
+	AzureAccountKey   = "account"
+	AzureKeyKey       = "key"
+)
+
+// SnapshotSpec defines the snapshot repository and the scheduled snapshots of Elasticsearch
+type SnapshotSpec struct {
+	// Dépôt de snapshots
+	// +kubebuilder:validation:Required
+	Repository SnapshotRepositorySpec `json:"repository"`
+
+	// Politiques SLM (snapshots planifiés et rétention)
+	// +optional
+	Policies []SnapshotPolicySpec `json:"policies,omitempty"`
+}
+
+// SnapshotRepositorySpec defines a snapshot repository, exactly one type must be set
+type SnapshotRepositorySpec struct {
+	// Nom du dépôt dans Elasticsearch
+	// +kubebuilder:default=backups
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Dépôt S3 ou compatible S3 (MinIO, Ceph...)
+	// +optional
+	S3 *S3RepositorySpec `json:"s3,omitempty"`
+
+	// Dépôt Google Cloud Storage
+	// +optional
+	GCS *GCSRepositorySpec `json:"gcs,omitempty"`
+
+	// Dépôt Azure Blob Storage
+	// +optional
+	Azure *AzureRepositorySpec `json:"azure,omitempty"`
+
+	// Dépôt sur un système de fichiers partagé monté sur tous les nœuds
+	// +optional
+	FS *FSRepositorySpec `json:"fs,omitempty"`
+
+	// Secret contenant les credentials du dépôt, ajoutés au keystore Elasticsearch
+	// (s3 : access_key, secret_key, session_token ; gcs : credentials.json ; azure : account, key)
+	// +optional
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+}
+
+// S3RepositorySpec defines an S3-compatible repository
+type S3RepositorySpec struct {
+	// Bucket
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Préfixe des fichiers dans le bucket
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// Endpoint d'un stockage compatible S3 (ex : minio.minio.svc:9000)
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Protocole de l'endpoint
+	// +kubebuilder:validation:Enum=http;https
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+
+	// Région du bucket
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Adressage par chemin (requis par MinIO)
+	// +optional
+	PathStyleAccess bool `json:"pathStyleAccess,omitempty"`
+}
+
+// GCSRepositorySpec defines a Google Cloud Storage repository
+type GCSRepositorySpec struct {
+	// Bucket
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Préfixe des fichiers dans le bucket
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+}
+
+// AzureRepositorySpec defines an Azure Blob Storage repository
+type AzureRepositorySpec struct {
+	// Container
+	// +kubebuilder:validation:Required
+	Container string `json:"container"`
+
+	// Préfixe des fichiers dans le container
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+}
+
+// FSRepositorySpec defines a shared filesystem repository
+type FSRepositorySpec struct {
+	// PVC ReadWriteMany monté sur tous les nœuds Elasticsearch
+	// +kubebuilder:validation:Required
+	ClaimName string `json:"claimName"`
+
+	// Point de montage du PVC, ajouté à path.repo
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// SnapshotPolicySpec defines a Snapshot Lifecycle Management policy
+type SnapshotPolicySpec struct {
+	// Nom de la politique SLM
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Planification au format cron Elasticsearch (ex : "0 30 1 * * ?")
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// Nom des snapshots, avec date math (par défaut <nom-{now/d}>)
+	// +optional
+	SnapshotName string `json:"snapshotName,omitempty"`
+
+	// Index à sauvegarder ; par défaut, tous
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// Inclure l'état global du cluster
+	// +optional
+	IncludeGlobalState bool `json:"includeGlobalState,omitempty"`
+
+	// Rétention des snapshots
+	// +optional
+	Retention *SnapshotRetentionSpec `json:"retention,omitempty"`
+}
+
+// SnapshotRetentionSpec defines how long the snapshots of a policy are kept
+type SnapshotRetentionSpec struct {
+	// Durée de conservation (ex : 30d)
+	// +optional
+	ExpireAfter string `json:"expireAfter,omitempty"`
+
+	// Nombre minimal de snapshots conservés
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinCount int32 `json:"minCount,omitempty"`
+
+	// Nombre maximal de snapshots conservés
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount int32 `json:"maxCount,omitempty"`
+}
+
+// SnapshotStatus reports the snapshot repository and the SLM policies
+type SnapshotStatus struct {
+	// Dépôt enregistré dans Elasticsearch
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Date du dernier snapshot réussi, toutes politiques confondues
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// État de chaque politique SLM
+	// +optional
+	Policies []SnapshotPolicyStatus `json:"policies,omitempty"`
+}
+
+// SnapshotPolicyStatus reports the last executions of a SLM policy
+type SnapshotPolicyStatus struct {
+	// Nom de la politique
+	Name string `json:"name"`
+
+	// Dernier snapshot réussi
+	// +optional
+	LastSuccessSnapshot string `json:"lastSuccessSnapshot,omitempty"`
+
+	// Date du dernier snapshot réussi
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// Date du dernier échec
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// Détail du dernier échec, ou erreur d'application de la politique
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RepositoryType returns the Elasticsearch type of the repository (s3, gcs, azure, fs)
+func (r *SnapshotRepositorySpec) RepositoryType() string {
+	switch {
+	case r.S3 != nil:
+		return "s3"
+	case r.GCS != nil:
+		return "gcs"
+	case r.Azure != nil:
+		return "azure"
+	case r.FS != nil:
+		return "fs"
+	}
+	return ""
+}
+
+// RepositoryName returns the name of the repository, defaulted when unset
+func (r *SnapshotRepositorySpec) RepositoryName() string {
+	if r.Name == "" {
+		return DefaultSnapshotRepositoryName
+	}
+	return r.Name
+}
+
+// Validate checks the repository type, the credentials and the policies
+func (s *SnapshotSpec) Validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s == nil {
+		return allErrs
+	}
+
+	repoPath := path.Child("repository")
+	repo := &s.Repository
+	if count := countSet(repo.S3 != nil, repo.GCS != nil, repo.Azure != nil, repo.FS != nil); count != 1 {
+		allErrs = append(allErrs, field.Invalid(repoPath, count, "exactly one of s3, gcs, azure, fs must be set"))
+	}
+	if repo.FS != nil && repo.CredentialsSecret != nil {
+		allErrs = append(allErrs, field.Forbidden(repoPath.Child("credentialsSecret"), "is not used by fs repositories"))
+	}
+	if (repo.GCS != nil || repo.Azure != nil) && repo.CredentialsSecret == nil {
+		allErrs = append(allErrs, field.Required(repoPath.Child("credentialsSecret"), "is required for gcs and azure repositories"))
+	}
+	if repo.FS != nil && repo.FS.MountPath != "" && !strings.HasPrefix(repo.FS.MountPath, "/") {
+		allErrs = append(allErrs, field.Invalid(repoPath.Child("fs", "mountPath"), repo.FS.MountPath, "must be an absolute path"))
+	}
+
+	seen := map[string]bool{}
+	for i, policy := range s.Policies {
+		p := path.Child("policies").Index(i)
+		if seen[policy.Name] {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), policy.Name))
+		}
+		seen[policy.Name] = true
+		if len(strings.Fields(policy.Schedule)) < 6 {
+			allErrs = append(allErrs, field.Invalid(p.Child("schedule"), policy.Schedule,
+				"must be an Elasticsearch cron expression with seconds, e.g. \"0 30 1 * * ?\""))
+		}
+		if retention := policy.Retention; retention != nil {
+			if retention.ExpireAfter != "" && !esDurationRegexp.MatchString(retention.ExpireAfter) {
+				allErrs = append(allErrs, field.Invalid(p.Child("retention", "expireAfter"), retention.ExpireAfter, "must be a duration such as 30d"))
+			}
+			if retention.MinCount > 0 && retention.MaxCount > 0 && retention.MinCount > retention.MaxCount {
+				allErrs = append(allErrs, field.Invalid(p.Child("retention", "minCount"), retention.MinCount,
+					fmt.Sprintf("must not be greater than maxCount (%d)", retention.MaxCount)))
+			}
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Kinds of the templates reported in the status
+const (
+	TemplateKindComponent = "ComponentTemplate"
+	TemplateKindIndex     = "IndexTemplate"
+)
+
+// ConditionTemplatesReady indique si tous les templates déclarés sont appliqués
+const ConditionTemplatesReady = "TemplatesReady"
+
+// ReservedIndexTemplateName is the index template managed for the ILM data stream
+const ReservedIndexTemplateName = "fluent-bit"
+
+var templateNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ComponentTemplateSpec defines a component template, a reusable block of settings
+// and mappings composed into index templates
+type ComponentTemplateSpec struct {
+	// Nom du component template
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Settings et mappings du component template
+	// +optional
+	Template TemplateBodySpec `json:"template,omitempty"`
+}
+
+// IndexTemplateSpec defines a composable index template
+type IndexTemplateSpec struct {
+	// Nom de l'index template
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Patterns des index concernés (ex : fluent-bit-*)
+	// +kubebuilder:validation:MinItems=1
+	IndexPatterns []string `json:"indexPatterns"`
+
+	// Component templates composés, dans l'ordre d'application
+	// +optional
+	ComposedOf []string `json:"composedOf,omitempty"`
+
+	// Priorité du template lorsque plusieurs patterns correspondent
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Priority int64 `json:"priority,omitempty"`
+
+	// Créer des data streams plutôt que des index
+	// +optional
+	DataStream bool `json:"dataStream,omitempty"`
+
+	// Settings et mappings propres au template
+	// +optional
+	Template TemplateBodySpec `json:"template,omitempty"`
+}
+
+// TemplateBodySpec holds the settings and mappings of a template
+type TemplateBodySpec struct {
+	// Nombre de shards primaires
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Shards *int32 `json:"shards,omitempty"`
+
+	// Nombre de replicas
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Nombre maximal de champs (index.mapping.total_fields.limit)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TotalFieldsLimit *int32 `json:"totalFieldsLimit,omitempty"`
+
+	// Settings additionnels, avec leur nom complet (ex : index.refresh_interval)
+	// +optional
+	Settings map[string]string `json:"settings,omitempty"`
+
+	// Mappings Elasticsearch (properties, dynamic, dynamic_templates...)
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Mappings *runtime.RawExtension `json:"mappings,omitempty"`
+}
+
+// TemplateStatus reports the state of a template declared on the stack
+type TemplateStatus struct {
+	// Nom du template
+	Name string `json:"name"`
+
+	// Type du template (ComponentTemplate, IndexTemplate)
+	Kind string `json:"kind"`
+
+	// État (Applied, Error)
+	State string `json:"state"`
+
+	// Message d'erreur
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Date de la dernière écriture du template dans Elasticsearch
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
+// validateTemplates checks the names, the references between templates and the mappings
+func validateTemplates(path *field.Path, spec *ElasticsearchSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	components := map[string]bool{}
+	for i, component := range spec.ComponentTemplates {
+		p := path.Child("componentTemplates").Index(i)
+		allErrs = append(allErrs, validateTemplateName(p.Child("name"), component.Name, components)...)
+		allErrs = append(allErrs, component.Template.validate(p.Child("template"))...)
+	}
+
+	indexTemplates := map[string]bool{}
+	for i, template := range spec.IndexTemplates {
+		p := path.Child("indexTemplates").Index(i)
+		allErrs = append(allErrs, validateTemplateName(p.Child("name"), template.Name, indexTemplates)...)
+		if spec.ILM != nil && template.Name == ReservedIndexTemplateName {
+			allErrs = append(allErrs, field.Invalid(p.Child("name"), template.Name, "is reserved for the ILM data stream"))
+		}
+		if len(template.IndexPatterns) == 0 {
+			allErrs = append(allErrs, field.Required(p.Child("indexPatterns"), ""))
+		}
+		for j, name := range template.ComposedOf {
+			if !components[name] {
+				allErrs = append(allErrs, field.NotFound(p.Child("composedOf").Index(j), name))
+			}
+		}
+		allErrs = append(allErrs, template.Template.validate(p.Child("template"))...)
+	}
+
+	if spec.ILM != nil {
+		for j, name := range spec.ILM.ComposedOf {
+			if !components[name] {
+				allErrs = append(allErrs, field.NotFound(path.Child("ilm", "composedOf").Index(j), name))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateTemplateName(path *field.Path, name string, seen map[string]bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if !templateNameRegexp.MatchString(name) {
+		allErrs = append(allErrs, field.Invalid(path, name, "must be lowercase and contain only alphanumeric characters, '-', '_' or '.'"))
+	}
+	if seen[name] {
+		allErrs = append(allErrs, field.Duplicate(path, name))
+	}
+	seen[name] = true
+	return allErrs
+}
+
+func (t *TemplateBodySpec) validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if t.Mappings != nil && len(t.Mappings.Raw) > 0 {
+		var mappings map[string]interface{}
+		if err := json.Unmarshal(t.Mappings.Raw, &mappings); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("mappings"), string(t.Mappings.Raw), fmt.Sprintf("must be a JSON object: %v", err)))
+		}
+	}
+	return allErrs
+}
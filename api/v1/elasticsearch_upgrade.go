@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ConditionElasticsearchUpgrading indique si les nœuds Elasticsearch sont redémarrés un par un
+// pour appliquer une nouvelle version ou une nouvelle configuration
+const ConditionElasticsearchUpgrading = "ElasticsearchUpgrading"
+
+// majorUpgradeMinors is, per major version, the minimum minor version from which
+// Elasticsearch supports a rolling upgrade to the next major
+var majorUpgradeMinors = map[uint64]uint64{
+	6: 8,
+	7: 17,
+	8: 18,
+}
+
+// CheckElasticsearchUpgrade returns an error when Elasticsearch cannot be upgraded from one
+// version to the other: downgrades, skipped majors and majors reached from an older minor
+func CheckElasticsearchUpgrade(from, to string) error {
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil {
+		return fmt.Errorf("invalid current version %q: %w", from, err)
+	}
+	toVersion, err := semver.NewVersion(to)
+	if err != nil {
+		return fmt.Errorf("invalid target version %q: %w", to, err)
+	}
+
+	switch {
+	case toVersion.LessThan(fromVersion):
+		return fmt.Errorf("downgrading Elasticsearch from %s to %s is not supported", fromVersion, toVersion)
+	case toVersion.Major() > fromVersion.Major()+1:
+		return fmt.Errorf("upgrading Elasticsearch from %s to %s skips a major version, upgrade to %d.x first",
+			fromVersion, toVersion, fromVersion.Major()+1)
+	case toVersion.Major() == fromVersion.Major()+1:
+		minMinor, ok := majorUpgradeMinors[fromVersion.Major()]
+		if !ok {
+			return fmt.Errorf("upgrading Elasticsearch from %s to %s is not supported", fromVersion, toVersion)
+		}
+		if fromVersion.Minor() < minMinor {
+			return fmt.Errorf("upgrading Elasticsearch to %d.x requires %d.%d or later, upgrade from %s to %d.%d first",
+				toVersion.Major(), fromVersion.Major(), minMinor, fromVersion, fromVersion.Major(), minMinor)
+		}
+	}
+	return nil
+}
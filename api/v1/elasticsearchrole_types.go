@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionReady indique si l'utilisateur ou le rôle est appliqué dans Elasticsearch
+const ConditionReady = "Ready"
+
+// ReservedElasticsearchRoles are the roles managed by the operator for the stack itself
+var ReservedElasticsearchRoles = []string{"fluentbit_writer"}
+
+// PrivilegedClusterPrivileges are the cluster privileges managing the cluster or its security.
+// Only the ElasticsearchRoles in the namespace of the stack can grant them
+var PrivilegedClusterPrivileges = []string{
+	"all", "manage", "manage_security", "manage_api_key", "grant_api_key", "manage_service_account",
+	"manage_token", "manage_oidc", "manage_saml", "manage_user_profile",
+}
+
+// ElasticsearchRoleSpec defines the desired state of ElasticsearchRole
+type ElasticsearchRoleSpec struct {
+	// EFKStack dont l'Elasticsearch reçoit le rôle
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Nom du rôle dans Elasticsearch ; par défaut, le nom de la ressource
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+
+	// Privilèges sur le cluster (monitor, manage_index_templates...)
+	// +optional
+	Cluster []string `json:"cluster,omitempty"`
+
+	// Privilèges sur les index
+	// +optional
+	Indices []IndexPrivilegesSpec `json:"indices,omitempty"`
+
+	// Privilèges dans Kibana, par espace
+	// +optional
+	Kibana []KibanaPrivilegesSpec `json:"kibana,omitempty"`
+}
+
+// IndexPrivilegesSpec grants privileges on the indices matching Names
+type IndexPrivilegesSpec struct {
+	// Noms ou patterns des index (ex : team-a-*)
+	// +kubebuilder:validation:MinItems=1
+	Names []string `json:"names"`
+
+	// Privilèges accordés (read, view_index_metadata, write...)
+	// +kubebuilder:validation:MinItems=1
+	Privileges []string `json:"privileges"`
+}
+
+// KibanaPrivilegesSpec grants Kibana privileges, either on every feature with Base or feature
+// by feature, in some spaces
+type KibanaPrivilegesSpec struct {
+	// Privilège sur toutes les fonctionnalités (all, read)
+	// +kubebuilder:validation:Enum=all;read
+	// +optional
+	Base string `json:"base,omitempty"`
+
+	// Privilèges par fonctionnalité (ex : discover: [read]), quand base n'est pas défini
+	// +optional
+	Features map[string][]string `json:"features,omitempty"`
+
+	// Espaces Kibana concernés ; par défaut, tous ("*")
+	// +optional
+	Spaces []string `json:"spaces,omitempty"`
+}
+
+// ElasticsearchRoleStatus defines the observed state of ElasticsearchRole
+type ElasticsearchRoleStatus struct {
+	// Génération de la spec appliquée
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Nom du rôle créé dans Elasticsearch
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+
+	// Conditions (Ready)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ElasticsearchRoleName returns the name of the role in Elasticsearch
+func (r *ElasticsearchRole) ElasticsearchRoleName() string {
+	if r.Spec.RoleName != "" {
+		return r.Spec.RoleName
+	}
+	return r.Name
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=esrole
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Role",type="string",JSONPath=".status.roleName"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticsearchRole is the Schema for the elasticsearchroles API. It declares a native role
+// in the Elasticsearch cluster of an EFKStack, deleted with the resource
+type ElasticsearchRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchRoleSpec   `json:"spec,omitempty"`
+	Status ElasticsearchRoleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ElasticsearchRoleList contains a list of ElasticsearchRole
+type ElasticsearchRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchRole{}, &ElasticsearchRoleList{})
+}
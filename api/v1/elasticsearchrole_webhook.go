@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// maxSecurityNameLength is the longest user or role name accepted by Elasticsearch
+const maxSecurityNameLength = 507
+
+// log is for logging in this package.
+var elasticsearchrolelog = logf.Log.WithName("elasticsearchrole-resource")
+
+// SetupWebhookWithManager registers the validating webhook of ElasticsearchRole
+func (r *ElasticsearchRole) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-elasticsearchrole,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=elasticsearchroles,verbs=create;update,versions=v1,name=velasticsearchrole.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ElasticsearchRole{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchRole) ValidateCreate() (admission.Warnings, error) {
+	elasticsearchrolelog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchRole) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	elasticsearchrolelog.Info("validate update", "name", r.Name)
+
+	oldRole, ok := old.(*ElasticsearchRole)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticsearchRole but got a %T", old)
+	}
+
+	allErrs := r.Validate()
+	// Le rôle précédent resterait dans Elasticsearch sans ressource pour le supprimer
+	specPath := field.NewPath("spec")
+	if r.Spec.StackRef.NamespaceOr(r.Namespace) != oldRole.Spec.StackRef.NamespaceOr(oldRole.Namespace) || r.Spec.StackRef.Name != oldRole.Spec.StackRef.Name {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("stackRef"), "is immutable"))
+	}
+	if r.ElasticsearchRoleName() != oldRole.ElasticsearchRoleName() {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("roleName"), "is immutable"))
+	}
+	return nil, r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchRole) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the name and the privileges of the role
+func (r *ElasticsearchRole) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	allErrs = append(allErrs, validateSecurityName(specPath.Child("roleName"), r.ElasticsearchRoleName(), ReservedElasticsearchRoles)...)
+
+	// Une équipe d'un autre namespace ne peut pas s'attribuer les droits de l'administrateur de la stack
+	if r.Spec.StackRef.NamespaceOr(r.Namespace) != r.Namespace {
+		for i, privilege := range r.Spec.Cluster {
+			if containsString(PrivilegedClusterPrivileges, privilege) {
+				allErrs = append(allErrs, field.Forbidden(specPath.Child("cluster").Index(i),
+					fmt.Sprintf("%s can only be granted by an ElasticsearchRole in the namespace of the EFKStack", privilege)))
+			}
+		}
+	}
+
+	for i, indices := range r.Spec.Indices {
+		p := specPath.Child("indices").Index(i)
+		if len(indices.Names) == 0 {
+			allErrs = append(allErrs, field.Required(p.Child("names"), ""))
+		}
+		if len(indices.Privileges) == 0 {
+			allErrs = append(allErrs, field.Required(p.Child("privileges"), ""))
+		}
+	}
+
+	// Kibana refuse un privilège de base combiné à des privilèges par fonctionnalité
+	for i, kibana := range r.Spec.Kibana {
+		p := specPath.Child("kibana").Index(i)
+		switch {
+		case kibana.Base == "" && len(kibana.Features) == 0:
+			allErrs = append(allErrs, field.Required(p.Child("base"), "base or features is required"))
+		case kibana.Base != "" && len(kibana.Features) > 0:
+			allErrs = append(allErrs, field.Forbidden(p.Child("features"), "cannot be combined with base"))
+		}
+		for feature, privileges := range kibana.Features {
+			if len(privileges) == 0 {
+				allErrs = append(allErrs, field.Required(p.Child("features").Key(feature), ""))
+			}
+		}
+		for j, space := range kibana.Spaces {
+			if space == "*" && len(kibana.Spaces) > 1 {
+				allErrs = append(allErrs, field.Invalid(p.Child("spaces").Index(j), space, "\"*\" cannot be combined with other spaces"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *ElasticsearchRole) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "ElasticsearchRole"}, r.Name, allErrs)
+}
+
+// validateSecurityName checks a user or role name against the rules of Elasticsearch and
+// rejects the names managed elsewhere
+func validateSecurityName(path *field.Path, name string, reserved []string) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(name) > maxSecurityNameLength {
+		allErrs = append(allErrs, field.TooLong(path, name, maxSecurityNameLength))
+	}
+	if strings.TrimSpace(name) != name {
+		allErrs = append(allErrs, field.Invalid(path, name, "must not start or end with whitespace"))
+	}
+	for _, r := range reserved {
+		if name == r {
+			allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("%s is reserved", name)))
+		}
+	}
+	return allErrs
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
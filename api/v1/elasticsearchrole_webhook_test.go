@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ElasticsearchRole Webhook", func() {
+	var role *ElasticsearchRole
+
+	BeforeEach(func() {
+		role = &ElasticsearchRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-read", Namespace: "team-a"},
+			Spec: ElasticsearchRoleSpec{
+				StackRef: StackReference{Name: "test-efk-stack", Namespace: "logging"},
+				Indices:  []IndexPrivilegesSpec{{Names: []string{"team-a-*"}, Privileges: []string{"read", "view_index_metadata"}}},
+				Kibana:   []KibanaPrivilegesSpec{{Features: map[string][]string{"discover": {"read"}}, Spaces: []string{"team-a"}}},
+			},
+		}
+	})
+
+	It("Should accept a valid role", func() {
+		_, err := role.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject the roles managed by the operator", func() {
+		role.Spec.RoleName = "fluentbit_writer"
+		_, err := role.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.roleName"))
+	})
+
+	It("Should only grant the privileged cluster privileges in the namespace of the stack", func() {
+		role.Spec.Cluster = []string{"monitor", "all", "manage_security"}
+		_, err := role.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.cluster[1]"))
+		Expect(err.Error()).To(ContainSubstring("spec.cluster[2]"))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.cluster[0]"))
+
+		role.Spec.StackRef.Namespace = ""
+		_, err = role.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject Kibana privileges combining base and features", func() {
+		role.Spec.Kibana[0].Base = "read"
+		_, err := role.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.kibana[0].features"))
+
+		role.Spec.Kibana[0] = KibanaPrivilegesSpec{Spaces: []string{"*", "team-a"}}
+		_, err = role.ValidateCreate()
+		Expect(err.Error()).To(ContainSubstring("spec.kibana[0].base"))
+		Expect(err.Error()).To(ContainSubstring("spec.kibana[0].spaces[0]"))
+	})
+
+	It("Should reject a change of the role name or of the stack", func() {
+		updated := role.DeepCopy()
+		updated.Spec.RoleName = "team-a-reader"
+		_, err := updated.ValidateUpdate(role)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.roleName"))
+
+		updated = role.DeepCopy()
+		updated.Spec.StackRef.Namespace = ""
+		_, err = updated.ValidateUpdate(role)
+		Expect(err.Error()).To(ContainSubstring("spec.stackRef"))
+
+		updated = role.DeepCopy()
+		updated.Spec.Cluster = []string{"monitor"}
+		_, err = updated.ValidateUpdate(role)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
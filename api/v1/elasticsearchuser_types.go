@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReservedElasticsearchUsers are the built-in users of Elasticsearch and the users managed
+// by the operator for the stack itself
+var ReservedElasticsearchUsers = []string{
+	"elastic", "kibana", "kibana_system", "logstash_system", "beats_system", "apm_system",
+	"remote_monitoring_user", "fluentbit",
+}
+
+// PrivilegedElasticsearchRoles are the built-in roles, and the role of Fluent Bit, giving access to
+// the whole cluster or to the indices of every team. Only the ElasticsearchUsers in the namespace
+// of the stack can grant them
+var PrivilegedElasticsearchRoles = []string{
+	"superuser", "kibana_system", "kibana_admin", "logstash_system", "beats_system", "apm_system",
+	"remote_monitoring_agent", "remote_monitoring_collector", "snapshot_user", "transport_client",
+	"editor", "viewer", "fluentbit_writer",
+}
+
+// ElasticsearchUserSpec defines the desired state of ElasticsearchUser
+type ElasticsearchUserSpec struct {
+	// EFKStack dont l'Elasticsearch reçoit l'utilisateur
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Nom de l'utilisateur dans Elasticsearch ; par défaut, le nom de la ressource
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Rôles de l'utilisateur (rôles intégrés ou déclarés par des ElasticsearchRoles)
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+
+	// Secret du namespace de la ressource dont la clé password contient le mot de passe.
+	// Par défaut, un mot de passe est généré dans le Secret <nom>-es-user
+	// +optional
+	PasswordSecret *corev1.LocalObjectReference `json:"passwordSecret,omitempty"`
+
+	// Nom complet
+	// +optional
+	FullName string `json:"fullName,omitempty"`
+
+	// Adresse e-mail
+	// +optional
+	Email string `json:"email,omitempty"`
+}
+
+// ElasticsearchUserStatus defines the observed state of ElasticsearchUser
+type ElasticsearchUserStatus struct {
+	// Génération de la spec appliquée
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Nom de l'utilisateur créé dans Elasticsearch
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Secret contenant le mot de passe de l'utilisateur
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Conditions (Ready)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ElasticsearchUsername returns the name of the user in Elasticsearch
+func (r *ElasticsearchUser) ElasticsearchUsername() string {
+	if r.Spec.Username != "" {
+		return r.Spec.Username
+	}
+	return r.Name
+}
+
+// PasswordSecretName returns the Secret holding the password of the user, generated by the
+// operator when passwordSecret is not set
+func (r *ElasticsearchUser) PasswordSecretName() string {
+	if r.Spec.PasswordSecret != nil {
+		return r.Spec.PasswordSecret.Name
+	}
+	return r.Name + "-es-user"
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=esuser
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Username",type="string",JSONPath=".status.username"
+//+kubebuilder:printcolumn:name="Secret",type="string",priority=1,JSONPath=".status.secretName"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticsearchUser is the Schema for the elasticsearchusers API. It declares a native user
+// in the Elasticsearch cluster of an EFKStack, deleted with the resource
+type ElasticsearchUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchUserSpec   `json:"spec,omitempty"`
+	Status ElasticsearchUserStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ElasticsearchUserList contains a list of ElasticsearchUser
+type ElasticsearchUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchUser `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchUser{}, &ElasticsearchUserList{})
+}
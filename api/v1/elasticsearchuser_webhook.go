@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var elasticsearchuserlog = logf.Log.WithName("elasticsearchuser-resource")
+
+// SetupWebhookWithManager registers the validating webhook of ElasticsearchUser
+func (r *ElasticsearchUser) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-elasticsearchuser,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=elasticsearchusers,verbs=create;update,versions=v1,name=velasticsearchuser.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ElasticsearchUser{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchUser) ValidateCreate() (admission.Warnings, error) {
+	elasticsearchuserlog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchUser) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	elasticsearchuserlog.Info("validate update", "name", r.Name)
+
+	oldUser, ok := old.(*ElasticsearchUser)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticsearchUser but got a %T", old)
+	}
+
+	allErrs := r.Validate()
+	// L'utilisateur précédent resterait dans Elasticsearch sans ressource pour le supprimer
+	specPath := field.NewPath("spec")
+	if r.Spec.StackRef.NamespaceOr(r.Namespace) != oldUser.Spec.StackRef.NamespaceOr(oldUser.Namespace) || r.Spec.StackRef.Name != oldUser.Spec.StackRef.Name {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("stackRef"), "is immutable"))
+	}
+	if r.ElasticsearchUsername() != oldUser.ElasticsearchUsername() {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("username"), "is immutable"))
+	}
+	return nil, r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticsearchUser) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the name, the roles and the password Secret of the user
+func (r *ElasticsearchUser) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	allErrs = append(allErrs, validateSecurityName(specPath.Child("username"), r.ElasticsearchUsername(), ReservedElasticsearchUsers)...)
+
+	// Une équipe d'un autre namespace ne peut pas s'attribuer les droits de l'administrateur de la stack
+	crossNamespace := r.Spec.StackRef.NamespaceOr(r.Namespace) != r.Namespace
+	for i, role := range r.Spec.Roles {
+		if role == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("roles").Index(i), ""))
+		} else if crossNamespace && containsString(PrivilegedElasticsearchRoles, role) {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("roles").Index(i),
+				fmt.Sprintf("%s can only be granted by an ElasticsearchUser in the namespace of the EFKStack", role)))
+		}
+	}
+	if r.Spec.PasswordSecret != nil && r.Spec.PasswordSecret.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("passwordSecret", "name"), ""))
+	}
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *ElasticsearchUser) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "ElasticsearchUser"}, r.Name, allErrs)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ElasticsearchUser Webhook", func() {
+	var user *ElasticsearchUser
+
+	BeforeEach(func() {
+		user = &ElasticsearchUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "team-a"},
+			Spec: ElasticsearchUserSpec{
+				StackRef: StackReference{Name: "test-efk-stack", Namespace: "logging"},
+				Roles:    []string{"team-a-read"},
+			},
+		}
+	})
+
+	It("Should accept a valid user and default its name and Secret", func() {
+		_, err := user.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(user.ElasticsearchUsername()).To(Equal("alice"))
+		Expect(user.PasswordSecretName()).To(Equal("alice-es-user"))
+
+		user.Spec.PasswordSecret = &corev1.LocalObjectReference{Name: "alice-password"}
+		Expect(user.PasswordSecretName()).To(Equal("alice-password"))
+	})
+
+	It("Should reject the built-in users and invalid names", func() {
+		user.Spec.Username = "kibana_system"
+		_, err := user.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("reserved"))
+
+		user.Spec.Username = " alice"
+		_, err = user.ValidateCreate()
+		Expect(err.Error()).To(ContainSubstring("whitespace"))
+
+		user.Spec.Username = strings.Repeat("a", 508)
+		_, err = user.ValidateCreate()
+		Expect(err.Error()).To(ContainSubstring("spec.username"))
+	})
+
+	It("Should only grant the privileged roles in the namespace of the stack", func() {
+		user.Spec.Roles = []string{"team-a-read", "superuser", "kibana_admin"}
+		_, err := user.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.roles[1]"))
+		Expect(err.Error()).To(ContainSubstring("spec.roles[2]"))
+		Expect(err.Error()).NotTo(ContainSubstring("spec.roles[0]"))
+
+		user.Namespace = "logging"
+		_, err = user.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject a change of the username", func() {
+		updated := user.DeepCopy()
+		updated.Spec.Username = "bob"
+		_, err := updated.ValidateUpdate(user)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("immutable"))
+
+		// Renseigner explicitement le nom par défaut n'est pas un changement
+		updated.Spec.Username = "alice"
+		updated.Spec.Roles = append(updated.Spec.Roles, "monitoring_user")
+		_, err = updated.ValidateUpdate(user)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
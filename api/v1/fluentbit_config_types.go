@@ -0,0 +1,443 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// FluentBitConfig defines the Fluent Bit pipeline rendered by the operator
+type FluentBitConfig struct {
+	// Section [SERVICE]
+	// +optional
+	Service FluentBitService `json:"service,omitempty"`
+
+	// Parsers ajoutés à parsers.conf (les parsers docker et cri sont toujours présents)
+	// +optional
+	Parsers []FluentBitParser `json:"parsers,omitempty"`
+
+	// Inputs ; par défaut, tail sur /var/log/containers/*.log
+	// +optional
+	Inputs []FluentBitInput `json:"inputs,omitempty"`
+
+	// Filtres, appliqués dans l'ordre ; par défaut, le filtre kubernetes
+	// +optional
+	Filters []FluentBitFilter `json:"filters,omitempty"`
+
+	// Outputs ; par défaut, l'Elasticsearch de la stack
+	// +optional
+	Outputs []FluentBitOutput `json:"outputs,omitempty"`
+}
+
+// FluentBitService defines the [SERVICE] section
+type FluentBitService struct {
+	// Intervalle de flush en secondes
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Flush int32 `json:"flush,omitempty"`
+
+	// Niveau de log de Fluent Bit
+	// +kubebuilder:validation:Enum=off;error;warn;info;debug;trace
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// FluentBitParser defines an entry of parsers.conf
+type FluentBitParser struct {
+	// Nom du parser, référencé par les inputs et filtres
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Format du parser
+	// +kubebuilder:validation:Enum=json;regex;logfmt;ltsv
+	// +kubebuilder:validation:Required
+	Format string `json:"format"`
+
+	// Expression régulière (format regex uniquement)
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Clé contenant l'horodatage
+	// +optional
+	TimeKey string `json:"timeKey,omitempty"`
+
+	// Format de l'horodatage (strptime)
+	// +optional
+	TimeFormat string `json:"timeFormat,omitempty"`
+}
+
+// FluentBitInput defines an [INPUT] section. Exactly one plugin must be set
+type FluentBitInput struct {
+	// Tag des enregistrements produits
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Plugin tail
+	// +optional
+	Tail *TailInput `json:"tail,omitempty"`
+
+	// Plugin systemd
+	// +optional
+	Systemd *SystemdInput `json:"systemd,omitempty"`
+
+	// Plugin forward
+	// +optional
+	Forward *ForwardInput `json:"forward,omitempty"`
+
+	// Propriétés supplémentaires du plugin, écrites telles quelles
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// TailInput defines the tail input plugin
+type TailInput struct {
+	// Chemin (glob) des fichiers lus
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Chemins exclus (glob)
+	// +optional
+	ExcludePath string `json:"excludePath,omitempty"`
+
+	// Parser appliqué à chaque ligne
+	// +optional
+	Parser string `json:"parser,omitempty"`
+
+	// Parsers multiline intégrés (docker, cri, go, python, java)
+	// +optional
+	MultilineParsers []string `json:"multilineParsers,omitempty"`
+
+	// Limite mémoire du buffer (ex : 50MB)
+	// +optional
+	MemBufLimit string `json:"memBufLimit,omitempty"`
+
+	// Ignorer les lignes plus longues que le buffer
+	// +optional
+	SkipLongLines bool `json:"skipLongLines,omitempty"`
+
+	// Intervalle de rafraîchissement de la liste des fichiers, en secondes
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RefreshInterval int32 `json:"refreshInterval,omitempty"`
+}
+
+// SystemdInput defines the systemd input plugin
+type SystemdInput struct {
+	// Filtres sur les champs du journal (ex : _SYSTEMD_UNIT=kubelet.service)
+	// +optional
+	Filters []string `json:"filters,omitempty"`
+
+	// Commencer la lecture à la fin du journal
+	// +optional
+	ReadFromTail bool `json:"readFromTail,omitempty"`
+
+	// Supprimer le préfixe _ des champs du journal
+	// +optional
+	StripUnderscores bool `json:"stripUnderscores,omitempty"`
+}
+
+// ForwardInput defines the forward input plugin
+type ForwardInput struct {
+	// Adresse d'écoute
+	// +optional
+	Listen string `json:"listen,omitempty"`
+
+	// Port d'écoute
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// FluentBitFilter defines a [FILTER] section. Exactly one plugin must be set
+type FluentBitFilter struct {
+	// Motif des tags filtrés ; par défaut, tous les tags
+	// +optional
+	Match string `json:"match,omitempty"`
+
+	// Plugin kubernetes
+	// +optional
+	Kubernetes *KubernetesFilter `json:"kubernetes,omitempty"`
+
+	// Plugin modify
+	// +optional
+	Modify *ModifyFilter `json:"modify,omitempty"`
+
+	// Plugin grep
+	// +optional
+	Grep *GrepFilter `json:"grep,omitempty"`
+
+	// Plugin nest
+	// +optional
+	Nest *NestFilter `json:"nest,omitempty"`
+
+	// Plugin lua
+	// +optional
+	Lua *LuaFilter `json:"lua,omitempty"`
+
+	// Plugin multiline
+	// +optional
+	Multiline *MultilineFilter `json:"multiline,omitempty"`
+
+	// Plugin parser
+	// +optional
+	Parser *ParserFilter `json:"parser,omitempty"`
+
+	// Propriétés supplémentaires du plugin, écrites telles quelles
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// KubernetesFilter defines the kubernetes filter plugin
+type KubernetesFilter struct {
+	// Parser le champ log s'il contient du JSON
+	// +optional
+	MergeLog bool `json:"mergeLog,omitempty"`
+
+	// Conserver le champ log original après fusion
+	// +optional
+	KeepLog bool `json:"keepLog,omitempty"`
+
+	// Ajouter les labels des pods
+	// +optional
+	Labels *bool `json:"labels,omitempty"`
+
+	// Ajouter les annotations des pods
+	// +optional
+	Annotations *bool `json:"annotations,omitempty"`
+
+	// Autoriser les pods à suggérer un parser via l'annotation fluentbit.io/parser
+	// +optional
+	K8sLoggingParser bool `json:"k8sLoggingParser,omitempty"`
+
+	// Autoriser les pods à exclure leurs logs via l'annotation fluentbit.io/exclude
+	// +optional
+	K8sLoggingExclude bool `json:"k8sLoggingExclude,omitempty"`
+}
+
+// ModifyFilter defines the modify filter plugin
+type ModifyFilter struct {
+	// Champs définis ou remplacés
+	// +optional
+	Set map[string]string `json:"set,omitempty"`
+
+	// Champs ajoutés s'ils n'existent pas
+	// +optional
+	Add map[string]string `json:"add,omitempty"`
+
+	// Champs supprimés
+	// +optional
+	Remove []string `json:"remove,omitempty"`
+
+	// Champs renommés (ancien nom -> nouveau nom)
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// GrepFilter defines the grep filter plugin
+type GrepFilter struct {
+	// Enregistrements conservés si la clé correspond au motif
+	// +optional
+	Regex []GrepRule `json:"regex,omitempty"`
+
+	// Enregistrements exclus si la clé correspond au motif
+	// +optional
+	Exclude []GrepRule `json:"exclude,omitempty"`
+}
+
+// GrepRule matches a record key against a regular expression
+type GrepRule struct {
+	// Clé de l'enregistrement
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+
+	// Expression régulière
+	// +kubebuilder:validation:Required
+	Pattern string `json:"pattern"`
+}
+
+// NestFilter defines the nest filter plugin
+type NestFilter struct {
+	// Opération : nest (regrouper) ou lift (remonter)
+	// +kubebuilder:validation:Enum=nest;lift
+	// +kubebuilder:validation:Required
+	Operation string `json:"operation"`
+
+	// Clés regroupées (opération nest)
+	// +optional
+	Wildcard []string `json:"wildcard,omitempty"`
+
+	// Clé sous laquelle regrouper (opération nest)
+	// +optional
+	NestUnder string `json:"nestUnder,omitempty"`
+
+	// Clé à remonter (opération lift)
+	// +optional
+	NestedUnder string `json:"nestedUnder,omitempty"`
+
+	// Préfixe ajouté aux clés
+	// +optional
+	AddPrefix string `json:"addPrefix,omitempty"`
+
+	// Préfixe retiré des clés
+	// +optional
+	RemovePrefix string `json:"removePrefix,omitempty"`
+}
+
+// LuaFilter defines the lua filter plugin
+type LuaFilter struct {
+	// Code du script Lua, monté dans le ConfigMap Fluent Bit
+	// +kubebuilder:validation:Required
+	Script string `json:"script"`
+
+	// Fonction appelée pour chaque enregistrement
+	// +kubebuilder:validation:Required
+	Call string `json:"call"`
+}
+
+// MultilineFilter defines the multiline filter plugin
+type MultilineFilter struct {
+	// Parsers multiline intégrés (docker, cri, go, python, java)
+	// +kubebuilder:validation:MinItems=1
+	Parsers []string `json:"parsers"`
+
+	// Clé contenant le message à concaténer
+	// +optional
+	KeyContent string `json:"keyContent,omitempty"`
+}
+
+// ParserFilter defines the parser filter plugin
+type ParserFilter struct {
+	// Clé contenant le texte à parser
+	// +kubebuilder:validation:Required
+	KeyName string `json:"keyName"`
+
+	// Parsers essayés dans l'ordre
+	// +kubebuilder:validation:MinItems=1
+	Parsers []string `json:"parsers"`
+
+	// Conserver les autres champs de l'enregistrement
+	// +optional
+	ReserveData bool `json:"reserveData,omitempty"`
+}
+
+// FluentBitOutput defines an [OUTPUT] section. Exactly one plugin must be set
+type FluentBitOutput struct {
+	// Motif des tags envoyés ; par défaut, tous les tags
+	// +optional
+	Match string `json:"match,omitempty"`
+
+	// Plugin es
+	// +optional
+	Elasticsearch *ElasticsearchOutput `json:"elasticsearch,omitempty"`
+
+	// Plugin forward
+	// +optional
+	Forward *ForwardOutput `json:"forward,omitempty"`
+
+	// Plugin http
+	// +optional
+	HTTP *HTTPOutput `json:"http,omitempty"`
+
+	// Plugin stdout
+	// +optional
+	Stdout *StdoutOutput `json:"stdout,omitempty"`
+
+	// Propriétés supplémentaires du plugin, écrites telles quelles
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ElasticsearchOutput defines the es output plugin
+type ElasticsearchOutput struct {
+	// Hôte Elasticsearch ; par défaut, le service Elasticsearch de la stack
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port Elasticsearch
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Index cible (ou préfixe si logstashFormat est activé)
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// Créer un index par jour (<index>-YYYY.MM.DD)
+	// +optional
+	LogstashFormat bool `json:"logstashFormat,omitempty"`
+
+	// Écrire dans le data stream <index> (incompatible avec logstashFormat)
+	// +optional
+	DataStream bool `json:"dataStream,omitempty"`
+
+	// Activer TLS
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// Remplacer les points des noms de champs par des underscores
+	// +optional
+	ReplaceDots bool `json:"replaceDots,omitempty"`
+}
+
+// ForwardOutput defines the forward output plugin
+type ForwardOutput struct {
+	// Hôte cible
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// Port cible
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// HTTPOutput defines the http output plugin
+type HTTPOutput struct {
+	// Hôte cible
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// Port cible
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Chemin de la requête
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Format du corps de la requête
+	// +kubebuilder:validation:Enum=json;json_lines;json_stream;msgpack;gelf
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Activer TLS
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// En-têtes HTTP ajoutés à chaque requête
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// StdoutOutput defines the stdout output plugin
+type StdoutOutput struct {
+	// Format de sortie
+	// +kubebuilder:validation:Enum=msgpack;json;json_lines;json_stream
+	// +optional
+	Format string `json:"format,omitempty"`
+}
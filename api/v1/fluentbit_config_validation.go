@@ -0,0 +1,315 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	// BuiltinFluentBitParsers are always present in the rendered parsers.conf
+	BuiltinFluentBitParsers = []string{"docker", "cri", "json"}
+	// BuiltinMultilineParsers are the multiline parsers shipped with Fluent Bit
+	BuiltinMultilineParsers = []string{"docker", "cri", "go", "python", "java", "ruby"}
+
+	fluentBitNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	luaFunctionRegexp   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Validate checks that the pipeline can be rendered into a valid fluent-bit.conf
+func (c *FluentBitConfig) Validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	parsers := map[string]bool{}
+	for _, name := range BuiltinFluentBitParsers {
+		parsers[name] = true
+	}
+	for i, parser := range c.Parsers {
+		p := path.Child("parsers").Index(i)
+		if !fluentBitNameRegexp.MatchString(parser.Name) {
+			allErrs = append(allErrs, field.Invalid(p.Child("name"), parser.Name, "must contain only alphanumeric characters, '-', '_' or '.'"))
+		} else if parsers[parser.Name] {
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), parser.Name))
+		}
+		parsers[parser.Name] = true
+		if parser.Format == "regex" && parser.Regex == "" {
+			allErrs = append(allErrs, field.Required(p.Child("regex"), "is required with the regex format"))
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("regex"), parser.Regex)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("timeFormat"), parser.TimeFormat)...)
+	}
+
+	for i := range c.Inputs {
+		allErrs = append(allErrs, c.Inputs[i].validate(path.Child("inputs").Index(i), parsers)...)
+	}
+	for i := range c.Filters {
+		allErrs = append(allErrs, c.Filters[i].validate(path.Child("filters").Index(i), parsers)...)
+	}
+	for i := range c.Outputs {
+		allErrs = append(allErrs, c.Outputs[i].validate(path.Child("outputs").Index(i))...)
+	}
+
+	return allErrs
+}
+
+func (in *FluentBitInput) validate(path *field.Path, parsers map[string]bool) field.ErrorList {
+	allErrs := validatePluginCount(path, countSet(in.Tail != nil, in.Systemd != nil, in.Forward != nil), "tail, systemd, forward")
+	allErrs = append(allErrs, validateSingleLine(path.Child("tag"), in.Tag)...)
+	allErrs = append(allErrs, validateProperties(path.Child("properties"), in.Properties)...)
+
+	if in.Tail != nil {
+		p := path.Child("tail")
+		if in.Tail.Path == "" {
+			allErrs = append(allErrs, field.Required(p.Child("path"), ""))
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("path"), in.Tail.Path)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("excludePath"), in.Tail.ExcludePath)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("memBufLimit"), in.Tail.MemBufLimit)...)
+		if in.Tail.Parser != "" && !parsers[in.Tail.Parser] {
+			allErrs = append(allErrs, field.NotFound(p.Child("parser"), in.Tail.Parser))
+		}
+		allErrs = append(allErrs, validateMultilineParsers(p.Child("multilineParsers"), in.Tail.MultilineParsers)...)
+	}
+	if in.Systemd != nil {
+		for i, filter := range in.Systemd.Filters {
+			p := path.Child("systemd", "filters").Index(i)
+			if !strings.Contains(filter, "=") {
+				allErrs = append(allErrs, field.Invalid(p, filter, "must be of the form FIELD=value"))
+			}
+			allErrs = append(allErrs, validateSingleLine(p, filter)...)
+		}
+	}
+	if in.Forward != nil {
+		allErrs = append(allErrs, validateSingleLine(path.Child("forward", "listen"), in.Forward.Listen)...)
+	}
+
+	return allErrs
+}
+
+func (f *FluentBitFilter) validate(path *field.Path, parsers map[string]bool) field.ErrorList {
+	allErrs := validatePluginCount(path, countSet(f.Kubernetes != nil, f.Modify != nil, f.Grep != nil, f.Nest != nil, f.Lua != nil, f.Multiline != nil, f.Parser != nil),
+		"kubernetes, modify, grep, nest, lua, multiline, parser")
+	allErrs = append(allErrs, validateSingleLine(path.Child("match"), f.Match)...)
+	allErrs = append(allErrs, validateProperties(path.Child("properties"), f.Properties)...)
+
+	if f.Modify != nil {
+		p := path.Child("modify")
+		if len(f.Modify.Set)+len(f.Modify.Add)+len(f.Modify.Remove)+len(f.Modify.Rename) == 0 {
+			allErrs = append(allErrs, field.Required(p, "at least one of set, add, remove or rename is required"))
+		}
+		allErrs = append(allErrs, validateRecordMap(p.Child("set"), f.Modify.Set)...)
+		allErrs = append(allErrs, validateRecordMap(p.Child("add"), f.Modify.Add)...)
+		allErrs = append(allErrs, validateRecordMap(p.Child("rename"), f.Modify.Rename)...)
+		for i, key := range f.Modify.Remove {
+			allErrs = append(allErrs, validateRecordKey(p.Child("remove").Index(i), key)...)
+		}
+	}
+	if f.Grep != nil {
+		p := path.Child("grep")
+		if len(f.Grep.Regex)+len(f.Grep.Exclude) == 0 {
+			allErrs = append(allErrs, field.Required(p, "at least one regex or exclude rule is required"))
+		}
+		for i, rule := range f.Grep.Regex {
+			allErrs = append(allErrs, rule.validate(p.Child("regex").Index(i))...)
+		}
+		for i, rule := range f.Grep.Exclude {
+			allErrs = append(allErrs, rule.validate(p.Child("exclude").Index(i))...)
+		}
+	}
+	if f.Nest != nil {
+		p := path.Child("nest")
+		switch f.Nest.Operation {
+		case "nest":
+			if len(f.Nest.Wildcard) == 0 {
+				allErrs = append(allErrs, field.Required(p.Child("wildcard"), "is required by the nest operation"))
+			}
+			if f.Nest.NestUnder == "" {
+				allErrs = append(allErrs, field.Required(p.Child("nestUnder"), "is required by the nest operation"))
+			}
+		case "lift":
+			if f.Nest.NestedUnder == "" {
+				allErrs = append(allErrs, field.Required(p.Child("nestedUnder"), "is required by the lift operation"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(p.Child("operation"), f.Nest.Operation, []string{"nest", "lift"}))
+		}
+		for i, wildcard := range f.Nest.Wildcard {
+			allErrs = append(allErrs, validateRecordKey(p.Child("wildcard").Index(i), wildcard)...)
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("nestUnder"), f.Nest.NestUnder)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("nestedUnder"), f.Nest.NestedUnder)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("addPrefix"), f.Nest.AddPrefix)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("removePrefix"), f.Nest.RemovePrefix)...)
+	}
+	if f.Lua != nil {
+		p := path.Child("lua")
+		if strings.TrimSpace(f.Lua.Script) == "" {
+			allErrs = append(allErrs, field.Required(p.Child("script"), ""))
+		}
+		if !luaFunctionRegexp.MatchString(f.Lua.Call) {
+			allErrs = append(allErrs, field.Invalid(p.Child("call"), f.Lua.Call, "must be a Lua function name"))
+		} else if !strings.Contains(f.Lua.Script, f.Lua.Call) {
+			allErrs = append(allErrs, field.Invalid(p.Child("call"), f.Lua.Call, "is not defined in the script"))
+		}
+	}
+	if f.Multiline != nil {
+		p := path.Child("multiline")
+		if len(f.Multiline.Parsers) == 0 {
+			allErrs = append(allErrs, field.Required(p.Child("parsers"), ""))
+		}
+		allErrs = append(allErrs, validateMultilineParsers(p.Child("parsers"), f.Multiline.Parsers)...)
+		allErrs = append(allErrs, validateSingleLine(p.Child("keyContent"), f.Multiline.KeyContent)...)
+	}
+	if f.Parser != nil {
+		p := path.Child("parser")
+		allErrs = append(allErrs, validateRecordKey(p.Child("keyName"), f.Parser.KeyName)...)
+		if len(f.Parser.Parsers) == 0 {
+			allErrs = append(allErrs, field.Required(p.Child("parsers"), ""))
+		}
+		for i, parser := range f.Parser.Parsers {
+			if !parsers[parser] {
+				allErrs = append(allErrs, field.NotFound(p.Child("parsers").Index(i), parser))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func (o *FluentBitOutput) validate(path *field.Path) field.ErrorList {
+	allErrs := validatePluginCount(path, countSet(o.Elasticsearch != nil, o.Forward != nil, o.HTTP != nil, o.Stdout != nil), "elasticsearch, forward, http, stdout")
+	allErrs = append(allErrs, validateSingleLine(path.Child("match"), o.Match)...)
+	allErrs = append(allErrs, validateProperties(path.Child("properties"), o.Properties)...)
+
+	if o.Elasticsearch != nil {
+		p := path.Child("elasticsearch")
+		allErrs = append(allErrs, validateSingleLine(p.Child("host"), o.Elasticsearch.Host)...)
+		// Sans host, la sortie hérite de l'adresse et des credentials de la stack
+		if o.Elasticsearch.Host == "" && len(o.Properties) > 0 {
+			allErrs = append(allErrs, field.Forbidden(path.Child("properties"), "cannot be set on an output to the Elasticsearch of the stack, which inherits its host and credentials"))
+		}
+		if o.Elasticsearch.Index != "" && !fluentBitNameRegexp.MatchString(o.Elasticsearch.Index) {
+			allErrs = append(allErrs, field.Invalid(p.Child("index"), o.Elasticsearch.Index, "must contain only alphanumeric characters, '-', '_' or '.'"))
+		}
+		if o.Elasticsearch.DataStream && o.Elasticsearch.LogstashFormat {
+			allErrs = append(allErrs, field.Invalid(p.Child("dataStream"), true, "cannot be combined with logstashFormat"))
+		}
+	}
+	if o.Forward != nil {
+		p := path.Child("forward")
+		if o.Forward.Host == "" {
+			allErrs = append(allErrs, field.Required(p.Child("host"), ""))
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("host"), o.Forward.Host)...)
+	}
+	if o.HTTP != nil {
+		p := path.Child("http")
+		if o.HTTP.Host == "" {
+			allErrs = append(allErrs, field.Required(p.Child("host"), ""))
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("host"), o.HTTP.Host)...)
+		if o.HTTP.URI != "" && !strings.HasPrefix(o.HTTP.URI, "/") {
+			allErrs = append(allErrs, field.Invalid(p.Child("uri"), o.HTTP.URI, "must start with /"))
+		}
+		allErrs = append(allErrs, validateSingleLine(p.Child("uri"), o.HTTP.URI)...)
+		allErrs = append(allErrs, validateRecordMap(p.Child("headers"), o.HTTP.Headers)...)
+	}
+
+	return allErrs
+}
+
+func (r GrepRule) validate(path *field.Path) field.ErrorList {
+	allErrs := validateRecordKey(path.Child("key"), r.Key)
+	if r.Pattern == "" {
+		allErrs = append(allErrs, field.Required(path.Child("pattern"), ""))
+	}
+	return append(allErrs, validateSingleLine(path.Child("pattern"), r.Pattern)...)
+}
+
+func countSet(plugins ...bool) int {
+	count := 0
+	for _, set := range plugins {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+func validatePluginCount(path *field.Path, count int, plugins string) field.ErrorList {
+	if count != 1 {
+		return field.ErrorList{field.Invalid(path, count, "exactly one plugin must be set among "+plugins)}
+	}
+	return nil
+}
+
+func validateMultilineParsers(path *field.Path, parsers []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, parser := range parsers {
+		supported := false
+		for _, builtin := range BuiltinMultilineParsers {
+			if parser == builtin {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			allErrs = append(allErrs, field.NotSupported(path.Index(i), parser, BuiltinMultilineParsers))
+		}
+	}
+	return allErrs
+}
+
+// validateProperties checks the raw plugin properties, which are written verbatim
+func validateProperties(path *field.Path, properties map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	for key, value := range properties {
+		if !fluentBitNameRegexp.MatchString(key) {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), key, "must contain only alphanumeric characters, '-', '_' or '.'"))
+		}
+		allErrs = append(allErrs, validateSingleLine(path.Key(key), value)...)
+	}
+	return allErrs
+}
+
+func validateRecordMap(path *field.Path, values map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	for key, value := range values {
+		allErrs = append(allErrs, validateRecordKey(path.Key(key), key)...)
+		allErrs = append(allErrs, validateSingleLine(path.Key(key), value)...)
+	}
+	return allErrs
+}
+
+// validateRecordKey rejects keys that would be split by the classic config format
+func validateRecordKey(path *field.Path, key string) field.ErrorList {
+	if key == "" || strings.ContainsAny(key, " \t\r\n") {
+		return field.ErrorList{field.Invalid(path, key, "must be a non-empty key without whitespace")}
+	}
+	return nil
+}
+
+// validateSingleLine rejects values that would inject new lines in fluent-bit.conf
+func validateSingleLine(path *field.Path, value string) field.ErrorList {
+	if strings.ContainsAny(value, "\r\n") {
+		return field.ErrorList{field.Invalid(path, value, "must not contain line breaks")}
+	}
+	return nil
+}
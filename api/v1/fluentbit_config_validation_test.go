@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ = Describe("FluentBitConfig validation", func() {
+	path := field.NewPath("spec", "fluentBit", "config")
+
+	It("Should accept an empty pipeline", func() {
+		Expect((&FluentBitConfig{}).Validate(path)).To(BeEmpty())
+	})
+
+	It("Should require exactly one plugin per section", func() {
+		cfg := &FluentBitConfig{
+			Filters: []FluentBitFilter{{Match: "*"}},
+			Outputs: []FluentBitOutput{{Match: "*", Stdout: &StdoutOutput{}, Forward: &ForwardOutput{Host: "aggregator"}}},
+		}
+		errs := cfg.Validate(path)
+		Expect(errs).To(HaveLen(2))
+		Expect(errs[0].Field).To(Equal("spec.fluentBit.config.filters[0]"))
+		Expect(errs[1].Field).To(Equal("spec.fluentBit.config.outputs[0]"))
+	})
+
+	It("Should reject a tail input referencing an unknown parser", func() {
+		cfg := &FluentBitConfig{
+			Inputs: []FluentBitInput{{Tail: &TailInput{Path: "/var/log/*.log", Parser: "nginx"}}},
+		}
+		Expect(cfg.Validate(path)).To(HaveLen(1))
+
+		cfg.Parsers = []FluentBitParser{{Name: "nginx", Format: "json"}}
+		Expect(cfg.Validate(path)).To(BeEmpty())
+	})
+
+	It("Should reject values that would inject configuration lines", func() {
+		cfg := &FluentBitConfig{
+			Outputs: []FluentBitOutput{{Match: "*\n[OUTPUT]", Stdout: &StdoutOutput{}}},
+		}
+		errs := cfg.Validate(path)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Detail).To(ContainSubstring("line breaks"))
+
+		cfg = &FluentBitConfig{
+			Inputs: []FluentBitInput{{Tail: &TailInput{Path: "/var/log/containers/*.log", MemBufLimit: "5MB\n[OUTPUT]\n    Name http"}}},
+		}
+		errs = cfg.Validate(path)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.fluentBit.config.inputs[0].tail.memBufLimit"))
+	})
+
+	It("Should check the operation of the nest filter", func() {
+		cfg := &FluentBitConfig{
+			Filters: []FluentBitFilter{{Match: "*", Nest: &NestFilter{Operation: "nest", NestUnder: "labels"}}},
+		}
+		errs := cfg.Validate(path)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.fluentBit.config.filters[0].nest.wildcard"))
+	})
+
+	It("Should require the lua function to be defined in the script", func() {
+		cfg := &FluentBitConfig{
+			Filters: []FluentBitFilter{{Match: "*", Lua: &LuaFilter{Script: "function a(tag, ts, record) return 0, ts, record end", Call: "b"}}},
+		}
+		Expect(cfg.Validate(path)).To(HaveLen(1))
+	})
+})
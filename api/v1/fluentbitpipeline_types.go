@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StackReference references an EFKStack
+type StackReference struct {
+	// Nom de l'EFKStack
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace de l'EFKStack ; par défaut, celui de la ressource qui la référence
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NamespaceOr returns the referenced namespace, or the given one when unset
+func (s StackReference) NamespaceOr(namespace string) string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+	return namespace
+}
+
+// FluentBitPipelineSpec defines the desired state of FluentBitPipeline
+type FluentBitPipelineSpec struct {
+	// EFKStack dont le Fluent Bit reçoit ce fragment
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Préfixe d'index Elasticsearch des logs des pods de ce namespace
+	// (appliqué aux outputs elasticsearch de la stack en logstashFormat)
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// Parsers ajoutés à parsers.conf, utilisables via l'annotation fluentbit.io/parser
+	// ou le filtre parser
+	// +optional
+	Parsers []FluentBitParser `json:"parsers,omitempty"`
+
+	// Filtres appliqués aux logs des pods de ce namespace, après ceux de la stack.
+	// Le champ match est positionné par l'opérateur
+	// +optional
+	Filters []FluentBitFilter `json:"filters,omitempty"`
+
+	// Outputs supplémentaires recevant les logs des pods de ce namespace.
+	// Le champ match est positionné par l'opérateur
+	// +optional
+	Outputs []FluentBitOutput `json:"outputs,omitempty"`
+}
+
+// FluentBitPipelineStatus defines the observed state of FluentBitPipeline
+type FluentBitPipelineStatus struct {
+	// Génération de la spec prise en compte
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions (Accepted)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// ConditionAccepted indique si le fragment a été fusionné dans la configuration Fluent Bit
+	ConditionAccepted = "Accepted"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=fbp
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Accepted",type="string",JSONPath=".status.conditions[?(@.type==\"Accepted\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Accepted\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// FluentBitPipeline is the Schema for the fluentbitpipelines API. It lets the owners
+// of a namespace add parsers, filters and outputs for their own pods to the Fluent Bit
+// configuration of an EFKStack
+type FluentBitPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FluentBitPipelineSpec   `json:"spec,omitempty"`
+	Status FluentBitPipelineStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FluentBitPipelineList contains a list of FluentBitPipeline
+type FluentBitPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FluentBitPipeline `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FluentBitPipeline{}, &FluentBitPipelineList{})
+}
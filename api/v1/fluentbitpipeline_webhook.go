@@ -0,0 +1,192 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// pipelineReservedProperties are the keys that would let a fragment change its plugin or route
+// the records of the other namespaces. Fluent Bit compares the keys without case
+var pipelineReservedProperties = map[string]bool{
+	"name":        true,
+	"match":       true,
+	"match_regex": true,
+	"tag":         true,
+	"alias":       true,
+}
+
+// pipelineConnectionProperties are the keys that would send the records, and the credentials of
+// the stack inherited by elasticsearch outputs, to another endpoint. The keys starting with one of
+// pipelineConnectionPrefixes are also rejected
+var pipelineConnectionProperties = map[string]bool{
+	"host":        true,
+	"port":        true,
+	"http_user":   true,
+	"http_passwd": true,
+	"cloud_id":    true,
+	"cloud_auth":  true,
+	"upstream":    true,
+}
+
+var pipelineConnectionPrefixes = []string{"tls", "aws_"}
+
+// log is for logging in this package.
+var fluentbitpipelinelog = logf.Log.WithName("fluentbitpipeline-resource")
+
+// SetupWebhookWithManager registers the validating webhook of FluentBitPipeline
+func (r *FluentBitPipeline) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-fluentbitpipeline,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=fluentbitpipelines,verbs=create;update,versions=v1,name=vfluentbitpipeline.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &FluentBitPipeline{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *FluentBitPipeline) ValidateCreate() (admission.Warnings, error) {
+	fluentbitpipelinelog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *FluentBitPipeline) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	fluentbitpipelinelog.Info("validate update", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *FluentBitPipeline) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks that the fragment can be merged into the Fluent Bit configuration.
+// Conflicts with the other fragments of the stack are reported in the status instead
+func (r *FluentBitPipeline) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	if r.Spec.Index != "" && !fluentBitNameRegexp.MatchString(r.Spec.Index) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("index"), r.Spec.Index, "must contain only alphanumeric characters, '-', '_' or '.'"))
+	} else if r.Spec.Index != "" && !r.namespaceIndex(r.Spec.Index) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("index"), r.Spec.Index, r.namespaceIndexMessage()))
+	}
+
+	// Le match est imposé par l'opérateur pour limiter le fragment aux pods du namespace
+	for i, filter := range r.Spec.Filters {
+		p := specPath.Child("filters").Index(i)
+		if filter.Match != "" {
+			allErrs = append(allErrs, field.Forbidden(p.Child("match"), "is set by the operator to the pods of the namespace"))
+		}
+		if filter.Kubernetes != nil {
+			allErrs = append(allErrs, field.Forbidden(p.Child("kubernetes"), "can only be configured in the EFKStack"))
+		}
+		// Le script Lua s'exécute dans le DaemonSet avec les logs de tous les namespaces
+		if filter.Lua != nil {
+			allErrs = append(allErrs, field.Forbidden(p.Child("lua"), "runs code in the Fluent Bit DaemonSet and can only be configured in the EFKStack"))
+		}
+		allErrs = append(allErrs, validatePipelineProperties(p.Child("properties"), filter.Properties)...)
+	}
+	for i, output := range r.Spec.Outputs {
+		p := specPath.Child("outputs").Index(i)
+		if output.Match != "" {
+			allErrs = append(allErrs, field.Forbidden(p.Child("match"), "is set by the operator to the pods of the namespace"))
+		}
+		allErrs = append(allErrs, validatePipelineProperties(p.Child("properties"), output.Properties)...)
+		// Les sorties sans host écrivent avec les credentials de la stack
+		if es := output.Elasticsearch; es != nil && es.Host == "" && es.Index != "" && !r.namespaceIndex(es.Index) {
+			allErrs = append(allErrs, field.Invalid(p.Child("elasticsearch", "index"), es.Index, r.namespaceIndexMessage()))
+		}
+	}
+
+	cfg := FluentBitConfig{
+		Parsers: r.Spec.Parsers,
+		Filters: r.Spec.Filters,
+		Outputs: r.Spec.Outputs,
+	}
+	return append(allErrs, cfg.Validate(specPath)...)
+}
+
+// namespaceIndex reports whether an index written with the credentials of the stack belongs to
+// the namespace of the fragment, so that a team cannot write into the indices of another one
+func (r *FluentBitPipeline) namespaceIndex(index string) bool {
+	return index == r.Namespace || strings.HasPrefix(index, r.Namespace+"-")
+}
+
+func (r *FluentBitPipeline) namespaceIndexMessage() string {
+	return fmt.Sprintf("must be %s or start with %s-", r.Namespace, r.Namespace)
+}
+
+// validatePipelineProperties rejects the raw properties that override the plugin or the
+// routing set by the operator
+func validatePipelineProperties(path *field.Path, properties map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	// Ordre stable pour des messages d'erreur reproductibles
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch name := strings.ToLower(key); {
+		case pipelineReservedProperties[name]:
+			allErrs = append(allErrs, field.Forbidden(path.Key(key), "is set by the operator and cannot be overridden by a FluentBitPipeline"))
+		case pipelineConnectionProperty(name):
+			allErrs = append(allErrs, field.Forbidden(path.Key(key), "sets the connection of the plugin and can only be configured with its typed fields"))
+		}
+	}
+	return allErrs
+}
+
+// pipelineConnectionProperty reports whether a lower-case key sets the endpoint or the credentials of a plugin
+func pipelineConnectionProperty(name string) bool {
+	if pipelineConnectionProperties[name] {
+		return true
+	}
+	for _, prefix := range pipelineConnectionPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *FluentBitPipeline) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "FluentBitPipeline"}, r.Name, allErrs)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("FluentBitPipeline Webhook", func() {
+	var pipeline *FluentBitPipeline
+
+	BeforeEach(func() {
+		pipeline = &FluentBitPipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a"},
+			Spec: FluentBitPipelineSpec{
+				StackRef: StackReference{Name: "test-efk-stack", Namespace: "logging"},
+				Index:    "team-a",
+				Filters:  []FluentBitFilter{{Grep: &GrepFilter{Exclude: []GrepRule{{Key: "log", Pattern: "healthz"}}}}},
+			},
+		}
+	})
+
+	It("Should accept a valid fragment", func() {
+		_, err := pipeline.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject a match set by the user", func() {
+		pipeline.Spec.Filters[0].Match = "*"
+		_, err := pipeline.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.filters[0].match"))
+	})
+
+	It("Should reject the kubernetes filter", func() {
+		pipeline.Spec.Filters = append(pipeline.Spec.Filters, FluentBitFilter{Kubernetes: &KubernetesFilter{}})
+		_, err := pipeline.ValidateUpdate(pipeline)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.filters[1].kubernetes"))
+	})
+
+	It("Should reject properties overriding the routing of the fragment", func() {
+		pipeline.Spec.Filters[0].Properties = map[string]string{"Match": "*", "match_regex": ".*", "Name": "lua"}
+		pipeline.Spec.Outputs = []FluentBitOutput{{
+			Stdout:     &StdoutOutput{},
+			Properties: map[string]string{"TAG": "kube.*", "Alias": "stdout", "Format": "json_lines"},
+		}}
+		_, err := pipeline.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		for _, path := range []string{
+			"spec.filters[0].properties[Match]",
+			"spec.filters[0].properties[match_regex]",
+			"spec.filters[0].properties[Name]",
+			"spec.outputs[0].properties[TAG]",
+			"spec.outputs[0].properties[Alias]",
+		} {
+			Expect(err.Error()).To(ContainSubstring(path))
+		}
+		Expect(err.Error()).NotTo(ContainSubstring("properties[Format]"))
+	})
+
+	It("Should reject properties changing the connection of an output", func() {
+		for _, key := range []string{"Host", "port", "HTTP_User", "HTTP_Passwd", "tls", "tls.verify", "tls.ca_file", "Cloud_ID", "Cloud_Auth", "AWS_Auth", "aws_region", "Upstream"} {
+			pipeline.Spec.Outputs = []FluentBitOutput{{
+				HTTP:       &HTTPOutput{Host: "collector.team-a"},
+				Properties: map[string]string{key: "value"},
+			}}
+			_, err := pipeline.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), key)
+			Expect(err.Error()).To(ContainSubstring("spec.outputs[0].properties[" + key + "]"))
+		}
+	})
+
+	It("Should reject properties on an output to the stack Elasticsearch", func() {
+		pipeline.Spec.Outputs = []FluentBitOutput{{
+			Elasticsearch: &ElasticsearchOutput{Index: "team-a"},
+			Properties:    map[string]string{"Buffer_Size": "1M"},
+		}}
+		_, err := pipeline.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.outputs[0].properties"))
+	})
+
+	It("Should only accept the indices of the namespace", func() {
+		for _, index := range []string{"team-a", "team-a-api"} {
+			pipeline.Spec.Index = index
+			_, err := pipeline.ValidateCreate()
+			Expect(err).NotTo(HaveOccurred(), index)
+		}
+		for _, index := range []string{"team-b", "team-ab", ".kibana_1", "fluent-bit"} {
+			pipeline.Spec.Index = index
+			_, err := pipeline.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), index)
+			Expect(err.Error()).To(ContainSubstring("spec.index"))
+		}
+
+		pipeline.Spec.Index = "team-a"
+		pipeline.Spec.Outputs = []FluentBitOutput{{Elasticsearch: &ElasticsearchOutput{Index: ".security-7"}}}
+		_, err := pipeline.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.outputs[0].elasticsearch.index"))
+
+		// Un cluster externe utilise ses propres credentials
+		pipeline.Spec.Outputs[0].Elasticsearch.Host = "es.team-a"
+		_, err = pipeline.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject the lua filter", func() {
+		pipeline.Spec.Filters = append(pipeline.Spec.Filters, FluentBitFilter{Lua: &LuaFilter{Script: "function cb(tag, ts, record) return 0, ts, record end", Call: "cb"}})
+		_, err := pipeline.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.filters[1].lua"))
+	})
+
+	It("Should default the stack namespace to the namespace of the fragment", func() {
+		Expect(pipeline.Spec.StackRef.NamespaceOr(pipeline.Namespace)).To(Equal("logging"))
+		pipeline.Spec.StackRef.Namespace = ""
+		Expect(pipeline.Spec.StackRef.NamespaceOr(pipeline.Namespace)).To(Equal("team-a"))
+	})
+})
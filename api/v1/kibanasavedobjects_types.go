@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KibanaSavedObjectsSpec defines the desired state of KibanaSavedObjects
+type KibanaSavedObjectsSpec struct {
+	// EFKStack dont le Kibana reçoit les objets
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Espace Kibana dans lequel les objets sont importés ; par défaut, l'espace default
+	// +optional
+	Space string `json:"space,omitempty"`
+
+	// Objets au format ndjson d'un export Kibana (Stack Management > Saved objects > Export)
+	// +optional
+	Objects string `json:"objects,omitempty"`
+
+	// ConfigMaps du namespace de la ressource dont chaque clé contient un export ndjson
+	// +optional
+	ConfigMapRefs []corev1.LocalObjectReference `json:"configMapRefs,omitempty"`
+
+	// Remplacer les objets existants de même ID
+	// +optional
+	// +kubebuilder:default=true
+	Overwrite *bool `json:"overwrite,omitempty"`
+}
+
+// KibanaSavedObjectsStatus defines the observed state of KibanaSavedObjects
+type KibanaSavedObjectsStatus struct {
+	// Génération de la spec importée
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Empreinte des objets importés, ils sont réimportés quand elle change
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// Date du dernier import
+	// +optional
+	LastImportTime *metav1.Time `json:"lastImportTime,omitempty"`
+
+	// Objets importés
+	// +optional
+	ImportedObjects []ImportedSavedObject `json:"importedObjects,omitempty"`
+
+	// Objets refusés par Kibana
+	// +optional
+	Errors []SavedObjectError `json:"errors,omitempty"`
+
+	// Conditions (Ready)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ImportedSavedObject is a saved object created or overwritten by the import
+type ImportedSavedObject struct {
+	// Type (index-pattern, dashboard, search, visualization, lens...)
+	Type string `json:"type"`
+
+	// ID de l'objet
+	ID string `json:"id"`
+
+	// Titre de l'objet
+	// +optional
+	Title string `json:"title,omitempty"`
+}
+
+// SavedObjectError is a saved object rejected by the import
+type SavedObjectError struct {
+	// Type de l'objet
+	Type string `json:"type"`
+
+	// ID de l'objet
+	ID string `json:"id"`
+
+	// Titre de l'objet
+	// +optional
+	Title string `json:"title,omitempty"`
+
+	// Raison du refus (conflict, missing_references, unsupported_type...)
+	Reason string `json:"reason"`
+
+	// Détail de l'erreur
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// OverwriteEnabled returns whether the existing objects are replaced, the default
+func (r *KibanaSavedObjects) OverwriteEnabled() bool {
+	return r.Spec.Overwrite == nil || *r.Spec.Overwrite
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=kibanasavedobjects,singular=kibanasavedobjects,shortName=kso
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Space",type="string",JSONPath=".spec.space"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Imported",type="date",priority=1,JSONPath=".status.lastImportTime"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KibanaSavedObjects is the Schema for the kibanasavedobjects API. It imports data views,
+// dashboards, searches and other saved objects into the Kibana of an EFKStack
+type KibanaSavedObjects struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KibanaSavedObjectsSpec   `json:"spec,omitempty"`
+	Status KibanaSavedObjectsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KibanaSavedObjectsList contains a list of KibanaSavedObjects
+type KibanaSavedObjectsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KibanaSavedObjects `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KibanaSavedObjects{}, &KibanaSavedObjectsList{})
+}
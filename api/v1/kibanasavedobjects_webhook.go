@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// kibanaSpaceRegexp matches the identifiers accepted by Kibana for its spaces
+var kibanaSpaceRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// log is for logging in this package.
+var kibanasavedobjectslog = logf.Log.WithName("kibanasavedobjects-resource")
+
+// SetupWebhookWithManager registers the validating webhook of KibanaSavedObjects
+func (r *KibanaSavedObjects) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-kibanasavedobjects,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=kibanasavedobjects,verbs=create;update,versions=v1,name=vkibanasavedobjects.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &KibanaSavedObjects{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSavedObjects) ValidateCreate() (admission.Warnings, error) {
+	kibanasavedobjectslog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSavedObjects) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	kibanasavedobjectslog.Info("validate update", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSavedObjects) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the target space and the inline objects. The content of the ConfigMaps is
+// checked by the controller
+func (r *KibanaSavedObjects) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	if r.Spec.Space != "" && !kibanaSpaceRegexp.MatchString(r.Spec.Space) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("space"), r.Spec.Space, "must contain only lowercase alphanumeric characters, '-' or '_'"))
+	}
+	if r.Spec.Objects == "" && len(r.Spec.ConfigMapRefs) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("objects"), "objects or configMapRefs is required"))
+	}
+	if err := ValidateSavedObjectsNDJSON(r.Spec.Objects); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("objects"), "", err.Error()))
+	}
+	for i, ref := range r.Spec.ConfigMapRefs {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("configMapRefs").Index(i).Child("name"), ""))
+		}
+	}
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *KibanaSavedObjects) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "KibanaSavedObjects"}, r.Name, allErrs)
+}
+
+// ValidateSavedObjectsNDJSON checks that every line of an export is a saved object with a type
+// and an id. The summary line added by Kibana at the end of its exports is accepted
+func ValidateSavedObjectsNDJSON(ndjson string) error {
+	for i, line := range strings.Split(ndjson, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			return fmt.Errorf("line %d is not a JSON object: %v", i+1, err)
+		}
+		if _, ok := object["exportedCount"]; ok {
+			continue
+		}
+		if object["type"] == nil || object["type"] == "" || object["id"] == nil || object["id"] == "" {
+			return fmt.Errorf("line %d has no type or id", i+1)
+		}
+	}
+	return nil
+}
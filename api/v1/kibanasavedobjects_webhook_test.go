@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("KibanaSavedObjects Webhook", func() {
+	var savedObjects *KibanaSavedObjects
+
+	BeforeEach(func() {
+		savedObjects = &KibanaSavedObjects{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-dashboards", Namespace: "team-a"},
+			Spec: KibanaSavedObjectsSpec{
+				StackRef: StackReference{Name: "test-efk-stack", Namespace: "logging"},
+				Space:    "team-a",
+				Objects: `{"type":"index-pattern","id":"team-a","attributes":{"title":"team-a-*"}}
+{"exportedCount":1,"missingRefCount":0,"missingReferences":[]}
+`,
+			},
+		}
+	})
+
+	It("Should accept an export of Kibana", func() {
+		_, err := savedObjects.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(savedObjects.OverwriteEnabled()).To(BeTrue())
+	})
+
+	It("Should reject invalid objects", func() {
+		savedObjects.Spec.Objects = `{"type":"dashboard","attributes":{}}`
+		_, err := savedObjects.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("line 1 has no type or id"))
+
+		savedObjects.Spec.Objects = "{\"type\":\"dashboard\",\"id\":\"a\"}\nnot json"
+		_, err = savedObjects.ValidateCreate()
+		Expect(err.Error()).To(ContainSubstring("line 2 is not a JSON object"))
+	})
+
+	It("Should require objects and a valid space", func() {
+		savedObjects.Spec.Objects = ""
+		savedObjects.Spec.Space = "Team A"
+		_, err := savedObjects.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.objects"))
+		Expect(err.Error()).To(ContainSubstring("spec.space"))
+
+		savedObjects.Spec.Space = ""
+		savedObjects.Spec.ConfigMapRefs = []corev1.LocalObjectReference{{Name: "dashboards"}}
+		_, err = savedObjects.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,166 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultKibanaTimeField is the time field of the data views created by the operator
+const DefaultKibanaTimeField = "@timestamp"
+
+// KibanaSpaceSpec defines the desired state of KibanaSpace
+type KibanaSpaceSpec struct {
+	// EFKStack dont le Kibana reçoit l'espace
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Identifiant de l'espace dans Kibana (URL /s/<id>) ; par défaut, le nom de la ressource
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// Nom affiché ; par défaut, l'identifiant de l'espace
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Description de l'espace
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Couleur de l'avatar (#RRGGBB) ; par défaut, choisie par Kibana
+	// +kubebuilder:validation:Pattern=`^#[0-9A-Fa-f]{6}$`
+	// +optional
+	Color string `json:"color,omitempty"`
+
+	// Initiales de l'avatar ; par défaut, calculées par Kibana
+	// +kubebuilder:validation:MaxLength=2
+	// +optional
+	Initials string `json:"initials,omitempty"`
+
+	// Fonctionnalités masquées dans l'espace (ml, canvas, maps, apm...)
+	// +optional
+	DisabledFeatures []string `json:"disabledFeatures,omitempty"`
+
+	// Data view créée dans l'espace et utilisée par défaut
+	// +optional
+	DefaultDataView *DataViewSpec `json:"defaultDataView,omitempty"`
+
+	// ElasticsearchRoles du namespace de la ressource qui reçoivent un accès à l'espace
+	// +optional
+	Roles []KibanaSpaceRoleBinding `json:"roles,omitempty"`
+}
+
+// DataViewSpec defines a Kibana data view
+type DataViewSpec struct {
+	// Pattern des index (ex : team-a-*)
+	// +kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+
+	// Nom affiché ; par défaut, le pattern
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Champ de date ; par défaut, @timestamp
+	// +optional
+	TimeField string `json:"timeField,omitempty"`
+}
+
+// KibanaSpaceRoleBinding grants an ElasticsearchRole access to the space
+type KibanaSpaceRoleBinding struct {
+	// Nom de l'ElasticsearchRole
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Privilège sur l'espace (all, read)
+	// +kubebuilder:validation:Enum=all;read
+	// +kubebuilder:default=read
+	// +optional
+	Privilege string `json:"privilege,omitempty"`
+}
+
+// KibanaSpaceStatus defines the observed state of KibanaSpace
+type KibanaSpaceStatus struct {
+	// Génération de la spec appliquée
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Identifiant de l'espace créé dans Kibana
+	// +optional
+	SpaceID string `json:"spaceId,omitempty"`
+
+	// Identifiant de la data view par défaut
+	// +optional
+	DataViewID string `json:"dataViewId,omitempty"`
+
+	// Conditions (Ready)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// KibanaSpaceID returns the ID of the space in Kibana
+func (r *KibanaSpace) KibanaSpaceID() string {
+	if r.Spec.SpaceID != "" {
+		return r.Spec.SpaceID
+	}
+	return r.Name
+}
+
+// DefaultDataViewID returns the ID of the default data view. The IDs of data views are unique
+// across spaces, so it is derived from the ID of the space
+func (r *KibanaSpace) DefaultDataViewID() string {
+	return r.KibanaSpaceID() + "-default"
+}
+
+// PrivilegeOrDefault returns the privilege granted by the binding, read by default
+func (b KibanaSpaceRoleBinding) PrivilegeOrDefault() string {
+	if b.Privilege != "" {
+		return b.Privilege
+	}
+	return "read"
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=kspace
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Space",type="string",JSONPath=".status.spaceId"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// KibanaSpace is the Schema for the kibanaspaces API. It declares a space in the Kibana of an
+// EFKStack, deleted with the resource
+type KibanaSpace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KibanaSpaceSpec   `json:"spec,omitempty"`
+	Status KibanaSpaceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// KibanaSpaceList contains a list of KibanaSpace
+type KibanaSpaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KibanaSpace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KibanaSpace{}, &KibanaSpaceList{})
+}
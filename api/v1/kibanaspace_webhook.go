@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var kibanaspacelog = logf.Log.WithName("kibanaspace-resource")
+
+// SetupWebhookWithManager registers the validating webhook of KibanaSpace
+func (r *KibanaSpace) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-kibanaspace,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=kibanaspaces,verbs=create;update,versions=v1,name=vkibanaspace.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &KibanaSpace{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSpace) ValidateCreate() (admission.Warnings, error) {
+	kibanaspacelog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSpace) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	kibanaspacelog.Info("validate update", "name", r.Name)
+
+	oldSpace, ok := old.(*KibanaSpace)
+	if !ok {
+		return nil, fmt.Errorf("expected a KibanaSpace but got a %T", old)
+	}
+
+	allErrs := r.Validate()
+	// L'espace précédent resterait dans Kibana sans ressource pour le supprimer
+	specPath := field.NewPath("spec")
+	if r.Spec.StackRef.NamespaceOr(r.Namespace) != oldSpace.Spec.StackRef.NamespaceOr(oldSpace.Namespace) || r.Spec.StackRef.Name != oldSpace.Spec.StackRef.Name {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("stackRef"), "is immutable"))
+	}
+	if r.KibanaSpaceID() != oldSpace.KibanaSpaceID() {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("spaceId"), "is immutable"))
+	}
+	return nil, r.toInvalidError(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KibanaSpace) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the identifier, the default data view and the role bindings of the space
+func (r *KibanaSpace) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	if id := r.KibanaSpaceID(); !kibanaSpaceRegexp.MatchString(id) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("spaceId"), id, "must contain only lowercase alphanumeric characters, '-' or '_'"))
+	}
+	for i, feature := range r.Spec.DisabledFeatures {
+		if feature == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("disabledFeatures").Index(i), ""))
+		}
+	}
+	if r.Spec.DefaultDataView != nil && r.Spec.DefaultDataView.Pattern == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("defaultDataView", "pattern"), ""))
+	}
+
+	bound := map[string]bool{}
+	for i, binding := range r.Spec.Roles {
+		p := specPath.Child("roles").Index(i)
+		switch {
+		case binding.Name == "":
+			allErrs = append(allErrs, field.Required(p.Child("name"), ""))
+		case bound[binding.Name]:
+			allErrs = append(allErrs, field.Duplicate(p.Child("name"), binding.Name))
+		}
+		bound[binding.Name] = true
+	}
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *KibanaSpace) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "KibanaSpace"}, r.Name, allErrs)
+}
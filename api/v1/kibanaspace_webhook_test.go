@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("KibanaSpace Webhook", func() {
+	var space *KibanaSpace
+
+	BeforeEach(func() {
+		space = &KibanaSpace{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+			Spec: KibanaSpaceSpec{
+				StackRef:         StackReference{Name: "test-efk-stack", Namespace: "logging"},
+				DisplayName:      "Team A",
+				DisabledFeatures: []string{"ml", "canvas"},
+				DefaultDataView:  &DataViewSpec{Pattern: "team-a-*"},
+				Roles:            []KibanaSpaceRoleBinding{{Name: "team-a-read"}, {Name: "team-a-admin", Privilege: "all"}},
+			},
+		}
+	})
+
+	It("Should accept a valid space", func() {
+		_, err := space.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(space.KibanaSpaceID()).To(Equal("team-a"))
+		Expect(space.DefaultDataViewID()).To(Equal("team-a-default"))
+		Expect(space.Spec.Roles[0].PrivilegeOrDefault()).To(Equal("read"))
+	})
+
+	It("Should reject an invalid identifier and duplicate bindings", func() {
+		space.Spec.SpaceID = "Team A"
+		space.Spec.Roles = append(space.Spec.Roles, KibanaSpaceRoleBinding{Name: "team-a-read", Privilege: "all"})
+		_, err := space.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.spaceId"))
+		Expect(err.Error()).To(ContainSubstring("spec.roles[2].name"))
+	})
+
+	It("Should forbid changing the identifier of the space", func() {
+		updated := space.DeepCopy()
+		updated.Spec.SpaceID = "team-b"
+		_, err := updated.ValidateUpdate(space)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.spaceId: Forbidden"))
+
+		updated = space.DeepCopy()
+		updated.Spec.DisabledFeatures = nil
+		_, err = updated.ValidateUpdate(space)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
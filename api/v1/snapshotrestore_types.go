@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phases of a SnapshotRestore
+const (
+	RestorePhasePending   = "Pending"
+	RestorePhaseRunning   = "Running"
+	RestorePhaseCompleted = "Completed"
+	RestorePhaseFailed    = "Failed"
+)
+
+// SnapshotRestoreSpec defines the desired state of SnapshotRestore
+type SnapshotRestoreSpec struct {
+	// EFKStack dont l'Elasticsearch est restauré
+	// +kubebuilder:validation:Required
+	StackRef StackReference `json:"stackRef"`
+
+	// Dépôt contenant le snapshot ; par défaut, celui déclaré dans la spec snapshot de la stack
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Nom du snapshot à restaurer
+	// +kubebuilder:validation:Required
+	Snapshot string `json:"snapshot"`
+
+	// Index à restaurer (wildcards et exclusions "-" acceptés) ; par défaut, tous
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// Expression régulière appliquée au nom des index restaurés
+	// +optional
+	RenamePattern string `json:"renamePattern,omitempty"`
+
+	// Nom de remplacement, avec les groupes de renamePattern ($1, $2...)
+	// +optional
+	RenameReplacement string `json:"renameReplacement,omitempty"`
+
+	// Restaurer les index dont certains shards sont absents du snapshot
+	// +optional
+	Partial bool `json:"partial,omitempty"`
+
+	// Restaurer l'état global du cluster (templates, politiques...)
+	// +optional
+	IncludeGlobalState bool `json:"includeGlobalState,omitempty"`
+}
+
+// SnapshotRestoreStatus defines the observed state of SnapshotRestore
+type SnapshotRestoreStatus struct {
+	// Phase (Pending, Running, Completed, Failed)
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Dépôt utilisé
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Index créés par la restauration (après renommage)
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// Progression des shards primaires restaurés
+	// +optional
+	Shards RestoreShardsStatus `json:"shards,omitempty"`
+
+	// Début de la restauration
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Fin de la restauration
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message d'erreur ou d'information
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreShardsStatus counts the primary shards of a restore
+type RestoreShardsStatus struct {
+	// Nombre total de shards primaires à restaurer
+	Total int32 `json:"total"`
+
+	// Shards restaurés
+	Successful int32 `json:"successful"`
+
+	// Shards dont la restauration a échoué
+	Failed int32 `json:"failed"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=ssr
+//+kubebuilder:printcolumn:name="Stack",type="string",JSONPath=".spec.stackRef.name"
+//+kubebuilder:printcolumn:name="Snapshot",type="string",JSONPath=".spec.snapshot"
+//+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+//+kubebuilder:printcolumn:name="Shards",type="integer",priority=1,JSONPath=".status.shards.successful"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SnapshotRestore is the Schema for the snapshotrestores API. It restores indices of a
+// snapshot into the Elasticsearch cluster of an EFKStack, once
+type SnapshotRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SnapshotRestoreSpec   `json:"spec,omitempty"`
+	Status SnapshotRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SnapshotRestoreList contains a list of SnapshotRestore
+type SnapshotRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SnapshotRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SnapshotRestore{}, &SnapshotRestoreList{})
+}
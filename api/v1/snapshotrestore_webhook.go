@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var snapshotrestorelog = logf.Log.WithName("snapshotrestore-resource")
+
+// SetupWebhookWithManager registers the validating webhook of SnapshotRestore
+func (r *SnapshotRestore) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-logging-efk-crds-io-v1-snapshotrestore,mutating=false,failurePolicy=fail,sideEffects=None,groups=logging.efk.crds.io,resources=snapshotrestores,verbs=create;update,versions=v1,name=vsnapshotrestore.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &SnapshotRestore{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *SnapshotRestore) ValidateCreate() (admission.Warnings, error) {
+	snapshotrestorelog.Info("validate create", "name", r.Name)
+
+	return nil, r.toInvalidError(r.Validate())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *SnapshotRestore) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	snapshotrestorelog.Info("validate update", "name", r.Name)
+
+	oldRestore, ok := old.(*SnapshotRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a SnapshotRestore but got a %T", old)
+	}
+
+	// Une restauration est exécutée une seule fois : la modifier n'aurait aucun effet
+	if !equality.Semantic.DeepEqual(r.Spec, oldRestore.Spec) {
+		return nil, r.toInvalidError(field.ErrorList{
+			field.Forbidden(field.NewPath("spec"), "is immutable, create a new SnapshotRestore instead"),
+		})
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *SnapshotRestore) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the references and the rename options of the restore
+func (r *SnapshotRestore) Validate() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.StackRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("stackRef", "name"), ""))
+	}
+	if r.Spec.Snapshot == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("snapshot"), ""))
+	}
+	if r.Spec.RenamePattern != "" {
+		if _, err := regexp.Compile(r.Spec.RenamePattern); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("renamePattern"), r.Spec.RenamePattern, err.Error()))
+		}
+		if r.Spec.RenameReplacement == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("renameReplacement"), "is required with renamePattern"))
+		}
+	} else if r.Spec.RenameReplacement != "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("renamePattern"), "is required with renameReplacement"))
+	}
+
+	return allErrs
+}
+
+// toInvalidError wraps field errors into an Invalid API error
+func (r *SnapshotRestore) toInvalidError(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "SnapshotRestore"}, r.Name, allErrs)
+}
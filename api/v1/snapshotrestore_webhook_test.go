@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("SnapshotRestore Webhook", func() {
+	var restore *SnapshotRestore
+
+	BeforeEach(func() {
+		restore = &SnapshotRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "logging"},
+			Spec: SnapshotRestoreSpec{
+				StackRef:          StackReference{Name: "test-efk-stack"},
+				Snapshot:          "nightly-2025.06.01",
+				Indices:           []string{"fluent-bit-*"},
+				RenamePattern:     "(.+)",
+				RenameReplacement: "restored-$1",
+			},
+		}
+	})
+
+	It("Should accept a valid restore", func() {
+		_, err := restore.ValidateCreate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject an invalid rename pattern", func() {
+		restore.Spec.RenamePattern = "(.+"
+		_, err := restore.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.renamePattern"))
+	})
+
+	It("Should require the rename pattern and replacement together", func() {
+		restore.Spec.RenameReplacement = ""
+		_, err := restore.ValidateCreate()
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.renameReplacement"))
+	})
+
+	It("Should reject any change of the spec", func() {
+		updated := restore.DeepCopy()
+		updated.Spec.Snapshot = "nightly-2025.06.02"
+		_, err := updated.ValidateUpdate(restore)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("immutable"))
+
+		updated = restore.DeepCopy()
+		updated.Labels = map[string]string{"team": "a"}
+		_, err = updated.ValidateUpdate(restore)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,29 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package charts embeds the Helm charts deployed by the operator so that the
+// binary does not depend on its working directory to find them
+package charts
+
+import "embed"
+
+// Root is the directory of the component charts inside FS
+const Root = "efk-stack"
+
+// FS contains the efk-stack charts (elasticsearch, fluentbit, kibana)
+//
+//go:embed all:efk-stack
+var FS embed.FS
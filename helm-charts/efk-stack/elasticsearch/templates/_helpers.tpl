@@ -21,6 +21,37 @@ Create a default fully qualified app name.
 {{- end }}
 {{- end }}
 
+{{/*
+Name of the StatefulSet of a node set: the fullname, suffixed with the name of the set if any.
+Takes a dict with the root context ("root") and the node set ("nodeSet")
+*/}}
+{{- define "elasticsearch.nodeSetFullname" -}}
+{{- include "elasticsearch.fullname" .root }}{{ with .nodeSet.name }}-{{ . }}{{ end }}
+{{- end }}
+
+{{/*
+Comma-separated names of the master-eligible nodes bootstrapping the cluster
+*/}}
+{{- define "elasticsearch.initialMasterNodes" -}}
+{{- $root := . }}
+{{- $nodes := list }}
+{{- if .Values.nodeSets }}
+{{- range $nodeSet := .Values.nodeSets }}
+{{- if or (not $nodeSet.roles) (has "master" $nodeSet.roles) }}
+{{- $name := include "elasticsearch.nodeSetFullname" (dict "root" $root "nodeSet" $nodeSet) }}
+{{- range $i := until (int $nodeSet.replicas) }}
+{{- $nodes = append $nodes (printf "%s-%d" $name $i) }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- else }}
+{{- range $i := until (int .Values.replicas) }}
+{{- $nodes = append $nodes (printf "%s-%d" (include "elasticsearch.fullname" $root) $i) }}
+{{- end }}
+{{- end }}
+{{- join "," $nodes }}
+{{- end }}
+
 {{/*
 Create chart name and version as used by the chart label.
 */}}
@@ -59,3 +90,299 @@ Create the name of the service account to use
 {{- end }}
 {{- end }}
 
+
+{{/*
+Pod annotations
+*/}}
+{{- define "elasticsearch.podAnnotations" -}}
+{{- if or .Values.podAnnotations .Values.config }}
+annotations:
+  {{- with .Values.podAnnotations }}
+  {{- toYaml . | nindent 2 }}
+  {{- end }}
+  {{- if .Values.config }}
+  efk.crds.io/config-checksum: {{ include "elasticsearch.configFile" . | sha256sum | trunc 16 | quote }}
+  {{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+elasticsearch.yml: the network setting of the image followed by .Values.config
+*/}}
+{{- define "elasticsearch.configFile" -}}
+network.host: 0.0.0.0
+{{ toYaml .Values.config }}
+{{- end }}
+
+{{/*
+Additional environment variables, used for node settings such as repository clients
+*/}}
+{{- define "elasticsearch.extraEnv" -}}
+{{- with .Values.extraEnv }}
+{{- toYaml . }}
+{{- end }}
+{{- end }}
+
+{{/*
+TLS settings of the transport and HTTP layers: PEM certificates when .Values.security.certificates
+names their Secrets, the tlsSecretName PKCS#12 keystore otherwise
+*/}}
+{{- define "elasticsearch.tlsEnv" -}}
+{{- $certs := .Values.security.certificates }}
+{{- if and (eq .Values.mode "cluster") $certs.transportSecretName }}
+{{- $transport := ternary "tls" "$(node.name).tls" (default false $certs.sharedTransport) }}
+- name: xpack.security.transport.ssl.enabled
+  value: "true"
+- name: xpack.security.transport.ssl.verification_mode
+  value: "certificate"
+- name: xpack.security.transport.ssl.key
+  value: "/usr/share/elasticsearch/config/certs/transport/{{ $transport }}.key"
+- name: xpack.security.transport.ssl.certificate
+  value: "/usr/share/elasticsearch/config/certs/transport/{{ $transport }}.crt"
+- name: xpack.security.transport.ssl.certificate_authorities
+  value: "/usr/share/elasticsearch/config/certs/transport/ca.crt"
+{{- else }}
+{{- if and .Values.security.tlsEnabled .Values.security.tlsSecretName }}
+- name: xpack.security.transport.ssl.enabled
+  value: "true"
+{{- else }}
+- name: xpack.security.transport.ssl.enabled
+  value: "false"
+{{- end }}
+{{- if .Values.security.tlsSecretName }}
+- name: xpack.security.transport.ssl.keystore.path
+  value: "/usr/share/elasticsearch/config/certs/elasticsearch.p12"
+- name: xpack.security.transport.ssl.truststore.path
+  value: "/usr/share/elasticsearch/config/certs/elasticsearch.p12"
+{{- end }}
+{{- end }}
+{{- if $certs.httpSecretName }}
+- name: xpack.security.http.ssl.enabled
+  value: "true"
+- name: xpack.security.http.ssl.key
+  value: "/usr/share/elasticsearch/config/certs/http/tls.key"
+- name: xpack.security.http.ssl.certificate
+  value: "/usr/share/elasticsearch/config/certs/http/tls.crt"
+- name: xpack.security.http.ssl.certificate_authorities
+  value: "/usr/share/elasticsearch/config/certs/http/ca.crt"
+{{- end }}
+{{- end }}
+
+{{/*
+Bootstrap password of the elastic user, read from the authSecretName Secret
+*/}}
+{{- define "elasticsearch.credentialsEnv" -}}
+{{- if and .Values.security.authEnabled .Values.security.authSecretName }}
+- name: ELASTIC_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      name: {{ .Values.security.authSecretName }}
+      key: password
+{{- end }}
+{{- end }}
+
+{{/*
+Probe of the HTTP port, over HTTPS when the HTTP layer uses TLS.
+With authentication, anonymous requests are rejected with a 401: the probe runs curl and accepts
+it, so that a password rotation never fails the probes of the running nodes.
+Takes a dict with the root context ("root") and the probe ("probe")
+*/}}
+{{- define "elasticsearch.probe" -}}
+{{- $probe := deepCopy .probe }}
+{{- $https := and .root.Values.security.certificates.httpSecretName $probe.httpGet }}
+{{- if and .root.Values.security.authEnabled $probe.httpGet }}
+{{- $url := printf "%s://127.0.0.1:%v%s" (ternary "https" "http" (not (empty $https))) $probe.httpGet.port $probe.httpGet.path }}
+{{- $command := printf "code=$(curl -s -k -o /dev/null -w '%%{http_code}' %s); [ \"$code\" = 200 ] || [ \"$code\" = 401 ]" $url }}
+{{- $_ := unset $probe "httpGet" }}
+{{- $_ := set $probe "exec" (dict "command" (list "sh" "-c" $command)) }}
+{{- else if $https }}
+{{- $_ := set $probe.httpGet "scheme" "HTTPS" }}
+{{- end }}
+{{- toYaml $probe }}
+{{- end }}
+
+{{/*
+Shell function rebuilding the keystore from the files of the keystore-secrets volume. The keystore
+is written in place, so that the file mounted by subPath in the elasticsearch container keeps its inode
+*/}}
+{{- define "elasticsearch.keystoreBuild" -}}
+build_keystore() (
+  set -e
+  rm -f config/elasticsearch.keystore
+  bin/elasticsearch-keystore create
+  for file in /mnt/keystore-secrets/*; do
+    [ -f "$file" ] || continue
+    bin/elasticsearch-keystore add-file --force "$(basename "$file")" "$file"
+  done
+  cat config/elasticsearch.keystore > /mnt/keystore/elasticsearch.keystore
+)
+{{- end }}
+
+{{/*
+Volume mounts of the keystore init container and of the keystore-updater sidecar
+*/}}
+{{- define "elasticsearch.keystoreVolumeMounts" -}}
+- name: keystore-secrets
+  mountPath: /mnt/keystore-secrets
+  readOnly: true
+- name: keystore
+  mountPath: /mnt/keystore
+{{- end }}
+
+{{/*
+Init container writing the secure settings of .Values.keystore to the keystore
+*/}}
+{{- define "elasticsearch.keystoreInitContainer" -}}
+{{- if .Values.keystore }}
+initContainers:
+- name: keystore
+  image: {{ include "elasticsearch.image" . | quote }}
+  imagePullPolicy: {{ .Values.image.pullPolicy }}
+  command:
+  - bash
+  - -c
+  - |
+    {{- include "elasticsearch.keystoreBuild" . | nindent 4 }}
+    build_keystore
+  volumeMounts:
+    {{- include "elasticsearch.keystoreVolumeMounts" . | nindent 2 }}
+{{- end }}
+{{- end }}
+
+{{/*
+Sidecar rebuilding the keystore when the Secrets of .Values.keystore change. The operator then
+reloads the secure settings through the _nodes/reload_secure_settings API
+*/}}
+{{- define "elasticsearch.keystoreUpdaterContainer" -}}
+{{- if and .Values.keystore .Values.keystoreUpdater.enabled }}
+- name: keystore-updater
+  image: {{ include "elasticsearch.image" . | quote }}
+  imagePullPolicy: {{ .Values.image.pullPolicy }}
+  command:
+  - bash
+  - -c
+  - |
+    {{- include "elasticsearch.keystoreBuild" . | nindent 4 }}
+    checksum() {
+      for file in /mnt/keystore-secrets/*; do
+        [ -f "$file" ] && echo "$file" && cat "$file"
+      done | sha256sum
+    }
+    last=$(checksum)
+    while true; do
+      sleep {{ .Values.keystoreUpdater.intervalSeconds }}
+      current=$(checksum)
+      if [ "$current" != "$last" ] && build_keystore; then
+        echo "Keystore rebuilt"
+        last=$current
+      fi
+    done
+  resources:
+    {{- toYaml .Values.keystoreUpdater.resources | nindent 4 }}
+  volumeMounts:
+    {{- include "elasticsearch.keystoreVolumeMounts" . | nindent 2 }}
+{{- end }}
+{{- end }}
+
+{{/*
+Volume mounts of elasticsearch.yml, the certificates, the keystore and the snapshot repository
+*/}}
+{{- define "elasticsearch.extraVolumeMounts" -}}
+{{- $certs := .Values.security.certificates }}
+{{- if .Values.config }}
+- name: config
+  mountPath: /usr/share/elasticsearch/config/elasticsearch.yml
+  subPath: elasticsearch.yml
+  readOnly: true
+{{- end }}
+{{- if .Values.security.tlsSecretName }}
+- name: certs
+  mountPath: /usr/share/elasticsearch/config/certs
+  readOnly: true
+{{- end }}
+{{- if and (eq .Values.mode "cluster") $certs.transportSecretName }}
+- name: transport-certs
+  mountPath: /usr/share/elasticsearch/config/certs/transport
+  readOnly: true
+{{- end }}
+{{- if $certs.httpSecretName }}
+- name: http-certs
+  mountPath: /usr/share/elasticsearch/config/certs/http
+  readOnly: true
+{{- end }}
+{{- if .Values.keystore }}
+- name: keystore
+  mountPath: /usr/share/elasticsearch/config/elasticsearch.keystore
+  subPath: elasticsearch.keystore
+{{- end }}
+{{- if .Values.snapshotVolume.claimName }}
+- name: snapshots
+  mountPath: {{ .Values.snapshotVolume.mountPath }}
+{{- end }}
+{{- end }}
+
+{{/*
+Volumes of elasticsearch.yml, the certificates, the keystore and the snapshot repository
+*/}}
+{{- define "elasticsearch.extraVolumes" -}}
+{{- $certs := .Values.security.certificates }}
+{{- if .Values.config }}
+- name: config
+  configMap:
+    name: {{ include "elasticsearch.fullname" . }}-config
+{{- end }}
+{{- if .Values.security.tlsSecretName }}
+- name: certs
+  secret:
+    secretName: {{ .Values.security.tlsSecretName }}
+{{- end }}
+{{- if and (eq .Values.mode "cluster") $certs.transportSecretName }}
+- name: transport-certs
+  secret:
+    secretName: {{ $certs.transportSecretName }}
+{{- end }}
+{{- if $certs.httpSecretName }}
+- name: http-certs
+  secret:
+    secretName: {{ $certs.httpSecretName }}
+{{- end }}
+{{- if .Values.keystore }}
+- name: keystore-secrets
+  projected:
+    sources:
+    {{- range .Values.keystore }}
+    {{- /* A missing Secret or key is skipped until it is created */}}
+    - secret:
+        name: {{ .secretName }}
+        optional: true
+        {{- if .key }}
+        items:
+        - key: {{ .key | quote }}
+          path: {{ .setting | quote }}
+        {{- end }}
+    {{- end }}
+- name: keystore
+  emptyDir: {}
+{{- end }}
+{{- if .Values.snapshotVolume.claimName }}
+- name: snapshots
+  persistentVolumeClaim:
+    claimName: {{ .Values.snapshotVolume.claimName }}
+{{- end }}
+{{- end }}
+
+{{/*
+Image of the containers: registry/repository pinned by image.digest when set, otherwise tagged
+with image.tag or the version. An empty registry leaves the repository as is
+*/}}
+{{- define "elasticsearch.image" -}}
+{{- $repository := .Values.image.repository }}
+{{- if .Values.image.registry }}
+{{- $repository = printf "%s/%s" .Values.image.registry .Values.image.repository }}
+{{- end }}
+{{- if .Values.image.digest }}
+{{- printf "%s@%s" $repository .Values.image.digest }}
+{{- else }}
+{{- printf "%s:%s" $repository (default .Values.version .Values.image.tag) }}
+{{- end }}
+{{- end }}
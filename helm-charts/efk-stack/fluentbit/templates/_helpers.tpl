@@ -41,3 +41,18 @@ app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
 {{- end }}
 
+{{/*
+Image of the containers: registry/repository pinned by image.digest when set, otherwise tagged
+with image.tag or the version. An empty registry leaves the repository as is
+*/}}
+{{- define "fluentbit.image" -}}
+{{- $repository := .Values.image.repository }}
+{{- if .Values.image.registry }}
+{{- $repository = printf "%s/%s" .Values.image.registry .Values.image.repository }}
+{{- end }}
+{{- if .Values.image.digest }}
+{{- printf "%s@%s" $repository .Values.image.digest }}
+{{- else }}
+{{- printf "%s:%s" $repository (default .Values.version .Values.image.tag) }}
+{{- end }}
+{{- end }}
@@ -41,3 +41,30 @@ app.kubernetes.io/instance: {{ .Release.Name }}
 {{- end }}
 {{- end }}
 
+{{/*
+Probe of the HTTP port, over HTTPS when Kibana serves TLS.
+Takes a dict with the root context ("root") and the probe ("probe")
+*/}}
+{{- define "kibana.probe" -}}
+{{- $probe := deepCopy .probe }}
+{{- if and .root.Values.tls.secretName $probe.httpGet }}
+{{- $_ := set $probe.httpGet "scheme" "HTTPS" }}
+{{- end }}
+{{- toYaml $probe }}
+{{- end }}
+
+{{/*
+Image of the containers: registry/repository pinned by image.digest when set, otherwise tagged
+with image.tag or the version. An empty registry leaves the repository as is
+*/}}
+{{- define "kibana.image" -}}
+{{- $repository := .Values.image.repository }}
+{{- if .Values.image.registry }}
+{{- $repository = printf "%s/%s" .Values.image.registry .Values.image.repository }}
+{{- end }}
+{{- if .Values.image.digest }}
+{{- printf "%s@%s" $repository .Values.image.digest }}
+{{- else }}
+{{- printf "%s:%s" $repository (default .Values.version .Values.image.tag) }}
+{{- end }}
+{{- end }}
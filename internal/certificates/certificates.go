@@ -0,0 +1,257 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certificates issues the self-signed CA and the PEM certificates securing the
+// HTTP and transport layers of Elasticsearch
+package certificates
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// Keys of the certificate Secrets, following the kubernetes.io/tls layout
+const (
+	CertificateKey = "tls.crt"
+	PrivateKeyKey  = "tls.key"
+	CAKey          = "ca.crt"
+)
+
+// clockSkew backdates the certificates so that nodes with a late clock accept them
+const clockSkew = 5 * time.Minute
+
+// CA is a certificate authority signing the certificates of a stack
+type CA struct {
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+}
+
+// Certificate is a PEM-encoded certificate and its private key
+type Certificate struct {
+	CertificatePEM []byte
+	PrivateKeyPEM  []byte
+}
+
+// NewCA creates a self-signed CA valid for the given duration
+func NewCA(commonName string, validity time.Duration) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	return &CA{Certificate: cert, PrivateKey: key}, nil
+}
+
+// ParseCA loads a CA from its PEM certificate and private key
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	certs, err := ParseCertificates(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported CA private key type %T", key)
+	}
+	return &CA{Certificate: certs[0], PrivateKey: signer}, nil
+}
+
+// Encode returns the PEM certificate and private key of the CA
+func (ca *CA) Encode() (*Certificate, error) {
+	keyPEM, err := encodePrivateKey(ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{CertificatePEM: encodeCertificate(ca.Certificate.Raw), PrivateKeyPEM: keyPEM}, nil
+}
+
+// Issue creates a certificate signed by the CA for the given names, usable by both TLS
+// servers and clients as Elasticsearch nodes are both on the transport layer. The
+// certificate never outlives the CA
+func (ca *CA) Issue(commonName string, dnsNames []string, ipAddresses []net.IP, validity time.Duration) (*Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	notAfter := now.Add(validity)
+	if notAfter.After(ca.Certificate.NotAfter) {
+		notAfter = ca.Certificate.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		IPAddresses:  ipAddresses,
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, key.Public(), ca.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate for %s: %w", commonName, err)
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{CertificatePEM: encodeCertificate(der), PrivateKeyPEM: keyPEM}, nil
+}
+
+// Valid returns true if the PEM certificate is signed by the CA, lists every given DNS name,
+// wildcards included, and does not expire within renewBefore
+func (ca *CA) Valid(certPEM []byte, dnsNames []string, renewBefore time.Duration) bool {
+	certs, err := ParseCertificates(certPEM)
+	if err != nil {
+		return false
+	}
+	cert := certs[0]
+	if cert.CheckSignatureFrom(ca.Certificate) != nil {
+		return false
+	}
+	if time.Until(cert.NotAfter) < renewBefore {
+		return false
+	}
+	listed := make(map[string]bool, len(cert.DNSNames))
+	for _, name := range cert.DNSNames {
+		listed[name] = true
+	}
+	for _, name := range dnsNames {
+		if !listed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCertificates returns the certificates of a PEM bundle
+func ParseCertificates(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+// NotAfter returns the expiry date of the first certificate of a PEM bundle
+func NotAfter(certPEM []byte) (time.Time, error) {
+	certs, err := ParseCertificates(certPEM)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return certs[0].NotAfter, nil
+}
+
+// Bundle returns the PEM bundle of the CA followed by the unexpired certificates of the
+// previous bundle, so that certificates signed by a rotated CA stay trusted until they expire
+func (ca *CA) Bundle(previous []byte) []byte {
+	var bundle bytes.Buffer
+	bundle.Write(encodeCertificate(ca.Certificate.Raw))
+	certs, err := ParseCertificates(previous)
+	if err != nil {
+		return bundle.Bytes()
+	}
+	now := time.Now()
+	for _, cert := range certs {
+		if cert.Equal(ca.Certificate) || now.After(cert.NotAfter) {
+			continue
+		}
+		bundle.Write(encodeCertificate(cert.Raw))
+	}
+	return bundle.Bytes()
+}
+
+// serialNumber returns a random 128-bit serial number
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// encodeCertificate returns a DER certificate in PEM form
+func encodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// encodePrivateKey returns a private key in PKCS#8 PEM form, read by Elasticsearch and OpenSSL
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCertificates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Certificates Suite")
+}
+
+var _ = Describe("Certificates", func() {
+	const day = 24 * time.Hour
+
+	It("Should issue certificates verified by the CA", func() {
+		ca, err := NewCA("test-efk-elasticsearch-ca", 365*day)
+		Expect(err).NotTo(HaveOccurred())
+
+		cert, err := ca.Issue("test-efk-elasticsearch", []string{"test-efk-elasticsearch.logging.svc"}, nil, 30*day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ca.Valid(cert.CertificatePEM, []string{"test-efk-elasticsearch.logging.svc"}, day)).To(BeTrue())
+		Expect(ca.Valid(cert.CertificatePEM, []string{"other.logging.svc"}, day)).To(BeFalse())
+		// A certificate within its renewal window must be reissued
+		Expect(ca.Valid(cert.CertificatePEM, nil, 31*day)).To(BeFalse())
+
+		certs, err := ParseCertificates(cert.CertificatePEM)
+		Expect(err).NotTo(HaveOccurred())
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.Certificate)
+		_, err = certs[0].Verify(x509.VerifyOptions{Roots: pool, DNSName: "test-efk-elasticsearch.logging.svc"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should not issue certificates outliving the CA", func() {
+		ca, err := NewCA("test-efk-elasticsearch-ca", 10*day)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := ca.Issue("node", nil, nil, 365*day)
+		Expect(err).NotTo(HaveOccurred())
+		notAfter, err := NotAfter(cert.CertificatePEM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notAfter).To(BeTemporally("==", ca.Certificate.NotAfter))
+	})
+
+	It("Should reload an encoded CA and keep trusting the previous one", func() {
+		previous, err := NewCA("previous", 365*day)
+		Expect(err).NotTo(HaveOccurred())
+		encoded, err := previous.Encode()
+		Expect(err).NotTo(HaveOccurred())
+		reloaded, err := ParseCA(encoded.CertificatePEM, encoded.PrivateKeyPEM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded.Certificate.Equal(previous.Certificate)).To(BeTrue())
+		cert, err := reloaded.Issue("node", nil, nil, day)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(previous.Valid(cert.CertificatePEM, nil, 0)).To(BeTrue())
+
+		current, err := NewCA("current", 365*day)
+		Expect(err).NotTo(HaveOccurred())
+		bundle, err := ParseCertificates(current.Bundle(current.Bundle(encoded.CertificatePEM)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle).To(HaveLen(2))
+		Expect(bundle[0].Subject.CommonName).To(Equal("current"))
+		Expect(bundle[1].Subject.CommonName).To(Equal("previous"))
+	})
+})
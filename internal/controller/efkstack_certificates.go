@@ -0,0 +1,446 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+)
+
+const (
+	// caValidity is the lifetime of the CA generated for a stack
+	caValidity = 3 * 365 * 24 * time.Hour
+	// certificateValidity is the lifetime of the HTTP and transport certificates
+	certificateValidity = 365 * 24 * time.Hour
+	// certificateRenewBefore is how long before their expiry the certificates are reissued
+	certificateRenewBefore = 30 * 24 * time.Hour
+
+	// stackLabel marks the resources created by the operator for a stack, outside of the Helm releases
+	stackLabel = "efk.crds.io/stack"
+
+	// fluentBitCAFile is the CA bundle mounted by the Fluent Bit chart
+	fluentBitCAFile = "/fluent-bit/tls/" + certificates.CAKey
+
+	// loopbackIP lets the nodes call their own HTTP endpoint over TLS
+	loopbackIP = "127.0.0.1"
+)
+
+// managedCertificates returns true if the operator generates the TLS certificates of the stack:
+// TLS is enabled without a user-provided keystore. TLS being part of the Elasticsearch security
+// features, the certificates are only used when security is enabled
+func managedCertificates(efkStack *loggingv1.EFKStack) bool {
+	security := efkStack.Spec.Elasticsearch.Security
+	return security.TLSEnabled && security.AuthEnabled && security.TLSSecretName == ""
+}
+
+// kibanaCertificateSecretName returns the name of the Secret holding the certificate of Kibana
+func kibanaCertificateSecretName(efkStack *loggingv1.EFKStack) string {
+	return fmt.Sprintf("%s-kibana-certs", efkStack.Name)
+}
+
+// certificateSecretNames returns the names of the Secrets holding the CA with its private key,
+// the public CA bundle, the HTTP certificate and the transport certificates of a stack
+func certificateSecretNames(efkStack *loggingv1.EFKStack) (caInternal, ca, http, transport string) {
+	prefix := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
+	return prefix + "-ca-internal", prefix + "-ca", prefix + "-http-certs", prefix + "-transport-certs"
+}
+
+// reconcileCertificates provides the certificates of the HTTP and transport layers of Elasticsearch
+// and reports their expiry in status.elasticsearch.certificates and in the CertificatesReady
+// condition. Without a cert-manager issuer, the operator signs them with its own CA and reissues
+// them before they expire. A rotated CA stays in the trusted bundle until it expires, so that
+// nodes restarted at different times keep talking
+func (r *EFKStackReconciler) reconcileCertificates(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) error {
+	esStatus := &efkStack.Status.Elasticsearch
+	if !managedCertificates(efkStack) {
+		esStatus.Certificates = nil
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionCertificatesReady)
+		return r.deleteCertManagerCertificates(ctx, efkStack, namespace)
+	}
+
+	var status *loggingv1.CertificatesStatus
+	var err error
+	issuer := efkStack.Spec.CertificateIssuer()
+	if issuer != nil {
+		status, err = r.requestCertificates(ctx, efkStack, namespace, issuer)
+	} else if err = r.deleteCertManagerCertificates(ctx, efkStack, namespace); err == nil {
+		status, err = r.issueCertificates(ctx, efkStack, namespace)
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionCertificatesReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             "Issued",
+	}
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Failed"
+		condition.Message = err.Error()
+	case status == nil:
+		// Les pods attendent les Secrets de cert-manager pour démarrer
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Issuing"
+		condition.Message = fmt.Sprintf("Waiting for %s %s to issue the certificates", issuer.Kind, issuer.Name)
+	default:
+		esStatus.Certificates = status
+		condition.Message = fmt.Sprintf("Certificates valid until %s", earliest(status.HTTPNotAfter, status.TransportNotAfter).Format(time.RFC3339))
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+	return err
+}
+
+// issueCertificates writes the certificate Secrets of a stack and returns their expiry
+func (r *EFKStackReconciler) issueCertificates(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) (*loggingv1.CertificatesStatus, error) {
+	caInternalName, caName, httpName, transportName := certificateSecretNames(efkStack)
+	service := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
+	headless := fmt.Sprintf("%s-headless", service)
+
+	// CA : renouvelée tant qu'elle peut encore signer un certificat de validité complète
+	caInternal, err := r.getSecret(ctx, caInternalName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var ca *certificates.CA
+	if caInternal != nil {
+		ca, err = certificates.ParseCA(caInternal.Data[certificates.CertificateKey], caInternal.Data[certificates.PrivateKeyKey])
+		if err != nil {
+			log.FromContext(ctx).Info("Regenerating unreadable CA", "secret", caInternalName, "error", err)
+		}
+	}
+	if ca == nil || time.Until(ca.Certificate.NotAfter) < certificateValidity+certificateRenewBefore {
+		if ca, err = certificates.NewCA(fmt.Sprintf("%s-ca", service), caValidity); err != nil {
+			return nil, err
+		}
+		encoded, err := ca.Encode()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.applyStackSecret(ctx, efkStack, caInternalName, namespace, map[string][]byte{
+			certificates.CertificateKey: encoded.CertificatePEM,
+			certificates.PrivateKeyKey:  encoded.PrivateKeyPEM,
+		}); err != nil {
+			return nil, err
+		}
+		log.FromContext(ctx).Info("Generated Elasticsearch CA", "secret", caInternalName, "notAfter", ca.Certificate.NotAfter)
+	}
+
+	// Bundle public : la CA courante suivie des CAs précédentes non expirées
+	caSecret, err := r.getSecret(ctx, caName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var previousBundle []byte
+	if caSecret != nil {
+		previousBundle = caSecret.Data[certificates.CAKey]
+	}
+	bundle := ca.Bundle(previousBundle)
+	if err := r.applyStackSecret(ctx, efkStack, caName, namespace, map[string][]byte{certificates.CAKey: bundle}); err != nil {
+		return nil, err
+	}
+	status := &loggingv1.CertificatesStatus{
+		CASecretName: caName,
+		CANotAfter:   &metav1.Time{Time: ca.Certificate.NotAfter},
+	}
+
+	// Certificat HTTP partagé par les nœuds, valide pour les services et les pods
+	httpNames := elasticsearchHTTPNames(efkStack, namespace)
+	httpSecret, err := r.getSecret(ctx, httpName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	httpData := map[string][]byte{}
+	if httpSecret != nil && ca.Valid(httpSecret.Data[certificates.CertificateKey], httpNames, certificateRenewBefore) {
+		httpData[certificates.CertificateKey] = httpSecret.Data[certificates.CertificateKey]
+		httpData[certificates.PrivateKeyKey] = httpSecret.Data[certificates.PrivateKeyKey]
+	} else {
+		cert, err := ca.Issue(service, httpNames, []net.IP{net.ParseIP(loopbackIP)}, certificateValidity)
+		if err != nil {
+			return nil, err
+		}
+		httpData[certificates.CertificateKey] = cert.CertificatePEM
+		httpData[certificates.PrivateKeyKey] = cert.PrivateKeyPEM
+		log.FromContext(ctx).Info("Issued Elasticsearch HTTP certificate", "secret", httpName)
+	}
+	httpData[certificates.CAKey] = bundle
+	if err := r.applyStackSecret(ctx, efkStack, httpName, namespace, httpData); err != nil {
+		return nil, err
+	}
+	if status.HTTPNotAfter, err = notAfter(httpData[certificates.CertificateKey]); err != nil {
+		return nil, err
+	}
+
+	// Kibana sert HTTPS avec spec.global.tls.enabled
+	if efkStack.Spec.Global.TLS.Enabled {
+		if err := r.issueKibanaCertificate(ctx, efkStack, namespace, ca, bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	// Un node singleton n'ouvre pas la couche transport aux autres nœuds
+	if efkStack.Spec.Elasticsearch.Mode == "singleton" {
+		return status, nil
+	}
+
+	// Certificats transport : un par pod, le pod lit le sien via $(node.name)
+	pods, err := r.elasticsearchPodNames(ctx, efkStack, namespace)
+	if err != nil {
+		return nil, err
+	}
+	transportSecret, err := r.getSecret(ctx, transportName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	transportData := map[string][]byte{certificates.CAKey: bundle}
+	issued := 0
+	for _, pod := range pods {
+		certKey, keyKey := pod+"."+certificates.CertificateKey, pod+"."+certificates.PrivateKeyKey
+		names := []string{
+			pod,
+			fmt.Sprintf("%s.%s.%s.svc", pod, headless, namespace),
+			fmt.Sprintf("%s.%s.%s.svc.cluster.local", pod, headless, namespace),
+		}
+		if transportSecret != nil && ca.Valid(transportSecret.Data[certKey], names, certificateRenewBefore) {
+			transportData[certKey] = transportSecret.Data[certKey]
+			transportData[keyKey] = transportSecret.Data[keyKey]
+		} else {
+			cert, err := ca.Issue(pod, names, nil, certificateValidity)
+			if err != nil {
+				return nil, err
+			}
+			transportData[certKey] = cert.CertificatePEM
+			transportData[keyKey] = cert.PrivateKeyPEM
+			issued++
+		}
+		expiry, err := notAfter(transportData[certKey])
+		if err != nil {
+			return nil, err
+		}
+		if status.TransportNotAfter == nil || expiry.Before(status.TransportNotAfter) {
+			status.TransportNotAfter = expiry
+		}
+	}
+	if err := r.applyStackSecret(ctx, efkStack, transportName, namespace, transportData); err != nil {
+		return nil, err
+	}
+	if issued > 0 {
+		log.FromContext(ctx).Info("Issued Elasticsearch transport certificates", "secret", transportName, "count", issued)
+	}
+
+	return status, nil
+}
+
+// issueKibanaCertificate writes the certificate served by Kibana, signed by the CA of the stack,
+// with the CA bundle verifying it
+func (r *EFKStackReconciler) issueKibanaCertificate(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, ca *certificates.CA, bundle []byte) error {
+	service := fmt.Sprintf("%s-kibana", efkStack.Name)
+	name := kibanaCertificateSecretName(efkStack)
+	names := serviceNames(service, namespace)
+	secret, err := r.getSecret(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+	data := map[string][]byte{}
+	if secret != nil && ca.Valid(secret.Data[certificates.CertificateKey], names, certificateRenewBefore) {
+		data[certificates.CertificateKey] = secret.Data[certificates.CertificateKey]
+		data[certificates.PrivateKeyKey] = secret.Data[certificates.PrivateKeyKey]
+	} else {
+		cert, err := ca.Issue(service, names, nil, certificateValidity)
+		if err != nil {
+			return err
+		}
+		data[certificates.CertificateKey] = cert.CertificatePEM
+		data[certificates.PrivateKeyKey] = cert.PrivateKeyPEM
+		log.FromContext(ctx).Info("Issued Kibana certificate", "secret", name)
+	}
+	data[certificates.CAKey] = bundle
+	return r.applyStackSecret(ctx, efkStack, name, namespace, data)
+}
+
+// elasticsearchHTTPNames returns the DNS names of the HTTP certificate: the services of the stack
+// and the pods behind the headless service
+func elasticsearchHTTPNames(efkStack *loggingv1.EFKStack, namespace string) []string {
+	service := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
+	headless := fmt.Sprintf("%s-headless", service)
+	names := append(serviceNames(service, namespace), serviceNames(headless, namespace)...)
+	return append(names,
+		fmt.Sprintf("*.%s.%s.svc", headless, namespace),
+		fmt.Sprintf("*.%s.%s.svc.cluster.local", headless, namespace),
+		"localhost")
+}
+
+// serviceNames returns the DNS names resolving to a service from its namespace and the other ones
+func serviceNames(service, namespace string) []string {
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+}
+
+// elasticsearchPodNames returns the pods of the Elasticsearch nodes, the ones requested by the spec
+// and the ones still running, such as the nodes being removed by a scale-down
+func (r *EFKStackReconciler) elasticsearchPodNames(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) ([]string, error) {
+	names := map[string]bool{}
+	for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+		for i := int32(0); i < nodeSet.Replicas; i++ {
+			names[fmt.Sprintf("%s-%d", nodeSet.StatefulSet, i)] = true
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(namespace),
+		client.MatchingLabels{"app.kubernetes.io/instance": fmt.Sprintf("%s-elasticsearch", efkStack.Name)},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list Elasticsearch pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		names[pod.Name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// getSecret returns a Secret, or nil if it does not exist
+func (r *EFKStackReconciler) getSecret(ctx context.Context, name, namespace string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get Secret %s: %w", name, err)
+	}
+	return secret, nil
+}
+
+// applyStackSecret creates or updates a Secret generated for a stack (certificates, credentials).
+// The Secret is only written when its content changes: Elasticsearch reloads the mounted
+// certificates on change.
+// It is not labeled with the Helm release, the configuration hash would restart the pods
+func (r *EFKStackReconciler) applyStackSecret(ctx context.Context, efkStack *loggingv1.EFKStack, name, namespace string, data map[string][]byte) error {
+	secret, err := r.getSecret(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/managed-by": "efk-operator",
+		stackLabel:                     efkStack.Name,
+	}
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return fmt.Errorf("failed to create Secret %s: %w", name, err)
+		}
+		return nil
+	}
+	if secretDataEqual(secret.Data, data) {
+		return nil
+	}
+	secret.Data = data
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		secret.Labels[key] = value
+	}
+	if err := r.Update(ctx, secret); err != nil {
+		return fmt.Errorf("failed to update Secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// elasticsearchCAValues returns the Helm values mounting the CA bundle of the stack in the
+// Elasticsearch clients, with its checksum restarting them when the CA is rotated. It returns
+// nil when the certificates are not managed by the operator or not issued yet
+func (r *EFKStackReconciler) elasticsearchCAValues(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) map[string]interface{} {
+	if !managedCertificates(efkStack) {
+		return nil
+	}
+	_, caName, _, _ := certificateSecretNames(efkStack)
+	secret, err := r.getSecret(ctx, caName, namespace)
+	if err != nil || secret == nil {
+		return nil
+	}
+	checksum := sha256.Sum256(secret.Data[certificates.CAKey])
+	return map[string]interface{}{
+		"caSecretName": caName,
+		"caChecksum":   hex.EncodeToString(checksum[:])[:16],
+	}
+}
+
+// secretDataEqual returns true if two Secret payloads hold the same keys and values
+func secretDataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		other, ok := b[key]
+		if !ok || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// notAfter returns the expiry of a PEM certificate as an API time
+func notAfter(certPEM []byte) (*metav1.Time, error) {
+	expiry, err := certificates.NotAfter(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.Time{Time: expiry}, nil
+}
+
+// earliest returns the earliest of the given times, ignoring the nil ones
+func earliest(times ...*metav1.Time) time.Time {
+	var result time.Time
+	for _, t := range times {
+		if t != nil && (result.IsZero() || t.Time.Before(result)) {
+			result = t.Time
+		}
+	}
+	return result
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+)
+
+var _ = Describe("Certificates", func() {
+	var (
+		ctx        context.Context
+		efkStack   *loggingv1.EFKStack
+		reconciler *EFKStackReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					Mode:     "cluster",
+					Replicas: 3,
+					Security: loggingv1.SecuritySpec{TLSEnabled: true, AuthEnabled: true},
+				},
+			},
+		}
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		reconciler = &EFKStackReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	})
+
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "logging"}, secret)).To(Succeed())
+		return secret
+	}
+
+	It("Should issue the CA, the HTTP certificate and one transport certificate per node", func() {
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+
+		ca := getSecret("test-efk-elasticsearch-ca")
+		Expect(ca.Labels).To(HaveKeyWithValue(stackLabel, "test-efk"))
+		Expect(ca.Labels).NotTo(HaveKey("app.kubernetes.io/instance"))
+		Expect(ca.Data).To(HaveKey(certificates.CAKey))
+		Expect(ca.Data).NotTo(HaveKey(certificates.PrivateKeyKey))
+
+		internal := getSecret("test-efk-elasticsearch-ca-internal")
+		issuer, err := certificates.ParseCA(internal.Data[certificates.CertificateKey], internal.Data[certificates.PrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+
+		http := getSecret("test-efk-elasticsearch-http-certs")
+		Expect(issuer.Valid(http.Data[certificates.CertificateKey], []string{
+			"test-efk-elasticsearch",
+			"test-efk-elasticsearch.logging.svc",
+			"*.test-efk-elasticsearch-headless.logging.svc",
+		}, 0)).To(BeTrue())
+
+		transport := getSecret("test-efk-elasticsearch-transport-certs")
+		Expect(transport.Data).To(HaveLen(7))
+		Expect(issuer.Valid(transport.Data["test-efk-elasticsearch-2.tls.crt"], []string{
+			"test-efk-elasticsearch-2.test-efk-elasticsearch-headless.logging.svc",
+		}, 0)).To(BeTrue())
+
+		status := efkStack.Status.Elasticsearch.Certificates
+		Expect(status).NotTo(BeNil())
+		Expect(status.CASecretName).To(Equal("test-efk-elasticsearch-ca"))
+		Expect(status.TransportNotAfter.Time).To(BeTemporally("~", time.Now().Add(certificateValidity), time.Hour))
+		Expect(meta.IsStatusConditionTrue(efkStack.Status.Conditions, loggingv1.ConditionCertificatesReady)).To(BeTrue())
+
+		values := reconciler.elasticsearchCAValues(ctx, efkStack, "logging")
+		Expect(values).To(HaveKeyWithValue("caSecretName", "test-efk-elasticsearch-ca"))
+		Expect(values).To(HaveKey("caChecksum"))
+	})
+
+	It("Should issue the Kibana certificate with global TLS", func() {
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		Expect(reconciler.kibanaTLSValues(ctx, efkStack, "logging")).To(BeNil())
+		Expect(kibanaURL(efkStack, "logging")).To(HavePrefix("http://"))
+
+		efkStack.Spec.Global.TLS.Enabled = true
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		internal := getSecret("test-efk-elasticsearch-ca-internal")
+		issuer, err := certificates.ParseCA(internal.Data[certificates.CertificateKey], internal.Data[certificates.PrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		kibana := getSecret("test-efk-kibana-certs")
+		Expect(issuer.Valid(kibana.Data[certificates.CertificateKey], []string{"test-efk-kibana", "test-efk-kibana.logging.svc"}, 0)).To(BeTrue())
+		Expect(kibana.Data[certificates.CAKey]).To(Equal(getSecret("test-efk-elasticsearch-ca").Data[certificates.CAKey]))
+
+		Expect(reconciler.kibanaTLSValues(ctx, efkStack, "logging")).To(HaveKeyWithValue("secretName", "test-efk-kibana-certs"))
+		Expect(kibanaURL(efkStack, "logging")).To(Equal("https://test-efk-kibana.logging.svc:5601"))
+	})
+
+	It("Should keep valid certificates and reissue the ones about to expire", func() {
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		http := getSecret("test-efk-elasticsearch-http-certs")
+		transport := getSecret("test-efk-elasticsearch-transport-certs")
+
+		// Certificat du nœud 0 dans sa fenêtre de renouvellement
+		internal := getSecret("test-efk-elasticsearch-ca-internal")
+		issuer, err := certificates.ParseCA(internal.Data[certificates.CertificateKey], internal.Data[certificates.PrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		expiring, err := issuer.Issue("test-efk-elasticsearch-0", []string{"test-efk-elasticsearch-0"}, nil, 24*time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		transport.Data["test-efk-elasticsearch-0.tls.crt"] = expiring.CertificatePEM
+		transport.Data["test-efk-elasticsearch-0.tls.key"] = expiring.PrivateKeyPEM
+		Expect(reconciler.Update(ctx, transport)).To(Succeed())
+
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		Expect(getSecret("test-efk-elasticsearch-http-certs").Data).To(Equal(http.Data))
+		renewed := getSecret("test-efk-elasticsearch-transport-certs")
+		Expect(renewed.Data["test-efk-elasticsearch-0.tls.crt"]).NotTo(Equal(expiring.CertificatePEM))
+		Expect(renewed.Data["test-efk-elasticsearch-1.tls.crt"]).To(Equal(transport.Data["test-efk-elasticsearch-1.tls.crt"]))
+	})
+
+	It("Should rotate the CA and keep trusting the previous one", func() {
+		previous, err := certificates.NewCA("previous", certificateValidity)
+		Expect(err).NotTo(HaveOccurred())
+		encoded, err := previous.Encode()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-ca-internal", Namespace: "logging"},
+			Data: map[string][]byte{
+				certificates.CertificateKey: encoded.CertificatePEM,
+				certificates.PrivateKeyKey:  encoded.PrivateKeyPEM,
+			},
+		})).To(Succeed())
+		Expect(reconciler.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-ca", Namespace: "logging"},
+			Data:       map[string][]byte{certificates.CAKey: encoded.CertificatePEM},
+		})).To(Succeed())
+
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+
+		bundle, err := certificates.ParseCertificates(getSecret("test-efk-elasticsearch-ca").Data[certificates.CAKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle).To(HaveLen(2))
+		Expect(bundle[0].Subject.CommonName).To(Equal("test-efk-elasticsearch-ca"))
+		Expect(bundle[1].Equal(previous.Certificate)).To(BeTrue())
+		Expect(efkStack.Status.Elasticsearch.Certificates.CANotAfter.Time).To(BeTemporally("~", time.Now().Add(caValidity), time.Hour))
+	})
+
+	It("Should request the certificates from a cert-manager issuer and wait for their Secrets", func() {
+		efkStack.Spec.Global.TLS.IssuerRef = &loggingv1.IssuerReference{Name: "platform-ca", Kind: loggingv1.ClusterIssuerKind}
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(certificateGVK)
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-efk-elasticsearch-transport", Namespace: "logging"}, certificate)).To(Succeed())
+		secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		Expect(secretName).To(Equal("test-efk-elasticsearch-transport-certs"))
+		issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+		Expect(issuerRef).To(Equal(map[string]string{"name": "platform-ca", "kind": "ClusterIssuer", "group": "cert-manager.io"}))
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-efk-kibana", Namespace: "logging"}, certificate)).To(Succeed())
+
+		// Pas de certificats auto-signés pendant l'émission par cert-manager
+		Expect(efkStack.Status.Elasticsearch.Certificates).To(BeNil())
+		condition := meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionCertificatesReady)
+		Expect(condition.Reason).To(Equal("Issuing"))
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-efk-elasticsearch-ca-internal", Namespace: "logging"}, &corev1.Secret{})).NotTo(Succeed())
+
+		// Secrets écrits par cert-manager
+		ca, err := certificates.NewCA("platform-ca", caValidity)
+		Expect(err).NotTo(HaveOccurred())
+		encodedCA, err := ca.Encode()
+		Expect(err).NotTo(HaveOccurred())
+		for _, name := range []string{"test-efk-elasticsearch-http-certs", "test-efk-elasticsearch-transport-certs", "test-efk-kibana-certs"} {
+			issued, err := ca.Issue(name, []string{name}, nil, certificateValidity)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciler.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "logging"},
+				Data: map[string][]byte{
+					certificates.CertificateKey: issued.CertificatePEM,
+					certificates.PrivateKeyKey:  issued.PrivateKeyPEM,
+					certificates.CAKey:          encodedCA.CertificatePEM,
+				},
+			})).To(Succeed())
+		}
+
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		Expect(getSecret("test-efk-elasticsearch-ca").Data[certificates.CAKey]).To(Equal(encodedCA.CertificatePEM))
+		Expect(efkStack.Status.Elasticsearch.Certificates.HTTPNotAfter).NotTo(BeNil())
+		Expect(meta.IsStatusConditionTrue(efkStack.Status.Conditions, loggingv1.ConditionCertificatesReady)).To(BeTrue())
+		Expect(reconciler.kibanaTLSValues(ctx, efkStack, "logging")).To(HaveKeyWithValue("secretName", "test-efk-kibana-certs"))
+
+		// Retour aux certificats générés par l'opérateur
+		efkStack.Spec.Global.TLS.IssuerRef = nil
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-efk-kibana", Namespace: "logging"}, certificate)).NotTo(Succeed())
+		Expect(reconciler.kibanaTLSValues(ctx, efkStack, "logging")).To(BeNil())
+	})
+
+	It("Should not manage the certificates of a user-provided keystore", func() {
+		efkStack.Spec.Elasticsearch.Security.TLSSecretName = "elasticsearch-certs"
+		Expect(reconciler.reconcileCertificates(ctx, efkStack, "logging")).To(Succeed())
+		Expect(efkStack.Status.Elasticsearch.Certificates).To(BeNil())
+		Expect(elasticsearchURL(efkStack, "logging")).To(HavePrefix("http://"))
+		Expect(reconciler.elasticsearchCAValues(ctx, efkStack, "logging")).To(BeNil())
+	})
+})
@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+)
+
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// certificateGVK is the cert-manager Certificate, handled as an unstructured object so that the
+// operator runs on clusters without cert-manager
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certManagerGroup is the API group of the cert-manager issuers
+const certManagerGroup = "cert-manager.io"
+
+// certificateRequest is a cert-manager Certificate requested for a component of the stack
+type certificateRequest struct {
+	name        string
+	secretName  string
+	dnsNames    []string
+	ipAddresses []string
+}
+
+// requestCertificates creates the cert-manager Certificates of Elasticsearch and Kibana and, once
+// cert-manager has written their Secrets, copies the CA of the issuer to the CA bundle Secret read by
+// the clients. It returns nil while the certificates are not issued yet. cert-manager renews the
+// Secrets in place: Elasticsearch reloads them, and the Secret watch refreshes the bundle
+func (r *EFKStackReconciler) requestCertificates(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, issuer *loggingv1.IssuerReference) (*loggingv1.CertificatesStatus, error) {
+	_, caName, httpName, transportName := certificateSecretNames(efkStack)
+	headless := fmt.Sprintf("%s-elasticsearch-headless", efkStack.Name)
+	cluster := efkStack.Spec.Elasticsearch.Mode != "singleton"
+
+	requests := []certificateRequest{
+		{fmt.Sprintf("%s-elasticsearch-http", efkStack.Name), httpName, elasticsearchHTTPNames(efkStack, namespace), []string{loopbackIP}},
+		{fmt.Sprintf("%s-kibana", efkStack.Name), kibanaCertificateSecretName(efkStack), serviceNames(fmt.Sprintf("%s-kibana", efkStack.Name), namespace), nil},
+	}
+	if cluster {
+		// Certificat transport partagé par les nœuds : ils ne vérifient que la CA (verification_mode certificate)
+		requests = append(requests, certificateRequest{fmt.Sprintf("%s-elasticsearch-transport", efkStack.Name), transportName, []string{
+			headless,
+			fmt.Sprintf("*.%s.%s.svc", headless, namespace),
+			fmt.Sprintf("*.%s.%s.svc.cluster.local", headless, namespace),
+		}, nil})
+	}
+	for _, request := range requests {
+		if err := r.applyCertificate(ctx, efkStack, namespace, issuer, request); err != nil {
+			return nil, err
+		}
+	}
+
+	// Les Secrets n'existent qu'une fois les certificats signés par l'issuer
+	status := &loggingv1.CertificatesStatus{CASecretName: caName}
+	var bundle bytes.Buffer
+	secretNames := []string{httpName}
+	if cluster {
+		secretNames = append(secretNames, transportName)
+	}
+	for _, secretName := range secretNames {
+		secret, err := r.getSecret(ctx, secretName, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil || len(secret.Data[certificates.CertificateKey]) == 0 {
+			return nil, nil
+		}
+		ca := secret.Data[certificates.CAKey]
+		if len(ca) == 0 {
+			return nil, fmt.Errorf("issuer %s does not publish its CA in the ca.crt key of Secret %s", issuer.Name, secretName)
+		}
+		if !bytes.Contains(bundle.Bytes(), ca) {
+			bundle.Write(ca)
+		}
+
+		expiry, err := notAfter(secret.Data[certificates.CertificateKey])
+		if err != nil {
+			return nil, err
+		}
+		if secretName == httpName {
+			status.HTTPNotAfter = expiry
+		} else {
+			status.TransportNotAfter = expiry
+		}
+		if status.CANotAfter, err = notAfter(ca); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.applyStackSecret(ctx, efkStack, caName, namespace, map[string][]byte{certificates.CAKey: bundle.Bytes()}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// applyCertificate creates or updates a cert-manager Certificate of a stack. Its Secret carries the
+// stack label, so that it is deleted with the stack
+func (r *EFKStackReconciler) applyCertificate(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, issuer *loggingv1.IssuerReference, request certificateRequest) error {
+	labels := map[string]interface{}{
+		"app.kubernetes.io/managed-by": "efk-operator",
+		stackLabel:                     efkStack.Name,
+	}
+	kind := issuer.Kind
+	if kind == "" {
+		kind = loggingv1.IssuerKind
+	}
+	group := issuer.Group
+	if group == "" {
+		group = certManagerGroup
+	}
+	spec := map[string]interface{}{
+		"secretName":     request.secretName,
+		"secretTemplate": map[string]interface{}{"labels": labels},
+		"commonName":     request.dnsNames[0],
+		"dnsNames":       toInterfaces(request.dnsNames),
+		// Clé PKCS#8 lue par Elasticsearch, renouvelée à chaque émission
+		"privateKey": map[string]interface{}{
+			"algorithm":      "ECDSA",
+			"size":           int64(256),
+			"encoding":       "PKCS8",
+			"rotationPolicy": "Always",
+		},
+		"usages":    []interface{}{"digital signature", "key encipherment", "server auth", "client auth"},
+		"issuerRef": map[string]interface{}{"name": issuer.Name, "kind": kind, "group": group},
+	}
+	if len(request.ipAddresses) > 0 {
+		spec["ipAddresses"] = toInterfaces(request.ipAddresses)
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	name := request.name
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, certificate)
+	if errors.IsNotFound(err) {
+		certificate.SetName(name)
+		certificate.SetNamespace(namespace)
+		certificate.SetLabels(map[string]string{
+			"app.kubernetes.io/managed-by": "efk-operator",
+			stackLabel:                     efkStack.Name,
+		})
+		certificate.Object["spec"] = spec
+		if err := r.Create(ctx, certificate); err != nil {
+			return fmt.Errorf("failed to create Certificate %s: %w", name, err)
+		}
+		log.FromContext(ctx).Info("Requested certificate from cert-manager", "certificate", name, "issuer", issuer.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Certificate %s: %w", name, err)
+	}
+	if equality.Semantic.DeepEqual(certificate.Object["spec"], spec) {
+		return nil
+	}
+	certificate.Object["spec"] = spec
+	if err := r.Update(ctx, certificate); err != nil {
+		return fmt.Errorf("failed to update Certificate %s: %w", name, err)
+	}
+	return nil
+}
+
+// deleteCertManagerCertificates deletes the cert-manager Certificates of a stack that no longer
+// uses an issuer. Clusters without cert-manager have nothing to delete
+func (r *EFKStackReconciler) deleteCertManagerCertificates(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) error {
+	certificateList := &unstructured.UnstructuredList{}
+	certificateList.SetGroupVersionKind(certificateGVK.GroupVersion().WithKind(certificateGVK.Kind + "List"))
+	err := r.List(ctx, certificateList, client.InNamespace(namespace), client.MatchingLabels{stackLabel: efkStack.Name})
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the Certificates of the stack: %w", err)
+	}
+	for i := range certificateList.Items {
+		if err := r.Delete(ctx, &certificateList.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Certificate %s: %w", certificateList.Items[i].GetName(), err)
+		}
+	}
+	return nil
+}
+
+// kibanaTLSValues returns the Helm values serving Kibana over HTTPS with its certificate, with
+// its checksum restarting Kibana on renewal. It returns nil when Kibana serves HTTP or the
+// certificate is not issued yet
+func (r *EFKStackReconciler) kibanaTLSValues(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) map[string]interface{} {
+	if !kibanaTLSEnabled(efkStack) {
+		return nil
+	}
+	secret, err := r.getSecret(ctx, kibanaCertificateSecretName(efkStack), namespace)
+	if err != nil || secret == nil || len(secret.Data[certificates.CertificateKey]) == 0 {
+		return nil
+	}
+	checksum := sha256.Sum256(secret.Data[certificates.CertificateKey])
+	return map[string]interface{}{
+		"secretName": secret.Name,
+		"checksum":   hex.EncodeToString(checksum[:])[:16],
+	}
+}
+
+// toInterfaces converts strings to the list type of unstructured objects
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
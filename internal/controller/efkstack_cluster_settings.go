@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// reconcileClusterSettings applies spec.elasticsearch.clusterSettings as persistent cluster
+// settings and reports them in status.elasticsearch.clusterSettings and in the
+// ClusterSettingsReady condition
+func (r *EFKStackReconciler) reconcileClusterSettings(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	desired := efkStack.Spec.Elasticsearch.ClusterSettings
+	esStatus := &efkStack.Status.Elasticsearch
+	if len(desired) == 0 && esStatus.ClusterSettings == nil {
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionClusterSettingsReady)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionClusterSettingsReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             "Applied",
+		Message:            fmt.Sprintf("%d cluster settings are applied", len(desired)),
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err == nil {
+		esStatus.ClusterSettings, err = syncClusterSettings(ctx, esClient, desired, esStatus.ClusterSettings)
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ApplyFailed"
+		condition.Message = err.Error()
+	} else if esStatus.ClusterSettings == nil {
+		// Tous les settings retirés de la spec ont été remis à leur valeur par défaut
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionClusterSettingsReady)
+		return
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+}
+
+// syncClusterSettings writes the desired settings that differ from the persistent cluster
+// settings, resets the settings applied previously and removed from the spec, and clears the
+// transient settings overriding them. A setting whose value changed since the operator wrote it
+// is reported as drifted. The previous status is returned unchanged when the update fails
+func syncClusterSettings(ctx context.Context, esClient *elasticsearch.Client, desired map[string]string, previous *loggingv1.ClusterSettingsStatus) (*loggingv1.ClusterSettingsStatus, error) {
+	current, err := esClient.GetClusterSettings(ctx)
+	if err != nil {
+		return previous, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	var applied map[string]string
+	if previous != nil {
+		applied = previous.Applied
+	}
+
+	update := &elasticsearch.ClusterSettings{
+		Persistent: map[string]interface{}{},
+		Transient:  map[string]interface{}{},
+	}
+	var drifted []string
+	for name, value := range desired {
+		actual, found := current.Persistent[name]
+		if !found || settingValue(actual) != settingValue(value) {
+			update.Persistent[name] = value
+			if last, ok := applied[name]; ok && (!found || settingValue(actual) != settingValue(last)) {
+				drifted = append(drifted, name)
+			}
+		}
+		// Un setting transient l'emporte sur le setting persistent
+		if _, found := current.Transient[name]; found {
+			update.Transient[name] = nil
+			drifted = append(drifted, name)
+		}
+	}
+	for name := range applied {
+		if _, ok := desired[name]; !ok {
+			if _, found := current.Persistent[name]; found {
+				update.Persistent[name] = nil
+			}
+		}
+	}
+
+	status := &loggingv1.ClusterSettingsStatus{}
+	if previous != nil {
+		status.Drifted = previous.Drifted
+		status.LastAppliedTime = previous.LastAppliedTime
+	}
+	if len(update.Persistent) > 0 || len(update.Transient) > 0 {
+		if err := esClient.PutClusterSettings(ctx, update); err != nil {
+			return previous, fmt.Errorf("failed to apply cluster settings: %w", err)
+		}
+		sort.Strings(drifted)
+		drifted = dedupSorted(drifted)
+		if len(drifted) > 0 {
+			log.FromContext(ctx).Info("Restored cluster settings changed outside of the operator", "settings", drifted)
+		}
+		log.FromContext(ctx).Info("Applied cluster settings", "count", len(update.Persistent))
+		now := metav1.Now()
+		status.Drifted = drifted
+		status.LastAppliedTime = &now
+	}
+
+	if len(desired) == 0 {
+		return nil, nil
+	}
+	status.Applied = map[string]string{}
+	for name, value := range desired {
+		status.Applied[name] = value
+	}
+	return status, nil
+}
+
+// settingValue returns a cluster setting value in the comma-separated form of the spec, the
+// API returning the list settings as arrays
+func settingValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case string:
+		items := strings.Split(v, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// dedupSorted removes the consecutive duplicates of a sorted list
+func dedupSorted(values []string) []string {
+	var result []string
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Cluster settings", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		esClient *elasticsearch.Client
+		current  *elasticsearch.ClusterSettings
+		puts     []*elasticsearch.ClusterSettings
+		desired  map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		current = &elasticsearch.ClusterSettings{
+			Persistent: map[string]interface{}{"cluster.routing.allocation.enable": "all"},
+			Transient:  map[string]interface{}{},
+		}
+		puts = nil
+		desired = map[string]string{
+			"cluster.routing.allocation.disk.watermark.low":   "90%",
+			"cluster.routing.allocation.awareness.attributes": "zone, rack",
+			"cluster.max_shards_per_node":                     "2000",
+		}
+
+		// Fake Elasticsearch returning the list settings as arrays, like the real API
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_cluster/settings", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				Expect(json.NewEncoder(w).Encode(current)).To(Succeed())
+				return
+			}
+			update := &elasticsearch.ClusterSettings{}
+			Expect(json.NewDecoder(r.Body).Decode(update)).To(Succeed())
+			puts = append(puts, update)
+			apply := func(settings, changes map[string]interface{}) {
+				for name, value := range changes {
+					switch {
+					case value == nil:
+						delete(settings, name)
+					case name == "cluster.routing.allocation.awareness.attributes":
+						settings[name] = []interface{}{"zone", "rack"}
+					default:
+						settings[name] = value
+					}
+				}
+			}
+			apply(current.Persistent, update.Persistent)
+			apply(current.Transient, update.Transient)
+		})
+		server = httptest.NewServer(mux)
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should apply the settings once and report them", func() {
+		status, err := syncClusterSettings(ctx, esClient, desired, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(puts).To(HaveLen(1))
+		Expect(puts[0].Persistent).To(HaveLen(3))
+		Expect(puts[0].Transient).To(BeEmpty())
+		Expect(current.Persistent).To(HaveKeyWithValue("cluster.max_shards_per_node", "2000"))
+		Expect(current.Persistent).To(HaveKeyWithValue("cluster.routing.allocation.enable", "all"))
+		Expect(status.Applied).To(Equal(desired))
+		Expect(status.Drifted).To(BeEmpty())
+		Expect(status.LastAppliedTime).NotTo(BeNil())
+
+		// Les listes renvoyées sous forme de tableaux ne sont pas réécrites
+		puts = nil
+		status, err = syncClusterSettings(ctx, esClient, desired, status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(puts).To(BeEmpty())
+		Expect(status.Applied).To(HaveLen(3))
+	})
+
+	It("Should restore the settings changed outside of the operator", func() {
+		status, err := syncClusterSettings(ctx, esClient, desired, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		current.Persistent["cluster.max_shards_per_node"] = "5000"
+		current.Transient["cluster.routing.allocation.disk.watermark.low"] = "95%"
+		status, err = syncClusterSettings(ctx, esClient, desired, status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Drifted).To(Equal([]string{"cluster.max_shards_per_node", "cluster.routing.allocation.disk.watermark.low"}))
+		Expect(current.Persistent).To(HaveKeyWithValue("cluster.max_shards_per_node", "2000"))
+		Expect(current.Transient).To(BeEmpty())
+
+		// Un changement de la spec n'est pas une dérive
+		desired["cluster.max_shards_per_node"] = "3000"
+		status, err = syncClusterSettings(ctx, esClient, desired, status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Drifted).To(BeEmpty())
+		Expect(current.Persistent).To(HaveKeyWithValue("cluster.max_shards_per_node", "3000"))
+	})
+
+	It("Should reset the settings removed from the spec", func() {
+		status, err := syncClusterSettings(ctx, esClient, desired, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(desired, "cluster.max_shards_per_node")
+		status, err = syncClusterSettings(ctx, esClient, desired, status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current.Persistent).NotTo(HaveKey("cluster.max_shards_per_node"))
+		Expect(status.Applied).To(HaveLen(2))
+
+		status, err = syncClusterSettings(ctx, esClient, nil, status)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(BeNil())
+		Expect(current.Persistent).To(Equal(map[string]interface{}{"cluster.routing.allocation.enable": "all"}))
+	})
+})
@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+// elasticsearchConfigValues returns the settings of spec.elasticsearch.config rendered into
+// elasticsearch.yml. The settings managed by the operator are rejected by the webhook, they are
+// dropped here when it is disabled
+func elasticsearchConfigValues(ctx context.Context, efkStack *loggingv1.EFKStack) map[string]interface{} {
+	values := map[string]interface{}{}
+	for name, value := range efkStack.Spec.Elasticsearch.Config {
+		if loggingv1.IsOperatorManagedSetting(name) {
+			log.FromContext(ctx).Info("Ignoring Elasticsearch setting managed by the operator", "setting", name)
+			continue
+		}
+		values[name] = value
+	}
+	return values
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+var _ = Describe("Elasticsearch configuration", func() {
+	It("Should drop the settings managed by the operator", func() {
+		efkStack := &loggingv1.EFKStack{
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					Config: map[string]string{
+						"indices.memory.index_buffer_size": "20%",
+						"xpack.security.enabled":           "false",
+						"node.roles":                       "data",
+					},
+				},
+			},
+		}
+		Expect(elasticsearchConfigValues(context.Background(), efkStack)).To(Equal(map[string]interface{}{
+			"indices.memory.index_buffer_size": "20%",
+		}))
+	})
+})
@@ -21,21 +21,26 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"path/filepath"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/fluentbit"
 	"github.com/zlorgoncho1/efk-operator/internal/helm"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -45,10 +50,12 @@ import (
 // EFKStackReconciler reconciles a EFKStack object
 type EFKStackReconciler struct {
 	client.Client
-	Scheme     *runtime.Scheme
-	HelmClient *helm.Client
-	RestConfig *rest.Config
-	KubeClient kubernetes.Interface
+	Scheme      *runtime.Scheme
+	HelmClients *helm.ClientCache
+	RestConfig  *rest.Config
+	KubeClient  kubernetes.Interface
+
+	helmClientsOnce sync.Once
 }
 
 //+kubebuilder:rbac:groups=logging.efk.crds.io,resources=efkstacks,verbs=get;list;watch;create;update;patch;delete
@@ -94,6 +101,20 @@ func (r *EFKStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// L'EFKStack est en cours de suppression : désinstaller les composants avant de retirer le finalizer
+	if !efkStack.DeletionTimestamp.IsZero() {
+		return r.finalizeEFKStack(ctx, efkStack, namespace)
+	}
+
+	// Ajouter le finalizer pour pouvoir nettoyer les releases Helm à la suppression
+	if !controllerutil.ContainsFinalizer(efkStack, efkStackFinalizer) {
+		controllerutil.AddFinalizer(efkStack, efkStackFinalizer)
+		if err := r.Update(ctx, efkStack); err != nil {
+			logger.Error(err, "Failed to add finalizer to EFKStack")
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Initialize status if needed
 	if efkStack.Status.Phase == "" {
 		efkStack.Status.Phase = "Pending"
@@ -103,35 +124,48 @@ func (r *EFKStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
-	// Initialize Helm client if not already done
-	if r.HelmClient == nil {
-		helmClient, err := helm.NewClient(r.RestConfig, r.KubeClient, namespace)
-		if err != nil {
-			logger.Error(err, "Failed to create Helm client")
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, err
-		}
-		r.HelmClient = helmClient
+	// Each target namespace gets its own Helm client
+	helmClient, err := r.helmClientFor(namespace)
+	if err != nil {
+		logger.Error(err, "Failed to create Helm client", "namespace", namespace)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
 
 	// Reconcile components in order: Elasticsearch -> Fluent Bit -> Kibana
-	result, err := r.reconcileElasticsearch(ctx, efkStack, namespace)
+	result, err := r.reconcileElasticsearch(ctx, efkStack, helmClient, namespace)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile Elasticsearch")
 		return result, err
 	}
 
+	// Délai avant le rechargement des secure settings en attente
+	var reloadAfter time.Duration
+
 	// Only proceed to Fluent Bit if Elasticsearch is ready
 	if efkStack.Status.Elasticsearch.State == "Ready" {
-		result, err = r.reconcileFluentBit(ctx, efkStack, namespace)
-		if err != nil {
-			logger.Error(err, "Failed to reconcile Fluent Bit")
-			return result, err
+		// Kibana et Fluent Bit s'authentifient avec les mots de passe appliqués ici
+		r.reconcileUsers(ctx, efkStack, namespace)
+		// Les component templates doivent exister avant le template du data stream qui les compose
+		r.reconcileTemplates(ctx, efkStack, namespace)
+		r.reconcileClusterSettings(ctx, efkStack, namespace)
+		reloadAfter = r.reconcileSecureSettings(ctx, efkStack, namespace)
+		r.reconcileSnapshots(ctx, efkStack, namespace)
+
+		// Le data stream doit être couvert par son template avant la première écriture de Fluent Bit
+		if err := r.reconcileIndexLifecycle(ctx, efkStack, namespace); err != nil {
+			logger.Error(err, "Failed to reconcile index lifecycle, Fluent Bit is not updated")
+		} else {
+			result, err = r.reconcileFluentBit(ctx, efkStack, helmClient, namespace)
+			if err != nil {
+				logger.Error(err, "Failed to reconcile Fluent Bit")
+				return result, err
+			}
 		}
 	}
 
 	// Only proceed to Kibana if Elasticsearch is ready
 	if efkStack.Status.Elasticsearch.State == "Ready" {
-		result, err = r.reconcileKibana(ctx, efkStack, namespace)
+		result, err = r.reconcileKibana(ctx, efkStack, helmClient, namespace)
 		if err != nil {
 			logger.Error(err, "Failed to reconcile Kibana")
 			return result, err
@@ -151,21 +185,25 @@ func (r *EFKStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	// Optimiser l'intervalle de réconciliation selon l'état (réduit pour réactivité)
+	requeueAfter := 5 * time.Second
 	if efkStack.Status.Phase == "Ready" {
 		// Si tout est Ready, vérifier moins fréquemment mais toujours réactif
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		requeueAfter = 30 * time.Second
 	}
-	// Si en cours de déploiement, vérifier plus fréquemment
-	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	// Revenir à l'échéance du rechargement des secure settings plutôt qu'à la suivante
+	if reloadAfter > 0 && reloadAfter < requeueAfter {
+		requeueAfter = reloadAfter
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // reconcileElasticsearch handles Elasticsearch deployment
-func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) (ctrl.Result, error) {
+func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStack *loggingv1.EFKStack, helmClient *helm.Client, namespace string) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling Elasticsearch")
 
 	releaseName := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
-	chartPath := filepath.Join("helm-charts", "efk-stack", "elasticsearch")
+	chartName := helm.ElasticsearchChart
 
 	// Determine mode (default to cluster if not specified)
 	mode := efkStack.Spec.Elasticsearch.Mode
@@ -179,55 +217,133 @@ func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStac
 		replicas = 1
 	}
 
+	// Le webhook peut être désactivé : ne jamais déployer une version que les nœuds ne peuvent pas rejoindre
+	if deployed := efkStack.Status.Elasticsearch.Version; deployed != "" && deployed != efkStack.Spec.Elasticsearch.Version {
+		if err := loggingv1.CheckElasticsearchUpgrade(deployed, efkStack.Spec.Elasticsearch.Version); err != nil {
+			logger.Error(err, "Refusing to upgrade Elasticsearch", "from", deployed, "to", efkStack.Spec.Elasticsearch.Version)
+			efkStack.Status.Elasticsearch.State = "Error"
+			efkStack.Status.Elasticsearch.Message = err.Error()
+			meta.SetStatusCondition(&efkStack.Status.Conditions, metav1.Condition{
+				Type:               loggingv1.ConditionElasticsearchUpgrading,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: efkStack.Generation,
+				Reason:             upgradeReasonUnsupported,
+				Message:            err.Error(),
+			})
+			// Seule une modification de la spec peut débloquer la situation
+			return ctrl.Result{}, r.Status().Update(ctx, efkStack)
+		}
+	}
+
+	// Les certificats doivent exister avant les pods qui les montent et avant les appels à l'API
+	if err := r.reconcileCertificates(ctx, efkStack, namespace); err != nil {
+		logger.Error(err, "Failed to issue Elasticsearch certificates")
+		efkStack.Status.Elasticsearch.State = "Error"
+		efkStack.Status.Elasticsearch.Message = fmt.Sprintf("Certificate issuance failed: %v", err)
+		r.Status().Update(ctx, efkStack)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+
+	// Le mot de passe de l'utilisateur elastic est lu par les pods au démarrage
+	if err := r.reconcileCredentials(ctx, efkStack, namespace); err != nil {
+		logger.Error(err, "Failed to generate Elasticsearch credentials")
+		efkStack.Status.Elasticsearch.State = "Error"
+		efkStack.Status.Elasticsearch.Message = fmt.Sprintf("Credential generation failed: %v", err)
+		r.Status().Update(ctx, efkStack)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+
+	// Les nœuds retirés sont vidés de leurs shards avant la réduction des StatefulSets
+	var nodeSetReplicas map[string]int32
+	if mode == "cluster" {
+		desired := map[string]int32{}
+		for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+			desired[nodeSet.StatefulSet] = nodeSet.Replicas
+		}
+		nodeSetReplicas = r.reconcileScaleDown(ctx, efkStack, namespace, desired)
+		if len(efkStack.Spec.Elasticsearch.NodeSets) == 0 {
+			replicas = nodeSetReplicas[releaseName]
+		}
+	}
+
 	// Prepare values for Helm chart
 	values := map[string]interface{}{
-		"version":  efkStack.Spec.Elasticsearch.Version,
-		"mode":     mode,
-		"replicas": replicas,
-		"resources": map[string]interface{}{
-			"requests": map[string]interface{}{
-				"cpu":    efkStack.Spec.Elasticsearch.Resources.Requests.Cpu().String(),
-				"memory": efkStack.Spec.Elasticsearch.Resources.Requests.Memory().String(),
-			},
-			"limits": map[string]interface{}{
-				"cpu":    efkStack.Spec.Elasticsearch.Resources.Limits.Cpu().String(),
-				"memory": efkStack.Spec.Elasticsearch.Resources.Limits.Memory().String(),
-			},
-		},
+		"version":   efkStack.Spec.Elasticsearch.Version,
+		"mode":      mode,
+		"replicas":  replicas,
+		"image":     imageValues(efkStack.Spec.Global, efkStack.Spec.Elasticsearch.Image, efkStack.Spec.Elasticsearch.Version),
+		"resources": resourceValues(efkStack.Spec.Elasticsearch.Resources),
+		"javaOpts":  efkStack.Spec.Elasticsearch.JavaOpts(efkStack.Spec.Elasticsearch.Resources),
 		"storage": map[string]interface{}{
 			"size":             efkStack.Spec.Elasticsearch.Storage.Size,
-			"storageClassName": efkStack.Spec.Elasticsearch.Storage.StorageClassName,
+			"storageClassName": storageClassName(efkStack.Spec.Global, efkStack.Spec.Elasticsearch.Storage.StorageClassName),
 			"path":             efkStack.Spec.Elasticsearch.Storage.Path,
 		},
 		"security": map[string]interface{}{
-			"tlsEnabled":  efkStack.Spec.Elasticsearch.Security.TLSEnabled,
-			"authEnabled": efkStack.Spec.Elasticsearch.Security.AuthEnabled,
+			"tlsEnabled":    efkStack.Spec.Elasticsearch.Security.TLSEnabled,
+			"authEnabled":   efkStack.Spec.Elasticsearch.Security.AuthEnabled,
+			"tlsSecretName": efkStack.Spec.Elasticsearch.Security.TLSSecretName,
 		},
 	}
+	if efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		elasticSecret, _, _ := credentialSecretNames(efkStack)
+		values["security"].(map[string]interface{})["authSecretName"] = elasticSecret
+	}
+	if managedCertificates(efkStack) {
+		_, _, httpSecret, transportSecret := certificateSecretNames(efkStack)
+		values["security"].(map[string]interface{})["certificates"] = map[string]interface{}{
+			"httpSecretName":      httpSecret,
+			"transportSecretName": transportSecret,
+			// cert-manager émet un seul certificat transport pour tous les nœuds
+			"sharedTransport": efkStack.Spec.CertificateIssuer() != nil,
+		}
+	}
+	// Settings d'elasticsearch.yml, appliqués par le redémarrage progressif des nœuds
+	if config := elasticsearchConfigValues(ctx, efkStack); len(config) > 0 {
+		values["config"] = config
+	}
+	if pullSecrets := imagePullSecretValues(efkStack.Spec.Global); pullSecrets != nil {
+		values["imagePullSecrets"] = pullSecrets
+	}
 	if len(efkStack.Spec.Elasticsearch.NodeSelector) > 0 {
 		values["nodeSelector"] = efkStack.Spec.Elasticsearch.NodeSelector
 	}
 	if len(efkStack.Spec.Elasticsearch.Tolerations) > 0 {
 		values["tolerations"] = efkStack.Spec.Elasticsearch.Tolerations
 	}
+	if mode == "cluster" && len(efkStack.Spec.Elasticsearch.NodeSets) > 0 {
+		values["nodeSets"] = nodeSetValues(efkStack, nodeSetReplicas)
+	}
+	// Dépôt de snapshots : credentials dans le keystore, settings du client, volume partagé
+	for key, value := range r.snapshotValues(ctx, efkStack, namespace) {
+		values[key] = value
+	}
+	// Secure settings : le sidecar keystore-updater reconstruit le keystore quand leurs Secrets changent
+	if keystore := secureSettingsValues(efkStack); len(keystore) > 0 {
+		if snapshotKeystore, ok := values["keystore"].([]interface{}); ok {
+			keystore = append(snapshotKeystore, keystore...)
+		}
+		values["keystore"] = keystore
+		values["keystoreUpdater"] = map[string]interface{}{"enabled": true}
+	}
 
 	// Deploy via Helm
-	_, err := r.HelmClient.InstallOrUpgrade(ctx, releaseName, chartPath, values)
+	_, err := helmClient.InstallOrUpgrade(ctx, releaseName, chartName, values)
 	if err != nil {
 		errorMsg := fmt.Sprintf("Helm install/upgrade failed: %v", err)
 		logger.Error(err, "Failed to deploy Elasticsearch via Helm",
 			"release", releaseName,
-			"chartPath", chartPath,
+			"chart", chartName,
 			"namespace", namespace,
 			"mode", mode,
 			"replicas", replicas)
-		
+
 		// Essayer de récupérer le statut du release pour plus d'informations
-		if releaseStatus, getErr := r.HelmClient.GetReleaseStatus(releaseName); getErr == nil {
+		if releaseStatus, getErr := helmClient.GetReleaseStatus(releaseName); getErr == nil {
 			logger.Info("Current Helm release status", "status", releaseStatus)
 			errorMsg = fmt.Sprintf("%s (Release status: %s)", errorMsg, releaseStatus)
 		}
-		
+
 		efkStack.Status.Elasticsearch.State = "Error"
 		efkStack.Status.Elasticsearch.Message = errorMsg
 		r.Status().Update(ctx, efkStack)
@@ -235,7 +351,7 @@ func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStac
 	}
 
 	// Check release status
-	status, err := r.HelmClient.GetReleaseStatus(releaseName)
+	status, err := helmClient.GetReleaseStatus(releaseName)
 	if err != nil {
 		logger.Error(err, "Failed to get release status", "release", releaseName)
 		efkStack.Status.Elasticsearch.Message = fmt.Sprintf("Failed to get release status: %v", err)
@@ -243,12 +359,21 @@ func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStac
 		efkStack.Status.Elasticsearch.Message = "" // Clear error message on success
 	}
 
-	// Update status
-	efkStack.Status.Elasticsearch.Version = efkStack.Spec.Elasticsearch.Version
+	// Update status. La version n'est enregistrée qu'une fois tous les nœuds redémarrés :
+	// jusque-là, les mises à jour suivantes sont validées depuis la version précédente
+	efkStack.Status.Elasticsearch.URL = elasticsearchURL(efkStack, namespace)
 	if status == "deployed" {
-		efkStack.Status.Elasticsearch.State = "Ready"
-		efkStack.Status.Elasticsearch.ReadyReplicas = efkStack.Spec.Elasticsearch.Replicas
-		efkStack.Status.Elasticsearch.Message = ""
+		// L'état réel vient des pods prêts et de la santé du cluster
+		r.updateElasticsearchReadiness(ctx, efkStack, namespace)
+		// En mode cluster, les pods d'une révision précédente sont redémarrés un par un
+		if mode == "cluster" {
+			r.reconcileRollingUpgrade(ctx, efkStack, namespace)
+		} else {
+			name := types.NamespacedName{Name: releaseName, Namespace: namespace}
+			if rolledOut, err := r.deploymentRolledOut(ctx, name); err == nil && rolledOut {
+				efkStack.Status.Elasticsearch.Version = efkStack.Spec.Elasticsearch.Version
+			}
+		}
 	} else {
 		efkStack.Status.Elasticsearch.State = "Deploying"
 		if status != "" {
@@ -260,16 +385,49 @@ func (r *EFKStackReconciler) reconcileElasticsearch(ctx context.Context, efkStac
 }
 
 // reconcileFluentBit handles Fluent Bit deployment
-func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) (ctrl.Result, error) {
+func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *loggingv1.EFKStack, helmClient *helm.Client, namespace string) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling Fluent Bit")
 
 	releaseName := fmt.Sprintf("%s-fluentbit", efkStack.Name)
-	chartPath := filepath.Join("helm-charts", "efk-stack", "fluentbit")
+	chartName := helm.FluentBitChart
+
+	// Fragments FluentBitPipeline des équipes applicatives
+	pipelines, err := r.acceptedPipelines(ctx, efkStack)
+	if err != nil {
+		logger.Error(err, "Failed to collect FluentBitPipelines")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+
+	// Rendre le pipeline typé en fichiers de configuration Fluent Bit
+	defaults := fluentbit.Defaults{
+		ElasticsearchHost: fmt.Sprintf("%s-elasticsearch", efkStack.Name),
+		ElasticsearchPort: 9200,
+		Index:             logsIndex,
+		DataStream:        efkStack.Spec.Elasticsearch.ILM != nil,
+	}
+	// CA des certificats générés par l'opérateur, pour vérifier le service Elasticsearch
+	caValues := r.elasticsearchCAValues(ctx, efkStack, namespace)
+	if caValues != nil {
+		defaults.CAFile = fluentBitCAFile
+	}
+	_, _, credentialsSecret := credentialSecretNames(efkStack)
+	credentialValues := r.credentialValues(ctx, efkStack, credentialsSecret, namespace)
+	defaults.Credentials = credentialValues != nil
+	configFiles, err := fluentbit.Render(efkStack.Spec.FluentBit.Config, pipelines, defaults)
+	if err != nil {
+		logger.Error(err, "Failed to render Fluent Bit configuration")
+		efkStack.Status.FluentBit.State = "Error"
+		efkStack.Status.FluentBit.Message = err.Error()
+		r.Status().Update(ctx, efkStack)
+		// Une configuration invalide ne se corrigera pas sans modification de la spec
+		return ctrl.Result{}, nil
+	}
 
 	// Prepare values for Helm chart
 	values := map[string]interface{}{
 		"version": efkStack.Spec.FluentBit.Version,
+		"image":   imageValues(efkStack.Spec.Global, efkStack.Spec.FluentBit.Image, efkStack.Spec.FluentBit.Version),
 		"resources": map[string]interface{}{
 			"requests": map[string]interface{}{
 				"cpu":    efkStack.Spec.FluentBit.Resources.Requests.Cpu().String(),
@@ -283,9 +441,22 @@ func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *l
 		"elasticsearch": map[string]interface{}{
 			"host":  fmt.Sprintf("%s-elasticsearch", efkStack.Name),
 			"port":  9200,
-			"index": "fluent-bit",
+			"index": logsIndex,
+		},
+		"config": map[string]interface{}{
+			"files": configFiles,
 		},
 	}
+	for key, value := range caValues {
+		values["elasticsearch"].(map[string]interface{})[key] = value
+	}
+	for key, value := range credentialValues {
+		values["elasticsearch"].(map[string]interface{})[key] = value
+	}
+
+	if pullSecrets := imagePullSecretValues(efkStack.Spec.Global); pullSecrets != nil {
+		values["imagePullSecrets"] = pullSecrets
+	}
 
 	// Ajouter nodeSelector si spécifié
 	if len(efkStack.Spec.FluentBit.NodeSelector) > 0 {
@@ -297,37 +468,24 @@ func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *l
 		values["tolerations"] = efkStack.Spec.FluentBit.Tolerations
 	} else {
 		// Tolerations par défaut pour un DaemonSet qui doit s'exécuter sur tous les nœuds
-		values["tolerations"] = []corev1.Toleration{
-			{
-				Operator: corev1.TolerationOpExists,
-				Effect:   corev1.TaintEffectNoSchedule,
-			},
-			{
-				Operator: corev1.TolerationOpExists,
-				Effect:   corev1.TaintEffectNoExecute,
-			},
-			{
-				Operator: corev1.TolerationOpExists,
-				Effect:   corev1.TaintEffectPreferNoSchedule,
-			},
-		}
+		values["tolerations"] = loggingv1.DefaultFluentBitTolerations()
 	}
 
 	// Deploy via Helm
-	_, err := r.HelmClient.InstallOrUpgrade(ctx, releaseName, chartPath, values)
+	_, err = helmClient.InstallOrUpgrade(ctx, releaseName, chartName, values)
 	if err != nil {
 		errorMsg := fmt.Sprintf("Helm install/upgrade failed: %v", err)
 		logger.Error(err, "Failed to deploy Fluent Bit via Helm",
 			"release", releaseName,
-			"chartPath", chartPath,
+			"chart", chartName,
 			"namespace", namespace)
-		
+
 		// Essayer de récupérer le statut du release pour plus d'informations
-		if releaseStatus, getErr := r.HelmClient.GetReleaseStatus(releaseName); getErr == nil {
+		if releaseStatus, getErr := helmClient.GetReleaseStatus(releaseName); getErr == nil {
 			logger.Info("Current Helm release status", "status", releaseStatus)
 			errorMsg = fmt.Sprintf("%s (Release status: %s)", errorMsg, releaseStatus)
 		}
-		
+
 		efkStack.Status.FluentBit.State = "Error"
 		efkStack.Status.FluentBit.Message = errorMsg
 		r.Status().Update(ctx, efkStack)
@@ -335,7 +493,7 @@ func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *l
 	}
 
 	// Check release status
-	status, err := r.HelmClient.GetReleaseStatus(releaseName)
+	status, err := helmClient.GetReleaseStatus(releaseName)
 	if err != nil {
 		logger.Error(err, "Failed to get release status", "release", releaseName)
 		efkStack.Status.FluentBit.Message = fmt.Sprintf("Failed to get release status: %v", err)
@@ -346,8 +504,7 @@ func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *l
 	// Update status
 	efkStack.Status.FluentBit.Version = efkStack.Spec.FluentBit.Version
 	if status == "deployed" {
-		efkStack.Status.FluentBit.State = "Ready"
-		efkStack.Status.FluentBit.Message = ""
+		r.updateFluentBitReadiness(ctx, efkStack, namespace)
 	} else {
 		efkStack.Status.FluentBit.State = "Deploying"
 		if status != "" {
@@ -359,17 +516,18 @@ func (r *EFKStackReconciler) reconcileFluentBit(ctx context.Context, efkStack *l
 }
 
 // reconcileKibana handles Kibana deployment
-func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) (ctrl.Result, error) {
+func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *loggingv1.EFKStack, helmClient *helm.Client, namespace string) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling Kibana")
 
 	releaseName := fmt.Sprintf("%s-kibana", efkStack.Name)
-	chartPath := filepath.Join("helm-charts", "efk-stack", "kibana")
+	chartName := helm.KibanaChart
 
 	// Prepare values for Helm chart
 	values := map[string]interface{}{
 		"version":  efkStack.Spec.Kibana.Version,
 		"replicas": efkStack.Spec.Kibana.Replicas,
+		"image":    imageValues(efkStack.Spec.Global, efkStack.Spec.Kibana.Image, efkStack.Spec.Kibana.Version),
 		"resources": map[string]interface{}{
 			"requests": map[string]interface{}{
 				"cpu":    efkStack.Spec.Kibana.Resources.Requests.Cpu().String(),
@@ -382,10 +540,23 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 		},
 		"elasticsearch": map[string]interface{}{
 			"hosts": []string{
-				fmt.Sprintf("http://%s-elasticsearch:9200", efkStack.Name),
+				fmt.Sprintf("%s://%s-elasticsearch:9200", elasticsearchScheme(efkStack), efkStack.Name),
 			},
 		},
 	}
+	// CA des certificats générés par l'opérateur, pour vérifier le service Elasticsearch
+	for key, value := range r.elasticsearchCAValues(ctx, efkStack, namespace) {
+		values["elasticsearch"].(map[string]interface{})[key] = value
+	}
+	// Utilisateur kibana_system
+	_, credentialsSecret, _ := credentialSecretNames(efkStack)
+	for key, value := range r.credentialValues(ctx, efkStack, credentialsSecret, namespace) {
+		values["elasticsearch"].(map[string]interface{})[key] = value
+	}
+	// Certificat de Kibana (cert-manager ou CA de la stack)
+	if tls := r.kibanaTLSValues(ctx, efkStack, namespace); tls != nil {
+		values["tls"] = tls
+	}
 
 	if efkStack.Spec.Kibana.Ingress.Enabled {
 		// Convertir le host en format hosts attendu par le template
@@ -401,19 +572,11 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 			},
 		}
 
-		// Convertir TLS au format attendu par le template
-		ingressTLS := []map[string]interface{}{}
-		for _, tls := range efkStack.Spec.Kibana.Ingress.TLS {
-			ingressTLS = append(ingressTLS, map[string]interface{}{
-				"hosts":      tls.Hosts,
-				"secretName": tls.SecretName,
-			})
-		}
-
 		ingressConfig := map[string]interface{}{
 			"enabled": true,
 			"hosts":   ingressHosts,
-			"tls":     ingressTLS,
+			// Convertir TLS au format attendu par le template
+			"tls": kibanaIngressTLSValues(efkStack),
 		}
 		// Ne pas inclure les annotations si elles sont vides pour éviter le warning Helm
 		if len(efkStack.Spec.Kibana.Ingress.Annotations) > 0 {
@@ -425,6 +588,9 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 		}
 		values["ingress"] = ingressConfig
 	}
+	if pullSecrets := imagePullSecretValues(efkStack.Spec.Global); pullSecrets != nil {
+		values["imagePullSecrets"] = pullSecrets
+	}
 	if len(efkStack.Spec.Kibana.NodeSelector) > 0 {
 		values["nodeSelector"] = efkStack.Spec.Kibana.NodeSelector
 	}
@@ -433,28 +599,28 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 	}
 
 	// Deploy via Helm
-	_, err := r.HelmClient.InstallOrUpgrade(ctx, releaseName, chartPath, values)
+	_, err := helmClient.InstallOrUpgrade(ctx, releaseName, chartName, values)
 	if err != nil {
 		errorMsg := fmt.Sprintf("Helm install/upgrade failed: %v", err)
 		logger.Error(err, "Failed to deploy Kibana via Helm",
 			"release", releaseName,
-			"chartPath", chartPath,
+			"chart", chartName,
 			"namespace", namespace,
 			"replicas", efkStack.Spec.Kibana.Replicas,
 			"ingressEnabled", efkStack.Spec.Kibana.Ingress.Enabled)
-		
+
 		// Essayer de récupérer le statut du release pour plus d'informations
-		if releaseStatus, getErr := r.HelmClient.GetReleaseStatus(releaseName); getErr == nil {
+		if releaseStatus, getErr := helmClient.GetReleaseStatus(releaseName); getErr == nil {
 			logger.Info("Current Helm release status", "status", releaseStatus)
 			errorMsg = fmt.Sprintf("%s (Release status: %s)", errorMsg, releaseStatus)
 		}
-		
+
 		// Log les valeurs importantes pour le debug (sans les secrets)
 		logger.V(1).Info("Helm values used",
 			"version", efkStack.Spec.Kibana.Version,
 			"replicas", efkStack.Spec.Kibana.Replicas,
 			"ingressEnabled", efkStack.Spec.Kibana.Ingress.Enabled)
-		
+
 		efkStack.Status.Kibana.State = "Error"
 		efkStack.Status.Kibana.Message = errorMsg
 		r.Status().Update(ctx, efkStack)
@@ -462,7 +628,7 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 	}
 
 	// Check release status
-	status, err := r.HelmClient.GetReleaseStatus(releaseName)
+	status, err := helmClient.GetReleaseStatus(releaseName)
 	if err != nil {
 		logger.Error(err, "Failed to get release status", "release", releaseName)
 		efkStack.Status.Kibana.Message = fmt.Sprintf("Failed to get release status: %v", err)
@@ -473,9 +639,7 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 	// Update status
 	efkStack.Status.Kibana.Version = efkStack.Spec.Kibana.Version
 	if status == "deployed" {
-		efkStack.Status.Kibana.State = "Ready"
-		efkStack.Status.Kibana.ReadyReplicas = efkStack.Spec.Kibana.Replicas
-		efkStack.Status.Kibana.Message = ""
+		r.updateKibanaReadiness(ctx, efkStack, namespace)
 		if efkStack.Spec.Kibana.Ingress.Enabled && efkStack.Spec.Kibana.Ingress.Host != "" {
 			efkStack.Status.Kibana.URL = fmt.Sprintf("https://%s", efkStack.Spec.Kibana.Ingress.Host)
 		}
@@ -489,18 +653,52 @@ func (r *EFKStackReconciler) reconcileKibana(ctx context.Context, efkStack *logg
 	return ctrl.Result{}, r.Status().Update(ctx, efkStack)
 }
 
+// helmClientFor returns the Helm client bound to the target namespace
+func (r *EFKStackReconciler) helmClientFor(namespace string) (*helm.Client, error) {
+	r.helmClientsOnce.Do(func() {
+		if r.HelmClients == nil {
+			r.HelmClients = helm.NewClientCache(r.RestConfig, r.KubeClient, nil)
+		}
+	})
+	return r.HelmClients.Get(namespace)
+}
+
 // updatePhase updates the overall phase of the EFKStack
 func (r *EFKStackReconciler) updatePhase(ctx context.Context, efkStack *loggingv1.EFKStack) error {
 	// Determine phase based on component states
-	if efkStack.Status.Elasticsearch.State == "Ready" &&
-		efkStack.Status.FluentBit.State == "Ready" &&
-		efkStack.Status.Kibana.State == "Ready" {
+	states := []string{
+		efkStack.Status.Elasticsearch.State,
+		efkStack.Status.FluentBit.State,
+		efkStack.Status.Kibana.State,
+	}
+	allReady := true
+	anyState := func(state string) bool {
+		for _, s := range states {
+			if s == state {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range states {
+		if s != "Ready" {
+			allReady = false
+		}
+	}
+
+	switch {
+	case allReady:
 		efkStack.Status.Phase = "Ready"
-	} else if efkStack.Status.Elasticsearch.State == "Deploying" ||
-		efkStack.Status.FluentBit.State == "Deploying" ||
-		efkStack.Status.Kibana.State == "Deploying" {
+	case anyState("Error"):
+		efkStack.Status.Phase = "Error"
+	case anyState("Deploying"):
 		efkStack.Status.Phase = "Deploying"
-	} else {
+	case anyState("Upgrading"):
+		efkStack.Status.Phase = "Upgrading"
+	case anyState("NotReady"):
+		// Composant déployé mais pods non prêts ou cluster rouge
+		efkStack.Status.Phase = "Degraded"
+	default:
 		efkStack.Status.Phase = "Pending"
 	}
 
@@ -776,6 +974,12 @@ func (r *EFKStackReconciler) mapSecretToEFKStack(ctx context.Context, obj client
 	secret := obj.(*corev1.Secret)
 	logger := log.FromContext(ctx)
 
+	// Secrets des secure settings, rechargés sans redémarrer les nœuds
+	if requests := r.mapSecureSettingsSecret(ctx, secret); len(requests) > 0 {
+		logger.Info("Secure settings Secret changed, triggering reconcile", "secret", secret.Name, "namespace", secret.Namespace)
+		return requests
+	}
+
 	// Chercher l'EFKStack associé via les labels
 	efkStackName := ""
 	if instance, ok := secret.Labels["app.kubernetes.io/instance"]; ok && len(instance) > 0 {
@@ -857,5 +1061,10 @@ func (r *EFKStackReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.mapSecretToEFKStack),
 		).
+		Watches(
+			&loggingv1.FluentBitPipeline{},
+			handler.EnqueueRequestsFromMapFunc(r.mapFluentBitPipelineToEFKStack),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
 		Complete(r)
 }
@@ -27,7 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -86,4 +88,162 @@ var _ = Describe("EFKStack Controller", func() {
 			Expect(created.Spec.Elasticsearch.Version).To(Equal("8.11.0"))
 		})
 	})
+
+	Context("When computing component readiness", func() {
+		It("Should report Degraded when Kibana pods are not all ready", func() {
+			replicas := int32(2)
+			efkStack := &loggingv1.EFKStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-efk",
+					Namespace: "default",
+				},
+				Status: loggingv1.EFKStackStatus{
+					Elasticsearch: loggingv1.ElasticsearchStatus{State: "Ready"},
+					FluentBit:     loggingv1.FluentBitStatus{State: "Ready"},
+				},
+			}
+			kibana := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-efk-kibana",
+					Namespace: "default",
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{
+					ReadyReplicas: 1,
+				},
+			}
+			_ = appsv1.AddToScheme(scheme)
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(efkStack, kibana).
+				WithStatusSubresource(efkStack).
+				Build()
+
+			reconciler := &EFKStackReconciler{Client: fakeClient, Scheme: scheme}
+			reconciler.updateKibanaReadiness(ctx, efkStack, "default")
+			Expect(efkStack.Status.Kibana.State).To(Equal("NotReady"))
+			Expect(efkStack.Status.Kibana.ReadyReplicas).To(Equal(int32(1)))
+
+			Expect(reconciler.updatePhase(ctx, efkStack)).To(Succeed())
+			Expect(efkStack.Status.Phase).To(Equal("Degraded"))
+		})
+	})
+
+	Context("When the cluster health is unavailable", func() {
+		It("Should report Elasticsearch NotReady even with every pod ready", func() {
+			replicas := int32(1)
+			efkStack := &loggingv1.EFKStack{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "default"},
+				Spec: loggingv1.EFKStackSpec{
+					Elasticsearch: loggingv1.ElasticsearchSpec{Replicas: 1},
+				},
+			}
+			statefulSet := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch", Namespace: "default"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status:     appsv1.StatefulSetStatus{Replicas: 1, ReadyReplicas: 1},
+			}
+			_ = appsv1.AddToScheme(scheme)
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(efkStack, statefulSet).Build()
+
+			// Le service de la stack n'existe pas dans l'environnement de test
+			reconciler := &EFKStackReconciler{Client: fakeClient, Scheme: scheme}
+			reconciler.updateElasticsearchReadiness(ctx, efkStack, "default")
+			Expect(efkStack.Status.Elasticsearch.ReadyReplicas).To(Equal(int32(1)))
+			Expect(efkStack.Status.Elasticsearch.State).To(Equal("NotReady"))
+			Expect(efkStack.Status.Elasticsearch.Message).To(HavePrefix("Cluster health unavailable"))
+		})
+	})
+
+	Context("When deleting an EFKStack", func() {
+		var pvcs []*corev1.PersistentVolumeClaim
+
+		BeforeEach(func() {
+			pvcs = nil
+			for _, name := range []string{
+				"data-test-efk-elasticsearch-hot-0", "data-test-efk-elasticsearch-hot-1", "data-test-efk-elasticsearch-warm-0",
+				"data-other-elasticsearch-hot-0", "data-test-efk-elasticsearch-hot-backup",
+			} {
+				pvcs = append(pvcs, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}})
+			}
+			// Groupe retiré de la spec, reconnu par le label de la release
+			pvcs = append(pvcs, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name: "data-test-efk-elasticsearch-cold-0", Namespace: "default",
+				Labels: map[string]string{"app.kubernetes.io/instance": "test-efk-elasticsearch"},
+			}})
+		})
+
+		It("Should release the finalizer without uninstalling when the policy is Retain", func() {
+			now := metav1.Now()
+			efkStack := &loggingv1.EFKStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-efk",
+					Namespace:         "default",
+					Finalizers:        []string{efkStackFinalizer},
+					DeletionTimestamp: &now,
+				},
+				Spec: loggingv1.EFKStackSpec{
+					DeletionPolicy: loggingv1.DeletionPolicyRetain,
+				},
+			}
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(efkStack, pvcs[0]).
+				WithStatusSubresource(efkStack).
+				Build()
+
+			reconciler := &EFKStackReconciler{Client: fakeClient, Scheme: scheme}
+			_, err := reconciler.finalizeEFKStack(ctx, efkStack, "default")
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fakeClient.Get(ctx, client.ObjectKeyFromObject(efkStack), &loggingv1.EFKStack{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(pvcs[0]), &corev1.PersistentVolumeClaim{})).To(Succeed())
+		})
+
+		It("Should delete the data volumes of every node set when the policy is Delete", func() {
+			now := metav1.Now()
+			efkStack := &loggingv1.EFKStack{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-efk",
+					Namespace:         "default",
+					Finalizers:        []string{efkStackFinalizer},
+					DeletionTimestamp: &now,
+				},
+				Spec: loggingv1.EFKStackSpec{
+					DeletionPolicy: loggingv1.DeletionPolicyDelete,
+					Elasticsearch: loggingv1.ElasticsearchSpec{
+						NodeSets: []loggingv1.NodeSetSpec{{Name: "hot", Replicas: 2}, {Name: "warm", Replicas: 1}},
+					},
+				},
+				// Les releases sont déjà désinstallées
+				Status: loggingv1.EFKStackStatus{
+					Elasticsearch: loggingv1.ElasticsearchStatus{State: "Uninstalled"},
+					FluentBit:     loggingv1.FluentBitStatus{State: "Uninstalled"},
+					Kibana:        loggingv1.KibanaStatus{State: "Uninstalled"},
+				},
+			}
+			objects := []client.Object{efkStack}
+			for _, pvc := range pvcs {
+				objects = append(objects, pvc)
+			}
+			fakeClient = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(efkStack).
+				Build()
+
+			reconciler := &EFKStackReconciler{Client: fakeClient, Scheme: scheme}
+			_, err := reconciler.finalizeEFKStack(ctx, efkStack, "default")
+			Expect(err).NotTo(HaveOccurred())
+
+			remaining := &corev1.PersistentVolumeClaimList{}
+			Expect(fakeClient.List(ctx, remaining)).To(Succeed())
+			var names []string
+			for _, pvc := range remaining.Items {
+				names = append(names, pvc.Name)
+			}
+			Expect(names).To(ConsistOf("data-other-elasticsearch-hot-0", "data-test-efk-elasticsearch-hot-backup"))
+		})
+	})
 })
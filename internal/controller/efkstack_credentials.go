@@ -0,0 +1,424 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// Built-in users and the native user of Fluent Bit
+const (
+	elasticUser      = "elastic"
+	kibanaSystemUser = "kibana_system"
+	fluentBitUser    = "fluentbit"
+	fluentBitRole    = "fluentbit_writer"
+)
+
+// pendingPasswordKey holds the new password of the elastic user while it is applied to Elasticsearch
+const pendingPasswordKey = "pending-password"
+
+// fluentBitRolePrivileges lets Fluent Bit create and write the indices and data streams of its outputs
+func fluentBitRolePrivileges(indices []string) *elasticsearch.Role {
+	return &elasticsearch.Role{
+		Indices: []elasticsearch.IndexPrivileges{{
+			Names:      indices,
+			Privileges: []string{"create_index", "index", "auto_configure"},
+		}},
+	}
+}
+
+// fluentBitIndexPatterns returns the indices and data streams that Fluent Bit writes with the
+// credentials of the stack: those of the elasticsearch outputs without host, of the stack and of
+// the valid FluentBitPipelines, and the index prefixes routed by the pipelines
+func fluentBitIndexPatterns(efkStack *loggingv1.EFKStack, pipelines []loggingv1.FluentBitPipeline) []string {
+	var valid []loggingv1.FluentBitPipeline
+	var routed []string
+	for _, pipeline := range pipelines {
+		if len(pipeline.Validate()) > 0 {
+			continue
+		}
+		valid = append(valid, pipeline)
+		if pipeline.Spec.Index != "" {
+			routed = append(routed, pipeline.Spec.Index)
+		}
+	}
+
+	patterns := map[string]bool{}
+	add := func(es *loggingv1.ElasticsearchOutput, prefixes []string) {
+		// Un cluster externe utilise ses propres credentials
+		if es == nil || es.Host != "" {
+			return
+		}
+		index := es.Index
+		if index == "" {
+			index = logsIndex
+		}
+		if !es.LogstashFormat {
+			patterns[index] = true
+			return
+		}
+		// Index journaliers <prefix>-YYYY.MM.DD
+		for _, prefix := range append([]string{index}, prefixes...) {
+			patterns[prefix+"-*"] = true
+		}
+	}
+
+	outputs := efkStack.Spec.FluentBit.Config.Outputs
+	if len(outputs) == 0 {
+		// Sortie par défaut du rendu Fluent Bit
+		ilm := efkStack.Spec.Elasticsearch.ILM != nil
+		outputs = []loggingv1.FluentBitOutput{{Elasticsearch: &loggingv1.ElasticsearchOutput{LogstashFormat: !ilm, DataStream: ilm}}}
+	}
+	for _, output := range outputs {
+		add(output.Elasticsearch, routed)
+	}
+	for _, pipeline := range valid {
+		for _, output := range pipeline.Spec.Outputs {
+			add(output.Elasticsearch, nil)
+		}
+	}
+
+	names := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		names = append(names, pattern)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// credentialSecretNames returns the Secrets holding the credentials of the elastic user, of Kibana
+// and of Fluent Bit. The password of the elastic user is read from authSecretName when it is set
+func credentialSecretNames(efkStack *loggingv1.EFKStack) (elastic, kibana, fluentBit string) {
+	elastic = efkStack.Spec.Elasticsearch.Security.AuthSecretName
+	if elastic == "" {
+		elastic = fmt.Sprintf("%s-elasticsearch-elastic-user", efkStack.Name)
+	}
+	return elastic, fmt.Sprintf("%s-kibana-user", efkStack.Name), fmt.Sprintf("%s-fluentbit-user", efkStack.Name)
+}
+
+// reconcileCredentials creates the Secrets holding the passwords of the built-in users, before the
+// pods reading them. The passwords are applied to Elasticsearch by reconcileUsers once it is ready
+func (r *EFKStackReconciler) reconcileCredentials(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) error {
+	esStatus := &efkStack.Status.Elasticsearch
+	if !efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		esStatus.Credentials = nil
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionCredentialsReady)
+		return nil
+	}
+
+	elasticName, kibanaName, fluentBitName := credentialSecretNames(efkStack)
+	generated := [][2]string{{kibanaName, kibanaSystemUser}, {fluentBitName, fluentBitUser}}
+	if efkStack.Spec.Elasticsearch.Security.AuthSecretName == "" {
+		generated = append(generated, [2]string{elasticName, elasticUser})
+	} else {
+		secret, err := r.getSecret(ctx, elasticName, namespace)
+		if err != nil {
+			return err
+		}
+		if secret == nil || len(secret.Data[loggingv1.PasswordKey]) == 0 {
+			err := fmt.Errorf("secret %s does not hold the password of the elastic user in its %s key", elasticName, loggingv1.PasswordKey)
+			setCredentialsCondition(efkStack, metav1.ConditionFalse, "SecretNotFound", err.Error())
+			return err
+		}
+	}
+	for _, user := range generated {
+		if err := r.ensureCredentialSecret(ctx, efkStack, user[0], namespace, user[1]); err != nil {
+			return err
+		}
+	}
+
+	if esStatus.Credentials == nil {
+		// Les mots de passe viennent d'être générés : une annotation déjà présente est considérée comme traitée
+		now := metav1.Now()
+		esStatus.Credentials = &loggingv1.CredentialsStatus{
+			LastRotationTime: &now,
+			RotationRequest:  efkStack.Annotations[loggingv1.RotateCredentialsAnnotation],
+		}
+	}
+	esStatus.Credentials.ElasticSecretName = elasticName
+	esStatus.Credentials.KibanaSecretName = kibanaName
+	esStatus.Credentials.FluentBitSecretName = fluentBitName
+	if meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionCredentialsReady) == nil {
+		setCredentialsCondition(efkStack, metav1.ConditionFalse, "Pending", "Waiting for Elasticsearch to apply the passwords")
+	}
+	return nil
+}
+
+// ensureCredentialSecret creates the credential Secret of a user with a random password. The
+// password of an existing Secret is kept
+func (r *EFKStackReconciler) ensureCredentialSecret(ctx context.Context, efkStack *loggingv1.EFKStack, name, namespace, username string) error {
+	secret, err := r.getSecret(ctx, name, namespace)
+	if err != nil {
+		return err
+	}
+	if secret != nil && len(secret.Data[loggingv1.PasswordKey]) > 0 {
+		return nil
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return err
+	}
+	return r.applyStackSecret(ctx, efkStack, name, namespace, map[string][]byte{
+		loggingv1.UsernameKey: []byte(username),
+		loggingv1.PasswordKey: []byte(password),
+	})
+}
+
+// reconcileUsers applies the passwords of the credential Secrets to Elasticsearch, and rotates the
+// generated ones when the rotation interval is over or the rotation annotation changed
+func (r *EFKStackReconciler) reconcileUsers(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	if !efkStack.Spec.Elasticsearch.Security.AuthEnabled || efkStack.Status.Elasticsearch.Credentials == nil {
+		return
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err == nil {
+		err = r.syncCredentials(ctx, efkStack, namespace, esClient, time.Now())
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to apply the credentials of the built-in users")
+		setCredentialsCondition(efkStack, metav1.ConditionFalse, "ApplyFailed", err.Error())
+		return
+	}
+	setCredentialsCondition(efkStack, metav1.ConditionTrue, "Applied", "The passwords of the built-in users are applied")
+}
+
+// syncCredentials completes an interrupted rotation of the elastic password, rotates the generated
+// passwords when requested, then sets the passwords of Kibana and Fluent Bit that Elasticsearch rejects.
+// esClient authenticates as the elastic user
+func (r *EFKStackReconciler) syncCredentials(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, esClient *elasticsearch.Client, now time.Time) error {
+	status := efkStack.Status.Elasticsearch.Credentials
+	elasticName, kibanaName, fluentBitName := credentialSecretNames(efkStack)
+	managedElastic := efkStack.Spec.Elasticsearch.Security.AuthSecretName == ""
+
+	var elastic *corev1.Secret
+	if managedElastic {
+		var err error
+		if elastic, err = r.getSecret(ctx, elasticName, namespace); err != nil {
+			return err
+		}
+		if esClient, err = r.applyElasticPassword(ctx, efkStack, namespace, esClient, elastic); err != nil {
+			return err
+		}
+	}
+
+	request := efkStack.Annotations[loggingv1.RotateCredentialsAnnotation]
+	if request != status.RotationRequest || passwordRotationDue(efkStack, now) {
+		if managedElastic {
+			password, err := generatePassword()
+			if err != nil {
+				return err
+			}
+			// Le nouveau mot de passe est conservé avant d'être appliqué : une rotation interrompue reprend au reconcile suivant
+			data := map[string][]byte{pendingPasswordKey: []byte(password)}
+			for key, value := range elastic.Data {
+				if key != pendingPasswordKey {
+					data[key] = value
+				}
+			}
+			if err := r.applyStackSecret(ctx, efkStack, elasticName, namespace, data); err != nil {
+				return err
+			}
+			elastic.Data = data
+			if esClient, err = r.applyElasticPassword(ctx, efkStack, namespace, esClient, elastic); err != nil {
+				return err
+			}
+		}
+		// Kibana et Fluent Bit redémarrent avec leurs nouveaux mots de passe, appliqués ci-dessous
+		for _, user := range [][2]string{{kibanaName, kibanaSystemUser}, {fluentBitName, fluentBitUser}} {
+			password, err := generatePassword()
+			if err != nil {
+				return err
+			}
+			if err := r.applyStackSecret(ctx, efkStack, user[0], namespace, map[string][]byte{
+				loggingv1.UsernameKey: []byte(user[1]),
+				loggingv1.PasswordKey: []byte(password),
+			}); err != nil {
+				return err
+			}
+		}
+		rotated := metav1.NewTime(now)
+		status.LastRotationTime = &rotated
+		status.RotationRequest = request
+		log.FromContext(ctx).Info("Rotated the passwords of the built-in users")
+	}
+
+	kibana, err := r.credentialPassword(ctx, kibanaName, namespace)
+	if err != nil {
+		return err
+	}
+	if err := ensureUserPassword(ctx, esClient, kibanaSystemUser, kibana, func() error {
+		return esClient.ChangePassword(ctx, kibanaSystemUser, kibana)
+	}); err != nil {
+		return err
+	}
+
+	fluentBit, err := r.credentialPassword(ctx, fluentBitName, namespace)
+	if err != nil {
+		return err
+	}
+	pipelines, err := r.stackPipelines(ctx, efkStack)
+	if err != nil {
+		return err
+	}
+	if err := ensureFluentBitRole(ctx, esClient, fluentBitIndexPatterns(efkStack, pipelines)); err != nil {
+		return err
+	}
+	return ensureUserPassword(ctx, esClient, fluentBitUser, fluentBit, func() error {
+		return esClient.PutUser(ctx, fluentBitUser, &elasticsearch.User{Password: fluentBit, Roles: []string{fluentBitRole}})
+	})
+}
+
+// ensureFluentBitRole creates the role of Fluent Bit or replaces it when the indices of the
+// outputs changed
+func ensureFluentBitRole(ctx context.Context, esClient *elasticsearch.Client, indices []string) error {
+	desired := fluentBitRolePrivileges(indices)
+	current, err := esClient.GetRole(ctx, fluentBitRole)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return fmt.Errorf("failed to get role %s: %w", fluentBitRole, err)
+	}
+	if err == nil {
+		if inSync, err := elasticsearch.Contains(current, desired); err != nil || inSync {
+			return err
+		}
+	}
+	if err := esClient.PutRole(ctx, fluentBitRole, desired); err != nil {
+		return fmt.Errorf("failed to apply role %s: %w", fluentBitRole, err)
+	}
+	return nil
+}
+
+// applyElasticPassword applies the pending password of the elastic user, then makes it the current
+// password of the Secret. It returns the client authenticating with the password in effect
+func (r *EFKStackReconciler) applyElasticPassword(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, esClient *elasticsearch.Client, secret *corev1.Secret) (*elasticsearch.Client, error) {
+	pending := string(secret.Data[pendingPasswordKey])
+	if pending == "" {
+		return esClient, nil
+	}
+	next := esClient.WithBasicAuth(elasticUser, pending)
+	// Le mot de passe a pu être appliqué avant l'interruption de la rotation précédente
+	if err := next.Authenticate(ctx); err != nil {
+		if !elasticsearch.IsUnauthorized(err) {
+			return nil, err
+		}
+		if err := esClient.ChangePassword(ctx, elasticUser, pending); err != nil {
+			return nil, fmt.Errorf("failed to change the password of the elastic user: %w", err)
+		}
+	}
+	data := map[string][]byte{
+		loggingv1.UsernameKey: []byte(elasticUser),
+		loggingv1.PasswordKey: []byte(pending),
+	}
+	if err := r.applyStackSecret(ctx, efkStack, secret.Name, namespace, data); err != nil {
+		return nil, err
+	}
+	secret.Data = data
+	return next, nil
+}
+
+// ensureUserPassword calls apply when Elasticsearch rejects the password of a user
+func ensureUserPassword(ctx context.Context, esClient *elasticsearch.Client, username, password string, apply func() error) error {
+	err := esClient.WithBasicAuth(username, password).Authenticate(ctx)
+	if err == nil {
+		return nil
+	}
+	if !elasticsearch.IsUnauthorized(err) {
+		return err
+	}
+	if err := apply(); err != nil {
+		return fmt.Errorf("failed to set the password of user %s: %w", username, err)
+	}
+	log.FromContext(ctx).Info("Set the password of an Elasticsearch user", "user", username)
+	return nil
+}
+
+// credentialPassword returns the password of a credential Secret
+func (r *EFKStackReconciler) credentialPassword(ctx context.Context, name, namespace string) (string, error) {
+	secret, err := r.getSecret(ctx, name, namespace)
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || len(secret.Data[loggingv1.PasswordKey]) == 0 {
+		return "", fmt.Errorf("secret %s has no %s key", name, loggingv1.PasswordKey)
+	}
+	return string(secret.Data[loggingv1.PasswordKey]), nil
+}
+
+// passwordRotationDue returns true when the generated passwords are older than the rotation interval
+func passwordRotationDue(efkStack *loggingv1.EFKStack, now time.Time) bool {
+	interval := efkStack.Spec.Elasticsearch.Security.PasswordRotationInterval
+	status := efkStack.Status.Elasticsearch.Credentials
+	if interval == nil || status == nil || status.LastRotationTime == nil {
+		return false
+	}
+	return !now.Before(status.LastRotationTime.Add(interval.Duration))
+}
+
+// credentialValues returns the Helm values reading the credentials of a Secret in a client of
+// Elasticsearch, with their checksum restarting the client when they are rotated. It returns nil
+// when authentication is disabled or the Secret does not exist yet
+func (r *EFKStackReconciler) credentialValues(ctx context.Context, efkStack *loggingv1.EFKStack, name, namespace string) map[string]interface{} {
+	if !efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		return nil
+	}
+	secret, err := r.getSecret(ctx, name, namespace)
+	if err != nil || secret == nil || len(secret.Data[loggingv1.PasswordKey]) == 0 {
+		return nil
+	}
+	hasher := sha256.New()
+	hasher.Write(secret.Data[loggingv1.UsernameKey])
+	hasher.Write(secret.Data[loggingv1.PasswordKey])
+	return map[string]interface{}{
+		"credentialsSecretName": secret.Name,
+		"credentialsChecksum":   hex.EncodeToString(hasher.Sum(nil))[:16],
+	}
+}
+
+// setCredentialsCondition sets the CredentialsReady condition
+func setCredentialsCondition(efkStack *loggingv1.EFKStack, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&efkStack.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionCredentialsReady,
+		Status:             status,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// generatePassword returns a random password of 24 URL-safe characters
+func generatePassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
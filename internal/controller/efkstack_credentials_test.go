@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Credentials", func() {
+	var (
+		ctx        context.Context
+		efkStack   *loggingv1.EFKStack
+		reconciler *EFKStackReconciler
+		server     *httptest.Server
+		users      map[string]string
+		roles      []string
+		roleBodies map[string]map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					Security: loggingv1.SecuritySpec{AuthEnabled: true},
+				},
+			},
+		}
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		_ = loggingv1.AddToScheme(scheme)
+		reconciler = &EFKStackReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+		// Fake Elasticsearch security API, the elastic user gets its bootstrap password below
+		users = map[string]string{}
+		roles = nil
+		roleBodies = map[string]map[string]interface{}{}
+		authenticated := func(r *http.Request) (string, bool) {
+			user, password, ok := r.BasicAuth()
+			return user, ok && password != "" && users[user] == password
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_security/_authenticate", func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := authenticated(r); !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		})
+		mux.HandleFunc("/_security/role/", func(w http.ResponseWriter, r *http.Request) {
+			name := strings.TrimPrefix(r.URL.Path, "/_security/role/")
+			if r.Method == http.MethodGet {
+				body, ok := roleBodies[name]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{name: body})).To(Succeed())
+				return
+			}
+			var body map[string]interface{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			roleBodies[name] = body
+			roles = append(roles, name)
+		})
+		mux.HandleFunc("/_security/user/", func(w http.ResponseWriter, r *http.Request) {
+			if user, ok := authenticated(r); !ok || user != elasticUser {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body map[string]interface{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/_security/user/"), "/_password")
+			users[name] = body["password"].(string)
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: name, Namespace: "logging"}, secret)).To(Succeed())
+		return secret
+	}
+	password := func(name string) string {
+		return string(getSecret(name).Data[loggingv1.PasswordKey])
+	}
+	// Client of the operator, authenticated with the password of the elastic Secret
+	elasticClient := func() *elasticsearch.Client {
+		elasticName, _, _ := credentialSecretNames(efkStack)
+		return elasticsearch.NewClient(elasticsearch.Config{URL: server.URL, Username: elasticUser, Password: password(elasticName)})
+	}
+
+	It("Should generate the credentials and apply them to Elasticsearch", func() {
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).To(Succeed())
+
+		elastic := getSecret("test-efk-elasticsearch-elastic-user")
+		Expect(elastic.Labels).To(HaveKeyWithValue(stackLabel, "test-efk"))
+		Expect(string(elastic.Data[loggingv1.UsernameKey])).To(Equal("elastic"))
+		Expect(elastic.Data[loggingv1.PasswordKey]).To(HaveLen(24))
+		Expect(string(getSecret("test-efk-kibana-user").Data[loggingv1.UsernameKey])).To(Equal("kibana_system"))
+		Expect(password("test-efk-fluentbit-user")).NotTo(Equal(password("test-efk-kibana-user")))
+
+		status := efkStack.Status.Elasticsearch.Credentials
+		Expect(status.ElasticSecretName).To(Equal("test-efk-elasticsearch-elastic-user"))
+		Expect(status.LastRotationTime).NotTo(BeNil())
+		Expect(meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionCredentialsReady).Reason).To(Equal("Pending"))
+
+		// Mot de passe d'amorçage lu par les nœuds au démarrage
+		users[elasticUser] = password("test-efk-elasticsearch-elastic-user")
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(users).To(HaveKeyWithValue("kibana_system", password("test-efk-kibana-user")))
+		Expect(users).To(HaveKeyWithValue("fluentbit", password("test-efk-fluentbit-user")))
+		Expect(roles).To(Equal([]string{"fluentbit_writer"}))
+
+		// Les mots de passe acceptés ne sont pas réécrits
+		roles = nil
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(roles).To(BeEmpty())
+
+		values := reconciler.credentialValues(ctx, efkStack, "test-efk-kibana-user", "logging")
+		Expect(values).To(HaveKeyWithValue("credentialsSecretName", "test-efk-kibana-user"))
+		Expect(values).To(HaveKey("credentialsChecksum"))
+	})
+
+	It("Should only let Fluent Bit write the indices of the stack and of its pipelines", func() {
+		for _, pipeline := range []*loggingv1.FluentBitPipeline{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a"},
+				Spec: loggingv1.FluentBitPipelineSpec{
+					StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+					Index:    "team-a",
+					Outputs:  []loggingv1.FluentBitOutput{{Elasticsearch: &loggingv1.ElasticsearchOutput{Index: "team-a-audit"}}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-b"},
+				Spec: loggingv1.FluentBitPipelineSpec{
+					StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+					Index:    ".kibana",
+				},
+			},
+		} {
+			Expect(reconciler.Create(ctx, pipeline)).To(Succeed())
+		}
+		indexNames := func() []interface{} {
+			indices := roleBodies[fluentBitRole]["indices"].([]interface{})
+			Expect(indices).To(HaveLen(1))
+			return indices[0].(map[string]interface{})["names"].([]interface{})
+		}
+
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).To(Succeed())
+		users[elasticUser] = password("test-efk-elasticsearch-elastic-user")
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(indexNames()).To(Equal([]interface{}{"fluent-bit-*", "team-a-*", "team-a-audit"}))
+
+		// Avec ILM, la sortie par défaut écrit dans le data stream et ignore les index des pipelines
+		roles = nil
+		efkStack.Spec.Elasticsearch.ILM = &loggingv1.IndexLifecycleSpec{}
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(roles).To(Equal([]string{"fluentbit_writer"}))
+		Expect(indexNames()).To(Equal([]interface{}{"fluent-bit", "team-a-audit"}))
+	})
+
+	It("Should rotate the passwords on request and on schedule", func() {
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).To(Succeed())
+		users[elasticUser] = password("test-efk-elasticsearch-elastic-user")
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		previousElastic, previousKibana := users[elasticUser], users["kibana_system"]
+		checksum := reconciler.credentialValues(ctx, efkStack, "test-efk-kibana-user", "logging")["credentialsChecksum"]
+
+		efkStack.Annotations = map[string]string{loggingv1.RotateCredentialsAnnotation: "2025-06-01"}
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(users[elasticUser]).NotTo(Equal(previousElastic))
+		Expect(users[elasticUser]).To(Equal(password("test-efk-elasticsearch-elastic-user")))
+		Expect(getSecret("test-efk-elasticsearch-elastic-user").Data).NotTo(HaveKey(pendingPasswordKey))
+		Expect(users["kibana_system"]).NotTo(Equal(previousKibana))
+		Expect(users["kibana_system"]).To(Equal(password("test-efk-kibana-user")))
+		Expect(efkStack.Status.Elasticsearch.Credentials.RotationRequest).To(Equal("2025-06-01"))
+		Expect(reconciler.credentialValues(ctx, efkStack, "test-efk-kibana-user", "logging")["credentialsChecksum"]).NotTo(Equal(checksum))
+
+		// Rotation planifiée
+		efkStack.Spec.Elasticsearch.Security.PasswordRotationInterval = &metav1.Duration{Duration: 24 * time.Hour}
+		Expect(passwordRotationDue(efkStack, time.Now())).To(BeFalse())
+		Expect(passwordRotationDue(efkStack, time.Now().Add(25*time.Hour))).To(BeTrue())
+		previousElastic = users[elasticUser]
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now().Add(25*time.Hour))).To(Succeed())
+		Expect(users[elasticUser]).NotTo(Equal(previousElastic))
+		Expect(passwordRotationDue(efkStack, time.Now().Add(25*time.Hour))).To(BeFalse())
+	})
+
+	It("Should complete a rotation of the elastic password interrupted before it was applied", func() {
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).To(Succeed())
+		elastic := getSecret("test-efk-elasticsearch-elastic-user")
+		users[elasticUser] = string(elastic.Data[loggingv1.PasswordKey])
+		elastic.Data[pendingPasswordKey] = []byte("next-password")
+		Expect(reconciler.Update(ctx, elastic)).To(Succeed())
+
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(users[elasticUser]).To(Equal("next-password"))
+		Expect(password("test-efk-elasticsearch-elastic-user")).To(Equal("next-password"))
+		Expect(getSecret("test-efk-elasticsearch-elastic-user").Data).NotTo(HaveKey(pendingPasswordKey))
+	})
+
+	It("Should read the elastic password from authSecretName", func() {
+		efkStack.Spec.Elasticsearch.Security.AuthSecretName = "elastic-credentials"
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).NotTo(Succeed())
+		Expect(meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionCredentialsReady).Reason).To(Equal("SecretNotFound"))
+
+		Expect(reconciler.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "elastic-credentials", Namespace: "logging"},
+			Data:       map[string][]byte{loggingv1.PasswordKey: []byte("user-defined")},
+		})).To(Succeed())
+		Expect(reconciler.reconcileCredentials(ctx, efkStack, "logging")).To(Succeed())
+		Expect(efkStack.Status.Elasticsearch.Credentials.ElasticSecretName).To(Equal("elastic-credentials"))
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-efk-elasticsearch-elastic-user", Namespace: "logging"}, &corev1.Secret{})).NotTo(Succeed())
+
+		// Le mot de passe fourni n'est jamais changé par la rotation
+		users[elasticUser] = "user-defined"
+		efkStack.Annotations = map[string]string{loggingv1.RotateCredentialsAnnotation: "now"}
+		Expect(reconciler.syncCredentials(ctx, efkStack, "logging", elasticClient(), time.Now())).To(Succeed())
+		Expect(users[elasticUser]).To(Equal("user-defined"))
+		Expect(users["kibana_system"]).To(Equal(password("test-efk-kibana-user")))
+	})
+})
@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+// efkStackFinalizer is the finalizer used to tear down the Helm releases of an EFKStack
+const efkStackFinalizer = "logging.efk.crds.io/finalizer"
+
+// finalizeEFKStack uninstalls the Helm releases of a deleted EFKStack according to its
+// deletion policy, then removes the finalizer so the object can be garbage collected
+func (r *EFKStackReconciler) finalizeEFKStack(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(efkStack, efkStackFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	policy := efkStack.Spec.DeletionPolicy
+	if policy == "" {
+		policy = loggingv1.DeletionPolicyDelete
+	}
+	logger.Info("Finalizing EFKStack", "deletionPolicy", policy)
+
+	if policy != loggingv1.DeletionPolicyRetain {
+		efkStack.Status.Phase = "Terminating"
+
+		// Ordre inverse des dépendances : Kibana -> Fluent Bit -> Elasticsearch
+		components := []struct {
+			release string
+			status  *string
+			message *string
+		}{
+			{fmt.Sprintf("%s-kibana", efkStack.Name), &efkStack.Status.Kibana.State, &efkStack.Status.Kibana.Message},
+			{fmt.Sprintf("%s-fluentbit", efkStack.Name), &efkStack.Status.FluentBit.State, &efkStack.Status.FluentBit.Message},
+			{fmt.Sprintf("%s-elasticsearch", efkStack.Name), &efkStack.Status.Elasticsearch.State, &efkStack.Status.Elasticsearch.Message},
+		}
+
+		for _, component := range components {
+			if *component.status == "Uninstalled" {
+				continue
+			}
+			helmClient, err := r.helmClientFor(namespace)
+			if err != nil {
+				logger.Error(err, "Failed to create Helm client", "namespace", namespace)
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
+
+			*component.status = "Uninstalling"
+			*component.message = fmt.Sprintf("Uninstalling Helm release %s", component.release)
+			r.setTerminatingCondition(efkStack, component.release)
+			if err := r.Status().Update(ctx, efkStack); err != nil {
+				logger.Error(err, "Failed to update EFKStack status during finalization")
+				return ctrl.Result{}, err
+			}
+
+			if err := helmClient.Uninstall(ctx, component.release); err != nil {
+				logger.Error(err, "Failed to uninstall Helm release", "release", component.release)
+				*component.status = "Error"
+				*component.message = fmt.Sprintf("Helm uninstall failed: %v", err)
+				r.Status().Update(ctx, efkStack)
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
+
+			*component.status = "Uninstalled"
+			*component.message = ""
+			if err := r.Status().Update(ctx, efkStack); err != nil {
+				logger.Error(err, "Failed to update EFKStack status during finalization")
+				return ctrl.Result{}, err
+			}
+			logger.Info("Uninstalled Helm release", "release", component.release)
+		}
+
+		// Les PVCs créés par le volumeClaimTemplate ne sont pas supprimés par Helm
+		if policy == loggingv1.DeletionPolicyDelete {
+			if err := r.deleteElasticsearchPVCs(ctx, efkStack, namespace); err != nil {
+				logger.Error(err, "Failed to delete Elasticsearch PVCs")
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
+
+			// Les Secrets créés par l'opérateur ou cert-manager (certificats) ne font partie d'aucune release
+			if err := r.deleteCertManagerCertificates(ctx, efkStack, namespace); err != nil {
+				logger.Error(err, "Failed to delete the Certificates of the stack")
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
+			if err := r.DeleteAllOf(ctx, &corev1.Secret{},
+				client.InNamespace(namespace),
+				client.MatchingLabels{stackLabel: efkStack.Name},
+			); err != nil {
+				logger.Error(err, "Failed to delete the Secrets of the stack")
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
+		}
+	}
+
+	// Les connexions aux APIs de la stack ne serviront plus
+	elasticsearchHTTPClients.forget(client.ObjectKeyFromObject(efkStack))
+	kibanaHTTPClients.forget(client.ObjectKeyFromObject(efkStack))
+
+	controllerutil.RemoveFinalizer(efkStack, efkStackFinalizer)
+	if err := r.Update(ctx, efkStack); err != nil {
+		logger.Error(err, "Failed to remove finalizer from EFKStack")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// deleteElasticsearchPVCs deletes the data volumes of the Elasticsearch nodes. The claims created
+// from the volumeClaimTemplate are named data-<statefulset>-<ordinal> and are not part of the
+// release; the claims labeled with the release, such as those of node sets removed from the spec,
+// are deleted as well
+func (r *EFKStackReconciler) deleteElasticsearchPVCs(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list PVCs: %w", err)
+	}
+
+	statefulSets := map[string]bool{}
+	for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+		statefulSets[nodeSet.StatefulSet] = true
+	}
+	release := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !statefulSets[dataClaimStatefulSet(pvc.Name)] && pvc.Labels["app.kubernetes.io/instance"] != release {
+			continue
+		}
+		if err := r.Delete(ctx, pvc); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete PVC %s: %w", pvc.Name, err)
+		}
+		log.FromContext(ctx).Info("Deleted Elasticsearch PVC", "pvc", pvc.Name)
+	}
+	return nil
+}
+
+// dataClaimStatefulSet returns the StatefulSet of a data-<statefulset>-<ordinal> claim, or an
+// empty string for other claims
+func dataClaimStatefulSet(name string) string {
+	rest, found := strings.CutPrefix(name, "data-")
+	if !found {
+		return ""
+	}
+	index := strings.LastIndex(rest, "-")
+	if index <= 0 {
+		return ""
+	}
+	if _, err := strconv.Atoi(rest[index+1:]); err != nil {
+		return ""
+	}
+	return rest[:index]
+}
+
+// setTerminatingCondition records the teardown progress in the Ready condition
+func (r *EFKStackReconciler) setTerminatingCondition(efkStack *loggingv1.EFKStack, release string) {
+	condition := metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: efkStack.Generation,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "Terminating",
+		Message:            fmt.Sprintf("Uninstalling %s", release),
+	}
+
+	for i, c := range efkStack.Status.Conditions {
+		if c.Type == condition.Type {
+			efkStack.Status.Conditions[i] = condition
+			return
+		}
+	}
+	efkStack.Status.Conditions = append(efkStack.Status.Conditions, condition)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+// imageValues returns the Helm values of the image of a component. The overrides of the
+// component take precedence over spec.global.imageRegistry, which replaces the registry of the
+// chart unless the repository override already starts with a registry. The tag defaults to the
+// version of the component
+func imageValues(global loggingv1.GlobalSpec, image *loggingv1.ImageSpec, version string) map[string]interface{} {
+	values := map[string]interface{}{
+		"tag": version,
+	}
+	if global.ImageRegistry != "" {
+		values["registry"] = global.ImageRegistry
+	}
+	if image == nil {
+		return values
+	}
+
+	if image.Repository != "" {
+		values["repository"] = image.Repository
+		if hasRegistry(image.Repository) {
+			values["registry"] = ""
+		}
+	}
+	if image.Tag != "" {
+		values["tag"] = image.Tag
+	}
+	if image.Digest != "" {
+		values["digest"] = image.Digest
+	}
+	if image.PullPolicy != "" {
+		values["pullPolicy"] = string(image.PullPolicy)
+	}
+	return values
+}
+
+// hasRegistry returns true if the first component of a repository is a registry host, following
+// the rules of the container runtimes: it holds a dot or a port, or is localhost
+func hasRegistry(repository string) bool {
+	first, _, found := strings.Cut(repository, "/")
+	if !found {
+		return false
+	}
+	return strings.ContainsAny(first, ".:") || first == "localhost"
+}
+
+// imagePullSecretValues returns the Helm values of spec.global.imagePullSecrets, or nil when
+// there is none so that the default of the chart applies
+func imagePullSecretValues(global loggingv1.GlobalSpec) []interface{} {
+	if len(global.ImagePullSecrets) == 0 {
+		return nil
+	}
+	secrets := make([]interface{}, 0, len(global.ImagePullSecrets))
+	for _, secret := range global.ImagePullSecrets {
+		secrets = append(secrets, map[string]interface{}{"name": secret.Name})
+	}
+	return secrets
+}
+
+// storageClassName returns the storage class of a volume: the one of the component, or else
+// spec.global.storageClass
+func storageClassName(global loggingv1.GlobalSpec, storageClass string) string {
+	if storageClass != "" {
+		return storageClass
+	}
+	return global.StorageClass
+}
+
+// kibanaIngressTLSValues returns the TLS section of the Kibana Ingress: spec.kibana.ingress.tls,
+// or else spec.global.tls.secretName for the host of the Ingress
+func kibanaIngressTLSValues(efkStack *loggingv1.EFKStack) []map[string]interface{} {
+	ingress := efkStack.Spec.Kibana.Ingress
+	values := []map[string]interface{}{}
+	for _, tls := range ingress.TLS {
+		values = append(values, map[string]interface{}{
+			"hosts":      tls.Hosts,
+			"secretName": tls.SecretName,
+		})
+	}
+	if len(values) == 0 && efkStack.Spec.Global.TLS.SecretName != "" && ingress.Host != "" {
+		values = append(values, map[string]interface{}{
+			"hosts":      []string{ingress.Host},
+			"secretName": efkStack.Spec.Global.TLS.SecretName,
+		})
+	}
+	return values
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+var _ = Describe("Global settings", func() {
+	var efkStack *loggingv1.EFKStack
+
+	BeforeEach(func() {
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{Version: "8.11.0", Mode: "cluster"},
+				Global: loggingv1.GlobalSpec{
+					ImageRegistry:    "registry.example.com",
+					StorageClass:     "standard",
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+				},
+			},
+		}
+	})
+
+	It("Should resolve the images from the global registry and the overrides", func() {
+		global := efkStack.Spec.Global
+		Expect(imageValues(global, nil, "8.11.0")).To(Equal(map[string]interface{}{
+			"registry": "registry.example.com",
+			"tag":      "8.11.0",
+		}))
+
+		// L'image du composant l'emporte sur le registry global
+		Expect(imageValues(global, &loggingv1.ImageSpec{
+			Repository: "mirror.local:5000/elastic/elasticsearch",
+			Digest:     "sha256:0123",
+			PullPolicy: corev1.PullAlways,
+		}, "8.11.0")).To(Equal(map[string]interface{}{
+			"registry":   "",
+			"repository": "mirror.local:5000/elastic/elasticsearch",
+			"tag":        "8.11.0",
+			"digest":     "sha256:0123",
+			"pullPolicy": "Always",
+		}))
+		Expect(imageValues(loggingv1.GlobalSpec{}, &loggingv1.ImageSpec{Repository: "elastic/kibana", Tag: "8.11.0-custom"}, "8.11.0")).
+			To(Equal(map[string]interface{}{"repository": "elastic/kibana", "tag": "8.11.0-custom"}))
+
+		Expect(hasRegistry("fluent/fluent-bit")).To(BeFalse())
+		Expect(hasRegistry("localhost/fluent-bit")).To(BeTrue())
+		Expect(imagePullSecretValues(global)).To(ConsistOf(map[string]interface{}{"name": "registry-credentials"}))
+		Expect(imagePullSecretValues(loggingv1.GlobalSpec{})).To(BeNil())
+	})
+
+	It("Should fall back to the global storage class", func() {
+		efkStack.Spec.Elasticsearch.NodeSets = []loggingv1.NodeSetSpec{
+			{Name: "master", Replicas: 3, Roles: []loggingv1.NodeRole{loggingv1.NodeRoleMaster}},
+			{Name: "hot", Replicas: 2, Roles: []loggingv1.NodeRole{loggingv1.NodeRoleDataHot},
+				Storage: loggingv1.StorageSpec{StorageClassName: "fast-ssd"}},
+		}
+		storageClass := func(value interface{}) interface{} {
+			return value.(map[string]interface{})["storage"].(map[string]interface{})["storageClassName"]
+		}
+		Expect(nodeSetValues(efkStack, nil)).To(ConsistOf(
+			WithTransform(storageClass, Equal("standard")),
+			WithTransform(storageClass, Equal("fast-ssd")),
+		))
+
+		efkStack.Spec.Elasticsearch.Storage.StorageClassName = "gp3"
+		Expect(storageClassName(efkStack.Spec.Global, efkStack.Spec.Elasticsearch.Storage.StorageClassName)).To(Equal("gp3"))
+		Expect(nodeSetValues(efkStack, nil)).To(ConsistOf(
+			WithTransform(storageClass, Equal("gp3")),
+			WithTransform(storageClass, Equal("fast-ssd")),
+		))
+	})
+
+	It("Should default the Kibana Ingress TLS to the global secret", func() {
+		efkStack.Spec.Kibana.Ingress = loggingv1.IngressSpec{Enabled: true, Host: "kibana.example.com"}
+		Expect(kibanaIngressTLSValues(efkStack)).To(BeEmpty())
+
+		efkStack.Spec.Global.TLS.SecretName = "wildcard-tls"
+		Expect(kibanaIngressTLSValues(efkStack)).To(ConsistOf(map[string]interface{}{
+			"hosts":      []string{"kibana.example.com"},
+			"secretName": "wildcard-tls",
+		}))
+
+		efkStack.Spec.Kibana.Ingress.TLS = []loggingv1.IngressTLS{{Hosts: []string{"kibana.example.com"}, SecretName: "kibana-tls"}}
+		Expect(kibanaIngressTLSValues(efkStack)).To(ConsistOf(HaveKeyWithValue("secretName", "kibana-tls")))
+	})
+})
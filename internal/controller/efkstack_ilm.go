@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// logsIndex is the index written by the default Fluent Bit output. With ILM it is also
+// the name of the data stream, of its lifecycle policy and of its index template
+const logsIndex = loggingv1.ReservedIndexTemplateName
+
+// logsIndexTemplatePriority is above the priority of the built-in templates of Elasticsearch
+const logsIndexTemplatePriority = 200
+
+// managedByMeta marks the Elasticsearch objects owned by the operator
+var managedByMeta = map[string]interface{}{"managed_by": "efk-operator"}
+
+// reconcileIndexLifecycle applies the ILM policy and the index template of the logs
+// and records the outcome in the IndexLifecycleReady condition
+func (r *EFKStackReconciler) reconcileIndexLifecycle(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) error {
+	ilm := efkStack.Spec.Elasticsearch.ILM
+	if ilm == nil {
+		// La politique et le template restent dans Elasticsearch pour le data stream existant
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionIndexLifecycleReady)
+		return nil
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err == nil {
+		err = ensureIndexLifecycle(ctx, esClient, ilm)
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionIndexLifecycleReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             "Applied",
+		Message:            fmt.Sprintf("ILM policy and index template %s are applied", logsIndex),
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ApplyFailed"
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+
+	return err
+}
+
+// ensureIndexLifecycle creates or updates the ILM policy then the index template referencing it,
+// leaving them untouched when Elasticsearch already holds the desired definitions. The live
+// objects are compared with the desired ones, including the hash of their definition
+func ensureIndexLifecycle(ctx context.Context, esClient *elasticsearch.Client, ilm *loggingv1.IndexLifecycleSpec) error {
+	logger := log.FromContext(ctx)
+
+	// Le hash de la définition dans _meta détecte les champs retirés de la spec,
+	// comme pour les templates déclarés sur la stack
+	policy := lifecyclePolicy(ilm)
+	var err error
+	if policy.Meta, err = managedMeta(policy); err != nil {
+		return err
+	}
+	current, err := esClient.GetLifecyclePolicy(ctx, logsIndex)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return fmt.Errorf("failed to get ILM policy %s: %w", logsIndex, err)
+	}
+	inSync := false
+	if err == nil {
+		if inSync, err = policyInSync(current, policy); err != nil {
+			return err
+		}
+	}
+	if !inSync {
+		logger.Info("Applying ILM policy", "policy", logsIndex)
+		if err := esClient.PutLifecyclePolicy(ctx, logsIndex, policy); err != nil {
+			return fmt.Errorf("failed to apply ILM policy %s: %w", logsIndex, err)
+		}
+	}
+
+	template := logsIndexTemplate(ilm)
+	if template.Meta, err = managedMeta(template); err != nil {
+		return err
+	}
+	currentTemplate, err := esClient.GetIndexTemplate(ctx, logsIndex)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return fmt.Errorf("failed to get index template %s: %w", logsIndex, err)
+	}
+	inSync = false
+	if err == nil {
+		if inSync, err = elasticsearch.Contains(currentTemplate, template); err != nil {
+			return err
+		}
+	}
+	if !inSync {
+		logger.Info("Applying index template", "template", logsIndex)
+		if err := esClient.PutIndexTemplate(ctx, logsIndex, template); err != nil {
+			return fmt.Errorf("failed to apply index template %s: %w", logsIndex, err)
+		}
+	}
+
+	return nil
+}
+
+// lifecyclePolicy builds the ILM policy: rollover in hot, optional warm optimisations,
+// then deletion once the retention has elapsed
+func lifecyclePolicy(ilm *loggingv1.IndexLifecycleSpec) *elasticsearch.LifecyclePolicy {
+	rollover := map[string]interface{}{}
+	spec := ilm.Hot.Rollover
+	if spec.MaxAge != "" {
+		rollover["max_age"] = spec.MaxAge
+	}
+	if spec.MaxSize != "" {
+		rollover["max_size"] = spec.MaxSize
+	}
+	if spec.MaxPrimaryShardSize != "" {
+		rollover["max_primary_shard_size"] = spec.MaxPrimaryShardSize
+	}
+	if spec.MaxDocs > 0 {
+		rollover["max_docs"] = spec.MaxDocs
+	}
+	// Sans condition, l'index d'écriture ne serait jamais renouvelé
+	if len(rollover) == 0 {
+		rollover["max_age"] = "1d"
+		rollover["max_primary_shard_size"] = "50gb"
+	}
+
+	policy := &elasticsearch.LifecyclePolicy{
+		Phases: map[string]elasticsearch.LifecyclePhase{
+			"hot": {
+				MinAge: "0ms",
+				Actions: map[string]interface{}{
+					"rollover":     rollover,
+					"set_priority": map[string]interface{}{"priority": 100},
+				},
+			},
+			"delete": {
+				MinAge:  fmt.Sprintf("%dd", ilm.RetentionDays),
+				Actions: map[string]interface{}{"delete": map[string]interface{}{}},
+			},
+		},
+	}
+
+	if warm := ilm.Warm; warm != nil {
+		actions := map[string]interface{}{
+			"set_priority": map[string]interface{}{"priority": 50},
+		}
+		if warm.ForceMergeSegments > 0 {
+			actions["forcemerge"] = map[string]interface{}{"max_num_segments": warm.ForceMergeSegments}
+		}
+		if warm.ShrinkShards > 0 {
+			actions["shrink"] = map[string]interface{}{"number_of_shards": warm.ShrinkShards}
+		}
+		if warm.Replicas != nil {
+			actions["allocate"] = map[string]interface{}{"number_of_replicas": *warm.Replicas}
+		}
+		policy.Phases["warm"] = elasticsearch.LifecyclePhase{MinAge: warm.MinAge, Actions: actions}
+	}
+
+	return policy
+}
+
+// policyInSync reports whether the current policy has the desired phases and actions.
+// Removed phases or actions are not caught by elasticsearch.Contains, so they are compared first
+func policyInSync(current, desired *elasticsearch.LifecyclePolicy) (bool, error) {
+	if len(current.Phases) != len(desired.Phases) {
+		return false, nil
+	}
+	for name, phase := range desired.Phases {
+		currentPhase, ok := current.Phases[name]
+		if !ok || len(currentPhase.Actions) != len(phase.Actions) {
+			return false, nil
+		}
+		for action := range phase.Actions {
+			if _, ok := currentPhase.Actions[action]; !ok {
+				return false, nil
+			}
+		}
+	}
+	return elasticsearch.Contains(current, desired)
+}
+
+// logsIndexTemplate builds the index template turning logsIndex into a data stream
+// managed by the ILM policy
+func logsIndexTemplate(ilm *loggingv1.IndexLifecycleSpec) *elasticsearch.IndexTemplate {
+	return &elasticsearch.IndexTemplate{
+		IndexPatterns: []string{logsIndex},
+		ComposedOf:    ilm.ComposedOf,
+		Priority:      logsIndexTemplatePriority,
+		DataStream:    &elasticsearch.DataStreamTemplate{},
+		Template: &elasticsearch.TemplateBody{
+			Settings: map[string]interface{}{
+				"index": map[string]interface{}{
+					"lifecycle": map[string]interface{}{"name": logsIndex},
+				},
+			},
+		},
+	}
+}
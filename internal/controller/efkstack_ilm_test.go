@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Index lifecycle", func() {
+	var (
+		ctx      context.Context
+		server   *httptest.Server
+		esClient *elasticsearch.Client
+		policies map[string]interface{}
+		puts     []string
+		ilm      *loggingv1.IndexLifecycleSpec
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		policies = map[string]interface{}{}
+		puts = nil
+		replicas := int32(0)
+		ilm = &loggingv1.IndexLifecycleSpec{
+			Warm:          &loggingv1.ILMWarmPhase{MinAge: "7d", ForceMergeSegments: 1, Replicas: &replicas},
+			RetentionDays: 30,
+		}
+
+		// Fake Elasticsearch storing the objects it receives, like the real API
+		mux := http.NewServeMux()
+		handle := func(path string, wrap func(body interface{}) interface{}) {
+			mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					var body interface{}
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					policies[path] = body
+					puts = append(puts, path)
+					return
+				}
+				body, ok := policies[path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(wrap(body))).To(Succeed())
+			})
+		}
+		handle("/_ilm/policy/fluent-bit", func(body interface{}) interface{} {
+			return map[string]interface{}{"fluent-bit": body}
+		})
+		handle("/_index_template/fluent-bit", func(body interface{}) interface{} {
+			return map[string]interface{}{"index_templates": []interface{}{
+				map[string]interface{}{"name": "fluent-bit", "index_template": body},
+			}}
+		})
+		server = httptest.NewServer(mux)
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should build the phases from the spec", func() {
+		policy := lifecyclePolicy(ilm)
+
+		Expect(policy.Phases["hot"].Actions["rollover"]).To(Equal(map[string]interface{}{"max_age": "1d", "max_primary_shard_size": "50gb"}))
+		Expect(policy.Phases["warm"].MinAge).To(Equal("7d"))
+		Expect(policy.Phases["warm"].Actions).To(HaveKey("forcemerge"))
+		Expect(policy.Phases["warm"].Actions).To(HaveKey("allocate"))
+		Expect(policy.Phases["warm"].Actions).NotTo(HaveKey("shrink"))
+		Expect(policy.Phases["delete"].MinAge).To(Equal("30d"))
+	})
+
+	It("Should apply the policy and the template only when they drift", func() {
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		Expect(puts).To(Equal([]string{"/_ilm/policy/fluent-bit", "/_index_template/fluent-bit"}))
+
+		puts = nil
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		Expect(puts).To(BeEmpty())
+
+		// Removing the warm phase must be detected
+		ilm.Warm = nil
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		Expect(puts).To(Equal([]string{"/_ilm/policy/fluent-bit"}))
+
+		// Removing a rollover condition only changes the hash of the policy
+		puts = nil
+		ilm.Hot.Rollover = loggingv1.ILMRollover{MaxAge: "1d", MaxDocs: 1000000}
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		ilm.Hot.Rollover.MaxDocs = 0
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		Expect(puts).To(Equal([]string{"/_ilm/policy/fluent-bit", "/_ilm/policy/fluent-bit"}))
+
+		// So does removing a component template from the index template
+		puts = nil
+		ilm.ComposedOf = []string{"mappings"}
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		ilm.ComposedOf = nil
+		Expect(ensureIndexLifecycle(ctx, esClient, ilm)).To(Succeed())
+		Expect(puts).To(Equal([]string{"/_index_template/fluent-bit", "/_index_template/fluent-bit"}))
+	})
+})
@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+// elasticsearchNodeSet is a StatefulSet of Elasticsearch nodes in cluster mode
+type elasticsearchNodeSet struct {
+	// StatefulSet is the name of the StatefulSet
+	StatefulSet string
+	// Replicas is the number of nodes requested by the spec
+	Replicas int32
+	// Master is true when the nodes are master-eligible
+	Master bool
+	// Spec is the node set of the spec, nil for the single StatefulSet of spec.elasticsearch.replicas
+	Spec *loggingv1.NodeSetSpec
+}
+
+// elasticsearchNodeSets returns the StatefulSets of the Elasticsearch nodes in cluster mode.
+// The master-eligible sets come last, so that they are restarted after the other nodes
+func elasticsearchNodeSets(efkStack *loggingv1.EFKStack) []elasticsearchNodeSet {
+	es := &efkStack.Spec.Elasticsearch
+	prefix := fmt.Sprintf("%s-elasticsearch", efkStack.Name)
+	if len(es.NodeSets) == 0 {
+		return []elasticsearchNodeSet{{StatefulSet: prefix, Replicas: es.Replicas, Master: true}}
+	}
+
+	nodeSets := make([]elasticsearchNodeSet, 0, len(es.NodeSets))
+	for i := range es.NodeSets {
+		set := &es.NodeSets[i]
+		nodeSets = append(nodeSets, elasticsearchNodeSet{
+			StatefulSet: fmt.Sprintf("%s-%s", prefix, set.Name),
+			Replicas:    set.Replicas,
+			Master:      set.HasRole(loggingv1.NodeRoleMaster),
+			Spec:        set,
+		})
+	}
+	sort.SliceStable(nodeSets, func(i, j int) bool {
+		return !nodeSets[i].Master && nodeSets[j].Master
+	})
+	return nodeSets
+}
+
+// nodeSetValues returns the Helm values of the node sets with the given replicas per StatefulSet.
+// Each set inherits the resources, storage, nodeSelector and tolerations of spec.elasticsearch
+// it does not define, and spec.global.storageClass when neither defines a storage class
+func nodeSetValues(efkStack *loggingv1.EFKStack, replicas map[string]int32) []interface{} {
+	es := &efkStack.Spec.Elasticsearch
+	values := []interface{}{}
+	for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+		set := nodeSet.Spec
+		if set == nil {
+			continue
+		}
+
+		resources := set.Resources
+		if len(resources.Requests) == 0 && len(resources.Limits) == 0 {
+			resources = es.Resources
+		}
+		storage := set.Storage
+		if storage.Size == "" {
+			storage.Size = es.Storage.Size
+		}
+		if storage.StorageClassName == "" {
+			storage.StorageClassName = storageClassName(efkStack.Spec.Global, es.Storage.StorageClassName)
+		}
+		if storage.Path == "" {
+			storage.Path = es.Storage.Path
+		}
+		nodeSelector := set.NodeSelector
+		if len(nodeSelector) == 0 {
+			nodeSelector = es.NodeSelector
+		}
+		tolerations := set.Tolerations
+		if len(tolerations) == 0 {
+			tolerations = es.Tolerations
+		}
+
+		roles := make([]string, 0, len(set.Roles))
+		for _, role := range set.Roles {
+			roles = append(roles, string(role))
+		}
+		value := map[string]interface{}{
+			"name":      set.Name,
+			"roles":     roles,
+			"replicas":  replicas[nodeSet.StatefulSet],
+			"resources": resourceValues(resources),
+			"javaOpts":  es.JavaOpts(resources),
+			"storage": map[string]interface{}{
+				"size":             storage.Size,
+				"storageClassName": storage.StorageClassName,
+				"path":             storage.Path,
+			},
+		}
+		if len(nodeSelector) > 0 {
+			value["nodeSelector"] = nodeSelector
+		}
+		if len(tolerations) > 0 {
+			value["tolerations"] = tolerations
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// resourceValues returns the Helm values of the resources of a container
+func resourceValues(resources corev1.ResourceRequirements) map[string]interface{} {
+	return map[string]interface{}{
+		"requests": map[string]interface{}{
+			"cpu":    resources.Requests.Cpu().String(),
+			"memory": resources.Requests.Memory().String(),
+		},
+		"limits": map[string]interface{}{
+			"cpu":    resources.Limits.Cpu().String(),
+			"memory": resources.Limits.Memory().String(),
+		},
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// updateElasticsearchReadiness derives the Elasticsearch state from the workload status
+// and the cluster health reported by the _cluster/health API
+func (r *EFKStackReconciler) updateElasticsearchReadiness(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	logger := log.FromContext(ctx)
+	esStatus := &efkStack.Status.Elasticsearch
+
+	// Le mode singleton est déployé en Deployment, le mode cluster en un StatefulSet par groupe de nœuds
+	var ready, desired int32
+	var err error
+	if efkStack.Spec.Elasticsearch.Mode == "singleton" {
+		name := types.NamespacedName{Name: fmt.Sprintf("%s-elasticsearch", efkStack.Name), Namespace: namespace}
+		ready, desired, err = r.deploymentReadiness(ctx, name)
+	} else {
+		// Les pods de chaque groupe indiquent au webhook si le groupe peut être retiré de la spec
+		var nodeSets []loggingv1.NodeSetStatus
+		for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+			statefulSet := &appsv1.StatefulSet{}
+			name := types.NamespacedName{Name: nodeSet.StatefulSet, Namespace: namespace}
+			if err = r.Get(ctx, name, statefulSet); err != nil {
+				break
+			}
+			ready += statefulSet.Status.ReadyReplicas
+			desired += statefulSetReplicas(statefulSet)
+			if nodeSet.Spec != nil {
+				nodeSets = append(nodeSets, loggingv1.NodeSetStatus{
+					Name:          nodeSet.Spec.Name,
+					Replicas:      statefulSet.Status.Replicas,
+					ReadyReplicas: statefulSet.Status.ReadyReplicas,
+				})
+			}
+		}
+		if err == nil {
+			esStatus.NodeSets = nodeSets
+		}
+	}
+	if err != nil {
+		esStatus.State = "Deploying"
+		esStatus.ReadyReplicas = 0
+		esStatus.Message = fmt.Sprintf("Waiting for workload: %v", err)
+		return
+	}
+	esStatus.ReadyReplicas = ready
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err == nil {
+		var health *elasticsearch.ClusterHealth
+		health, err = esClient.ClusterHealth(ctx)
+		if err == nil {
+			esStatus.Health = health.Status
+			esStatus.ActiveShards = health.ActiveShards
+			esStatus.UnassignedShards = health.UnassignedShards
+		}
+	}
+
+	switch {
+	case ready < desired:
+		esStatus.State = "NotReady"
+		esStatus.Message = fmt.Sprintf("%d/%d pods ready", ready, desired)
+	case err != nil:
+		// Des pods prêts ne suffisent pas : les appels à l'API de l'opérateur échoueraient aussi
+		logger.V(1).Info("Elasticsearch cluster health unavailable", "error", err)
+		esStatus.State = "NotReady"
+		esStatus.Health = ""
+		esStatus.Message = fmt.Sprintf("Cluster health unavailable: %v", err)
+	case esStatus.Health == elasticsearch.HealthRed:
+		esStatus.State = "NotReady"
+		esStatus.Message = fmt.Sprintf("Cluster health is red (%d unassigned shards)", esStatus.UnassignedShards)
+	default:
+		esStatus.State = "Ready"
+		esStatus.Message = ""
+	}
+}
+
+// updateFluentBitReadiness derives the Fluent Bit state from the DaemonSet status
+func (r *EFKStackReconciler) updateFluentBitReadiness(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	fbStatus := &efkStack.Status.FluentBit
+	daemonSet := &appsv1.DaemonSet{}
+	name := types.NamespacedName{Name: fmt.Sprintf("%s-fluentbit", efkStack.Name), Namespace: namespace}
+	if err := r.Get(ctx, name, daemonSet); err != nil {
+		fbStatus.State = "Deploying"
+		fbStatus.ReadyReplicas = 0
+		fbStatus.Message = fmt.Sprintf("Waiting for workload: %v", err)
+		return
+	}
+
+	ready := daemonSet.Status.NumberReady
+	desired := daemonSet.Status.DesiredNumberScheduled
+	fbStatus.ReadyReplicas = ready
+	if desired == 0 || ready < desired {
+		fbStatus.State = "NotReady"
+		fbStatus.Message = fmt.Sprintf("%d/%d pods ready", ready, desired)
+		return
+	}
+	fbStatus.State = "Ready"
+	fbStatus.Message = ""
+}
+
+// updateKibanaReadiness derives the Kibana state from the Deployment status
+func (r *EFKStackReconciler) updateKibanaReadiness(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	kbStatus := &efkStack.Status.Kibana
+	name := types.NamespacedName{Name: fmt.Sprintf("%s-kibana", efkStack.Name), Namespace: namespace}
+	ready, desired, err := r.deploymentReadiness(ctx, name)
+	if err != nil {
+		kbStatus.State = "Deploying"
+		kbStatus.ReadyReplicas = 0
+		kbStatus.Message = fmt.Sprintf("Waiting for workload: %v", err)
+		return
+	}
+
+	kbStatus.ReadyReplicas = ready
+	if ready < desired {
+		kbStatus.State = "NotReady"
+		kbStatus.Message = fmt.Sprintf("%d/%d pods ready", ready, desired)
+		return
+	}
+	kbStatus.State = "Ready"
+	kbStatus.Message = ""
+}
+
+// deploymentReadiness returns the ready and desired replicas of a Deployment
+func (r *EFKStackReconciler) deploymentReadiness(ctx context.Context, name types.NamespacedName) (int32, int32, error) {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, name, deployment); err != nil {
+		return 0, 0, err
+	}
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ReadyReplicas, desired, nil
+}
+
+// deploymentRolledOut returns true once every replica of a Deployment runs its last revision
+func (r *EFKStackReconciler) deploymentRolledOut(ctx context.Context, name types.NamespacedName) (bool, error) {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, name, deployment); err != nil {
+		return false, err
+	}
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == desired && status.Replicas == desired && status.AvailableReplicas == desired, nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// reconcileScaleDown returns the number of Elasticsearch replicas to deploy per StatefulSet. When the
+// spec removes nodes, the StatefulSets keep their current size until the departing nodes hold no shard
+// and are excluded from the voting configuration. The progress is recorded in status.elasticsearch.scaleDown
+// and in the ElasticsearchScalingDown condition
+func (r *EFKStackReconciler) reconcileScaleDown(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string, desired map[string]int32) map[string]int32 {
+	logger := log.FromContext(ctx)
+
+	var statefulSets []*appsv1.StatefulSet
+	for name := range desired {
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, statefulSet); err != nil {
+			// Groupe pas encore déployé : aucun nœud à retirer
+			continue
+		}
+		statefulSets = append(statefulSets, statefulSet)
+	}
+	if len(statefulSets) == 0 {
+		// Première installation : aucun nœud à retirer
+		return desired
+	}
+	sort.Slice(statefulSets, func(i, j int) bool {
+		return statefulSets[i].Name < statefulSets[j].Name
+	})
+
+	masters := map[string]bool{}
+	for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+		masters[nodeSet.StatefulSet] = nodeSet.Master
+	}
+
+	esStatus := &efkStack.Status.Elasticsearch
+	replicas := heldReplicas(statefulSets, desired)
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err == nil {
+		replicas, err = scaleDown(ctx, esClient, statefulSets, desired, masters, esStatus)
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionElasticsearchScalingDown,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+	}
+	switch {
+	case err != nil:
+		logger.Error(err, "Scale-down of Elasticsearch failed, retrying")
+		condition.Reason = "Failed"
+		condition.Message = err.Error()
+	case esStatus.ScaleDown != nil && esStatus.ScaleDown.RemainingShards > 0:
+		condition.Reason = "MigratingShards"
+		condition.Message = fmt.Sprintf("Moving %d shards off nodes %s",
+			esStatus.ScaleDown.RemainingShards, strings.Join(esStatus.ScaleDown.Nodes, ", "))
+	case esStatus.ScaleDown != nil:
+		condition.Reason = "RemovingNodes"
+		condition.Message = fmt.Sprintf("Removing nodes %s", strings.Join(esStatus.ScaleDown.Nodes, ", "))
+	case meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionElasticsearchScalingDown) != nil:
+		total := int32(0)
+		for _, count := range replicas {
+			total += count
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Completed"
+		condition.Message = fmt.Sprintf("Elasticsearch runs %d nodes", total)
+	default:
+		// Aucune réduction n'a jamais eu lieu
+		return replicas
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+
+	return replicas
+}
+
+// scaleDown performs the next step of the removal of the nodes beyond the desired replicas of each
+// StatefulSet and returns the replicas to deploy per StatefulSet: the current ones for the shrinking
+// StatefulSets while shards remain on the departing nodes, the desired ones afterwards. Only the
+// nodes of the master-eligible StatefulSets in masters are excluded from the voting configuration.
+// Once the StatefulSets are shrunk, the exclusions are lifted
+func scaleDown(ctx context.Context, esClient *elasticsearch.Client, statefulSets []*appsv1.StatefulSet, desired map[string]int32, masters map[string]bool, esStatus *loggingv1.ElasticsearchStatus) (map[string]int32, error) {
+	logger := log.FromContext(ctx)
+
+	// Le StatefulSet supprime les pods d'ordinal le plus élevé
+	var nodes, masterNodes []string
+	for _, statefulSet := range statefulSets {
+		target, ok := desired[statefulSet.Name]
+		if !ok {
+			continue
+		}
+		for i := target; i < statefulSetReplicas(statefulSet); i++ {
+			node := fmt.Sprintf("%s-%d", statefulSet.Name, i)
+			nodes = append(nodes, node)
+			if masters[statefulSet.Name] {
+				masterNodes = append(masterNodes, node)
+			}
+		}
+	}
+
+	if len(nodes) == 0 {
+		if esStatus.ScaleDown == nil {
+			return desired, nil
+		}
+		// Les nœuds retirés doivent avoir quitté le cluster avant de lever leurs exclusions
+		for _, statefulSet := range statefulSets {
+			if statefulSet.Status.Replicas > statefulSetReplicas(statefulSet) {
+				return desired, nil
+			}
+		}
+		logger.Info("Nodes removed, lifting allocation and voting exclusions", "nodes", esStatus.ScaleDown.Nodes)
+		if err := esClient.ClearVotingConfigExclusions(ctx); err != nil {
+			return desired, fmt.Errorf("failed to clear voting config exclusions: %w", err)
+		}
+		err := esClient.PutClusterSettings(ctx, &elasticsearch.ClusterSettings{
+			Persistent: map[string]interface{}{elasticsearch.AllocationExcludeNameSetting: nil},
+		})
+		if err != nil {
+			return desired, fmt.Errorf("failed to clear allocation exclusion: %w", err)
+		}
+		esStatus.ScaleDown = nil
+		return desired, nil
+	}
+
+	current := heldReplicas(statefulSets, desired)
+	if esStatus.ScaleDown == nil || strings.Join(esStatus.ScaleDown.Nodes, ",") != strings.Join(nodes, ",") {
+		now := metav1.Now()
+		esStatus.ScaleDown = &loggingv1.ScaleDownStatus{Nodes: nodes, StartTime: &now}
+	}
+
+	exclude := strings.Join(nodes, ",")
+	settings, err := esClient.GetClusterSettings(ctx)
+	if err != nil {
+		return current, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	if settings.Persistent[elasticsearch.AllocationExcludeNameSetting] != exclude {
+		logger.Info("Excluding departing nodes from shard allocation", "nodes", nodes)
+		err := esClient.PutClusterSettings(ctx, &elasticsearch.ClusterSettings{
+			Persistent: map[string]interface{}{elasticsearch.AllocationExcludeNameSetting: exclude},
+		})
+		if err != nil {
+			return current, fmt.Errorf("failed to exclude nodes from shard allocation: %w", err)
+		}
+	}
+
+	shards, err := esClient.Shards(ctx, nil)
+	if err != nil {
+		return current, fmt.Errorf("failed to list shards: %w", err)
+	}
+	departing := map[string]bool{}
+	for _, node := range nodes {
+		departing[node] = true
+	}
+	remaining := int32(0)
+	for i := range shards {
+		if departing[shards[i].NodeName()] {
+			remaining++
+		}
+	}
+	esStatus.ScaleDown.RemainingShards = remaining
+	if remaining > 0 {
+		return current, nil
+	}
+
+	// Sans exclusion, arrêter plusieurs nœuds éligibles master pourrait faire perdre le quorum.
+	// Elasticsearch refuse d'exclure les nœuds qui ne votent pas
+	if len(masterNodes) > 0 {
+		if err := esClient.AddVotingConfigExclusions(ctx, masterNodes); err != nil {
+			return current, fmt.Errorf("failed to exclude nodes from the voting configuration: %w", err)
+		}
+	}
+	logger.Info("Departing nodes drained, shrinking the StatefulSets", "nodes", nodes)
+	return desired, nil
+}
+
+// heldReplicas returns the desired replicas, except for the StatefulSets being shrunk
+// which keep their current replicas
+func heldReplicas(statefulSets []*appsv1.StatefulSet, desired map[string]int32) map[string]int32 {
+	replicas := make(map[string]int32, len(desired))
+	for name, count := range desired {
+		replicas[name] = count
+	}
+	for _, statefulSet := range statefulSets {
+		if count, ok := replicas[statefulSet.Name]; ok && statefulSetReplicas(statefulSet) > count {
+			replicas[statefulSet.Name] = statefulSetReplicas(statefulSet)
+		}
+	}
+	return replicas
+}
+
+// statefulSetReplicas returns the desired replicas of a StatefulSet
+func statefulSetReplicas(statefulSet *appsv1.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas != nil {
+		return *statefulSet.Spec.Replicas
+	}
+	return 1
+}
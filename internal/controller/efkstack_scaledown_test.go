@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Scale-down", func() {
+	var (
+		ctx         context.Context
+		server      *httptest.Server
+		esClient    *elasticsearch.Client
+		statefulSet *appsv1.StatefulSet
+		esStatus    *loggingv1.ElasticsearchStatus
+		exclude     interface{}
+		shards      []elasticsearch.Shard
+		voting      []string
+		timeouts    []string
+		masters     map[string]bool
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		exclude = nil
+		voting = nil
+		timeouts = nil
+		masters = map[string]bool{"test-efk-elasticsearch": true}
+		shards = []elasticsearch.Shard{
+			{Index: "logs", Shard: "0", PriRep: "p", State: elasticsearch.ShardStarted, Node: "test-efk-elasticsearch-0"},
+			{Index: "logs", Shard: "0", PriRep: "r", State: elasticsearch.ShardStarted, Node: "test-efk-elasticsearch-4"},
+			{Index: "logs", Shard: "1", PriRep: "p", State: elasticsearch.ShardStarted, Node: "test-efk-elasticsearch-3 -> 10.0.0.2 abc test-efk-elasticsearch-1"},
+		}
+
+		// Fake Elasticsearch keeping the allocation exclusion and the voting exclusions
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_cluster/settings", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				settings := &elasticsearch.ClusterSettings{}
+				Expect(json.NewDecoder(r.Body).Decode(settings)).To(Succeed())
+				exclude = settings.Persistent[elasticsearch.AllocationExcludeNameSetting]
+				return
+			}
+			persistent := map[string]interface{}{}
+			if exclude != nil {
+				persistent[elasticsearch.AllocationExcludeNameSetting] = exclude
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"persistent": persistent})).To(Succeed())
+		})
+		mux.HandleFunc("/_cat/shards", func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewEncoder(w).Encode(shards)).To(Succeed())
+		})
+		mux.HandleFunc("/_cluster/voting_config_exclusions", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				voting = nil
+				return
+			}
+			voting = append(voting, r.URL.Query().Get("node_names"))
+			timeouts = append(timeouts, r.URL.Query().Get("timeout"))
+		})
+		server = httptest.NewServer(mux)
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+
+		replicas := int32(5)
+		statefulSet = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch", Namespace: "logging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{Replicas: 5},
+		}
+		esStatus = &loggingv1.ElasticsearchStatus{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should scale up without draining", func() {
+		replicas, err := scaleDown(ctx, esClient, []*appsv1.StatefulSet{statefulSet}, map[string]int32{"test-efk-elasticsearch": 7}, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(HaveKeyWithValue("test-efk-elasticsearch", int32(7)))
+		Expect(esStatus.ScaleDown).To(BeNil())
+		Expect(exclude).To(BeNil())
+	})
+
+	It("Should keep the departing nodes until their shards have moved", func() {
+		desired := map[string]int32{"test-efk-elasticsearch": 3}
+		replicas, err := scaleDown(ctx, esClient, []*appsv1.StatefulSet{statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(HaveKeyWithValue("test-efk-elasticsearch", int32(5)))
+		Expect(exclude).To(Equal("test-efk-elasticsearch-3,test-efk-elasticsearch-4"))
+		Expect(esStatus.ScaleDown.Nodes).To(Equal([]string{"test-efk-elasticsearch-3", "test-efk-elasticsearch-4"}))
+		Expect(esStatus.ScaleDown.RemainingShards).To(Equal(int32(2)))
+		Expect(voting).To(BeEmpty())
+
+		// Shards have moved to the remaining nodes
+		shards = shards[:1]
+		replicas, err = scaleDown(ctx, esClient, []*appsv1.StatefulSet{statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(HaveKeyWithValue("test-efk-elasticsearch", int32(3)))
+		Expect(esStatus.ScaleDown.RemainingShards).To(BeZero())
+		Expect(voting).To(Equal([]string{"test-efk-elasticsearch-3,test-efk-elasticsearch-4"}))
+		Expect(timeouts).To(Equal([]string{"5s"}))
+
+		// The exclusions are lifted once the departing pods are gone
+		*statefulSet.Spec.Replicas = 3
+		replicas, err = scaleDown(ctx, esClient, []*appsv1.StatefulSet{statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(HaveKeyWithValue("test-efk-elasticsearch", int32(3)))
+		Expect(esStatus.ScaleDown).NotTo(BeNil())
+
+		statefulSet.Status.Replicas = 3
+		_, err = scaleDown(ctx, esClient, []*appsv1.StatefulSet{statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(esStatus.ScaleDown).To(BeNil())
+		Expect(exclude).To(BeNil())
+		Expect(voting).To(BeEmpty())
+	})
+
+	It("Should drain the departing nodes of every node set together", func() {
+		masterReplicas := int32(3)
+		masterSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-master", Namespace: "logging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &masterReplicas},
+			Status:     appsv1.StatefulSetStatus{Replicas: 3},
+		}
+		statefulSet.Name = "test-efk-elasticsearch-hot"
+		shards = []elasticsearch.Shard{
+			{Index: "logs", Shard: "0", PriRep: "p", State: elasticsearch.ShardStarted, Node: "test-efk-elasticsearch-hot-4"},
+		}
+		desired := map[string]int32{"test-efk-elasticsearch-master": 3, "test-efk-elasticsearch-hot": 4, "test-efk-elasticsearch-warm": 2}
+
+		masters = map[string]bool{"test-efk-elasticsearch-master": true}
+		replicas, err := scaleDown(ctx, esClient, []*appsv1.StatefulSet{masterSet, statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(Equal(map[string]int32{
+			"test-efk-elasticsearch-master": 3, "test-efk-elasticsearch-hot": 5, "test-efk-elasticsearch-warm": 2,
+		}))
+		Expect(exclude).To(Equal("test-efk-elasticsearch-hot-4"))
+		Expect(esStatus.ScaleDown.RemainingShards).To(Equal(int32(1)))
+
+		// Les nœuds de données ne sont pas exclus du vote
+		shards = nil
+		replicas, err = scaleDown(ctx, esClient, []*appsv1.StatefulSet{masterSet, statefulSet}, desired, masters, esStatus)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replicas).To(HaveKeyWithValue("test-efk-elasticsearch-hot", int32(4)))
+		Expect(voting).To(BeEmpty())
+	})
+})
@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// keystoreSyncDelay bounds the time taken by the kubelet to update the Secret volumes and by
+// the keystore-updater sidecar to rebuild the keystore of the nodes
+const keystoreSyncDelay = 2 * time.Minute
+
+// secureSettingsValues returns the keystore entries of spec.elasticsearch.secureSettings. A
+// source without entries adds every key of its Secret
+func secureSettingsValues(efkStack *loggingv1.EFKStack) []interface{} {
+	var keystore []interface{}
+	for _, source := range efkStack.Spec.Elasticsearch.SecureSettings {
+		if len(source.Entries) == 0 {
+			keystore = append(keystore, map[string]interface{}{"secretName": source.SecretName})
+			continue
+		}
+		for _, entry := range source.Entries {
+			keystore = append(keystore, map[string]interface{}{
+				"setting":    entry.SettingName(),
+				"secretName": source.SecretName,
+				"key":        entry.Key,
+			})
+		}
+	}
+	return keystore
+}
+
+// reconcileSecureSettings reloads the secure settings of the nodes once their Secrets changed
+// and reports it in status.elasticsearch.secureSettings and in the SecureSettingsReady condition.
+// It returns the delay before the reload is due, zero when no reload is pending
+func (r *EFKStackReconciler) reconcileSecureSettings(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) time.Duration {
+	esStatus := &efkStack.Status.Elasticsearch
+	if len(efkStack.Spec.Elasticsearch.SecureSettings) == 0 {
+		esStatus.SecureSettings = nil
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionSecureSettingsReady)
+		return 0
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionSecureSettingsReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: efkStack.Generation,
+	}
+	defer func() {
+		meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+	}()
+
+	hash, missing, err := r.secureSettingsHash(ctx, efkStack.Spec.Elasticsearch.SecureSettings, namespace)
+	if err != nil {
+		condition.Reason = "ReloadFailed"
+		condition.Message = err.Error()
+		return 0
+	}
+	now := time.Now()
+	if esStatus.SecureSettings == nil || esStatus.SecureSettings.Hash != hash {
+		status := &loggingv1.SecureSettingsStatus{Hash: hash, ChangeTime: &metav1.Time{Time: now}}
+		if esStatus.SecureSettings != nil {
+			status.LastReloadTime = esStatus.SecureSettings.LastReloadTime
+		}
+		esStatus.SecureSettings = status
+	}
+
+	// Avant l'échéance, l'appel ne rechargerait que l'ancien keystore
+	if wait := reloadDelay(esStatus.SecureSettings, now); wait > 0 {
+		condition.Reason = "Reloading"
+		condition.Message = fmt.Sprintf("Waiting %s for the nodes to rebuild their keystore", wait.Round(time.Second))
+		if len(missing) > 0 {
+			condition.Reason = "SecretNotFound"
+			condition.Message = fmt.Sprintf("Secret keys not found: %s", strings.Join(missing, ", "))
+		}
+		return wait
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	var reloaded bool
+	if err == nil {
+		reloaded, err = reloadSecureSettings(ctx, esClient, esStatus.SecureSettings, now)
+	}
+	switch {
+	case err != nil:
+		condition.Reason = "ReloadFailed"
+		condition.Message = err.Error()
+	case len(missing) > 0:
+		// Les volumes sont optionnels : les clés sont ajoutées au keystore dès leur création
+		condition.Reason = "SecretNotFound"
+		condition.Message = fmt.Sprintf("Secret keys not found: %s", strings.Join(missing, ", "))
+	case !reloaded:
+		condition.Reason = "Reloading"
+		condition.Message = "Waiting for the nodes to rebuild their keystore"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Reloaded"
+		condition.Message = "The secure settings are reloaded on every node"
+	}
+	return 0
+}
+
+// reloadDelay returns the time left before the reload of the secure settings is due, that is
+// keystoreSyncDelay after the change of the Secrets, as the operator cannot observe when the
+// nodes rebuilt their keystore
+func reloadDelay(status *loggingv1.SecureSettingsStatus, now time.Time) time.Duration {
+	due := status.ChangeTime.Add(keystoreSyncDelay)
+	if status.LastReloadTime != nil && !status.LastReloadTime.Time.Before(due) {
+		return 0
+	}
+	if wait := due.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// reloadSecureSettings calls the reload API once the reload is due and returns true when the
+// nodes reloaded the keystore rebuilt after the last change of the Secrets. A single call
+// happens per change, the failed ones being retried by the next reconciles
+func reloadSecureSettings(ctx context.Context, esClient *elasticsearch.Client, status *loggingv1.SecureSettingsStatus, now time.Time) (bool, error) {
+	due := status.ChangeTime.Add(keystoreSyncDelay)
+	if status.LastReloadTime != nil && !status.LastReloadTime.Time.Before(due) {
+		return true, nil
+	}
+	if now.Before(due) {
+		return false, nil
+	}
+	if err := esClient.ReloadSecureSettings(ctx); err != nil {
+		return false, fmt.Errorf("failed to reload the secure settings: %w", err)
+	}
+	log.FromContext(ctx).Info("Reloaded the secure settings")
+	status.LastReloadTime = &metav1.Time{Time: now}
+	return true, nil
+}
+
+// secureSettingsHash returns a hash of the content of the secure settings, and the Secrets and
+// keys that do not exist yet
+func (r *EFKStackReconciler) secureSettingsHash(ctx context.Context, sources []loggingv1.SecureSettingSource, namespace string) (string, []string, error) {
+	hasher := sha256.New()
+	var missing []string
+	for _, source := range sources {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: source.SecretName, Namespace: namespace}, secret)
+		if errors.IsNotFound(err) {
+			missing = append(missing, source.SecretName)
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get Secret %s: %w", source.SecretName, err)
+		}
+
+		entries := source.Entries
+		if len(entries) == 0 {
+			for key := range secret.Data {
+				entries = append(entries, loggingv1.SecureSettingEntry{Key: key})
+			}
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+		}
+		for _, entry := range entries {
+			data, ok := secret.Data[entry.Key]
+			if !ok {
+				missing = append(missing, source.SecretName+"/"+entry.Key)
+				continue
+			}
+			hasher.Write([]byte(entry.SettingName()))
+			hasher.Write([]byte{0})
+			hasher.Write(data)
+			hasher.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16], missing, nil
+}
+
+// mapSecureSettingsSecret returns the EFKStacks whose secure settings read the Secret
+func (r *EFKStackReconciler) mapSecureSettingsSecret(ctx context.Context, secret *corev1.Secret) []reconcile.Request {
+	efkStacks := &loggingv1.EFKStackList{}
+	if err := r.List(ctx, efkStacks, client.InNamespace(secret.Namespace)); err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to list EFKStacks when mapping Secret", "error", err, "secret", secret.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range efkStacks.Items {
+		for _, source := range efkStacks.Items[i].Spec.Elasticsearch.SecureSettings {
+			if source.SecretName == secret.Name {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&efkStacks.Items[i])})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Secure settings", func() {
+	var (
+		ctx        context.Context
+		efkStack   *loggingv1.EFKStack
+		reconciler *EFKStackReconciler
+		oidc       *corev1.Secret
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					SecureSettings: []loggingv1.SecureSettingSource{
+						{SecretName: "oidc-secrets"},
+						{SecretName: "backup-credentials", Entries: []loggingv1.SecureSettingEntry{
+							{Key: "access_key", Setting: "s3.client.backup.access_key"},
+							{Key: "secret_key", Setting: "s3.client.backup.secret_key"},
+						}},
+					},
+				},
+			},
+		}
+		oidc = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "oidc-secrets", Namespace: "logging"},
+			Data:       map[string][]byte{"xpack.security.authc.realms.oidc.oidc1.rp.client_secret": []byte("s3cr3t")},
+		}
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		_ = loggingv1.AddToScheme(scheme)
+		reconciler = &EFKStackReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(efkStack.DeepCopy(), oidc).Build()}
+	})
+
+	It("Should add the Secrets to the keystore", func() {
+		Expect(secureSettingsValues(efkStack)).To(Equal([]interface{}{
+			map[string]interface{}{"secretName": "oidc-secrets"},
+			map[string]interface{}{"setting": "s3.client.backup.access_key", "secretName": "backup-credentials", "key": "access_key"},
+			map[string]interface{}{"setting": "s3.client.backup.secret_key", "secretName": "backup-credentials", "key": "secret_key"},
+		}))
+	})
+
+	It("Should hash the content of the Secrets and report the missing ones", func() {
+		hash, missing, err := reconciler.secureSettingsHash(ctx, efkStack.Spec.Elasticsearch.SecureSettings, "logging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"backup-credentials"}))
+
+		Expect(reconciler.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-credentials", Namespace: "logging"},
+			Data:       map[string][]byte{"access_key": []byte("AKIA")},
+		})).To(Succeed())
+		created, missing, err := reconciler.secureSettingsHash(ctx, efkStack.Spec.Elasticsearch.SecureSettings, "logging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(Equal([]string{"backup-credentials/secret_key"}))
+		Expect(created).NotTo(Equal(hash))
+
+		oidc.Data["xpack.security.authc.realms.oidc.oidc1.rp.client_secret"] = []byte("rotated")
+		Expect(reconciler.Update(ctx, oidc)).To(Succeed())
+		rotated, _, err := reconciler.secureSettingsHash(ctx, efkStack.Spec.Elasticsearch.SecureSettings, "logging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).NotTo(Equal(created))
+	})
+
+	It("Should reload the secure settings once the nodes had time to rebuild their keystore", func() {
+		reloads := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_nodes/reload_secure_settings", func(w http.ResponseWriter, r *http.Request) {
+			reloads++
+			_, _ = w.Write([]byte(`{"nodes":{"a1":{"name":"test-efk-es-0"}}}`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		esClient := elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+
+		changed := time.Now()
+		status := &loggingv1.SecureSettingsStatus{Hash: "abc", ChangeTime: &metav1.Time{Time: changed}}
+		Expect(reloadDelay(status, changed.Add(30*time.Second))).To(Equal(keystoreSyncDelay - 30*time.Second))
+		reloaded, err := reloadSecureSettings(ctx, esClient, status, changed.Add(30*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeFalse())
+		Expect(reloads).To(BeZero())
+
+		Expect(reloadDelay(status, changed.Add(keystoreSyncDelay+time.Second))).To(BeZero())
+		reloaded, err = reloadSecureSettings(ctx, esClient, status, changed.Add(keystoreSyncDelay+time.Second))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeTrue())
+		Expect(reloads).To(Equal(1))
+
+		// Plus aucun rechargement jusqu'au prochain changement des Secrets
+		reloaded, err = reloadSecureSettings(ctx, esClient, status, changed.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeTrue())
+		Expect(reloads).To(Equal(1))
+
+		// A new change waits for a new window
+		status.ChangeTime = &metav1.Time{Time: changed.Add(time.Hour)}
+		Expect(reloadDelay(status, changed.Add(time.Hour))).To(Equal(keystoreSyncDelay))
+		reloaded, err = reloadSecureSettings(ctx, esClient, status, changed.Add(time.Hour+time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(BeFalse())
+		Expect(reloads).To(Equal(1))
+	})
+
+	It("Should enqueue the stacks reading a Secret", func() {
+		Expect(reconciler.mapSecretToEFKStack(ctx, oidc)).To(ConsistOf(
+			HaveField("NamespacedName.Name", "test-efk"),
+		))
+		Expect(reconciler.mapSecureSettingsSecret(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "logging"},
+		})).To(BeEmpty())
+	})
+})
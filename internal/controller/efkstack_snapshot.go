@@ -0,0 +1,394 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// snapshotClient is the name of the repository client configured in elasticsearch.yml and the keystore
+const snapshotClient = "default"
+
+// snapshotCredentials lists, per repository type, the Secret keys and the keystore settings they fill
+var snapshotCredentials = map[string][][2]string{
+	"s3": {
+		{loggingv1.S3AccessKeyKey, "s3.client." + snapshotClient + ".access_key"},
+		{loggingv1.S3SecretKeyKey, "s3.client." + snapshotClient + ".secret_key"},
+		{loggingv1.S3SessionTokenKey, "s3.client." + snapshotClient + ".session_token"},
+	},
+	"gcs": {
+		{loggingv1.GCSCredentialsKey, "gcs.client." + snapshotClient + ".credentials_file"},
+	},
+	"azure": {
+		{loggingv1.AzureAccountKey, "azure.client." + snapshotClient + ".account"},
+		{loggingv1.AzureKeyKey, "azure.client." + snapshotClient + ".key"},
+	},
+}
+
+// snapshotValues returns the Helm values mounting the snapshot repository on the Elasticsearch
+// nodes: credentials in the keystore, client settings and shared filesystem volume
+func (r *EFKStackReconciler) snapshotValues(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) map[string]interface{} {
+	values := map[string]interface{}{}
+	spec := efkStack.Spec.Elasticsearch.Snapshot
+	if spec == nil {
+		return values
+	}
+	repo := &spec.Repository
+
+	var env []interface{}
+	addEnv := func(name, value string) {
+		if value != "" {
+			env = append(env, map[string]interface{}{"name": name, "value": value})
+		}
+	}
+	if s3 := repo.S3; s3 != nil {
+		prefix := "s3.client." + snapshotClient + "."
+		addEnv(prefix+"endpoint", s3.Endpoint)
+		addEnv(prefix+"protocol", s3.Protocol)
+		addEnv(prefix+"region", s3.Region)
+		if s3.PathStyleAccess {
+			addEnv(prefix+"path_style_access", "true")
+		}
+	}
+	if fs := repo.FS; fs != nil {
+		mountPath := snapshotMountPath(fs)
+		addEnv("path.repo", mountPath)
+		values["snapshotVolume"] = map[string]interface{}{
+			"claimName": fs.ClaimName,
+			"mountPath": mountPath,
+		}
+	}
+	if len(env) > 0 {
+		values["extraEnv"] = env
+	}
+
+	if repo.CredentialsSecret != nil {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: repo.CredentialsSecret.Name, Namespace: namespace}, secret)
+		if err != nil {
+			// L'erreur est remontée par la condition SnapshotsReady, Elasticsearch reste déployé
+			log.FromContext(ctx).Info("Snapshot credentials not available", "secret", repo.CredentialsSecret.Name, "error", err)
+			return values
+		}
+
+		// Seules les clés présentes sont montées, un item manquant bloquerait le démarrage du pod
+		var keystore []interface{}
+		hasher := sha256.New()
+		for _, credential := range snapshotCredentials[repo.RepositoryType()] {
+			data, ok := secret.Data[credential[0]]
+			if !ok {
+				continue
+			}
+			keystore = append(keystore, map[string]interface{}{
+				"setting":    credential[1],
+				"secretName": secret.Name,
+				"key":        credential[0],
+			})
+			hasher.Write([]byte(credential[1]))
+			hasher.Write(data)
+		}
+		if len(keystore) > 0 {
+			values["keystore"] = keystore
+			// Le keystore n'est lu qu'au démarrage : un changement de credentials redémarre les pods
+			values["podAnnotations"] = map[string]interface{}{
+				"efk.crds.io/keystore-hash": hex.EncodeToString(hasher.Sum(nil))[:16],
+			}
+		}
+	}
+
+	return values
+}
+
+// snapshotMountPath returns the mount path of a shared filesystem repository
+func snapshotMountPath(fs *loggingv1.FSRepositorySpec) string {
+	if fs.MountPath == "" {
+		return loggingv1.DefaultSnapshotMountPath
+	}
+	return fs.MountPath
+}
+
+// reconcileSnapshots registers the snapshot repository, applies the SLM policies and reports
+// their last executions in the status
+func (r *EFKStackReconciler) reconcileSnapshots(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	logger := log.FromContext(ctx)
+	spec := efkStack.Spec.Elasticsearch.Snapshot
+	esStatus := &efkStack.Status.Elasticsearch
+
+	if spec == nil && esStatus.Snapshot == nil {
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionSnapshotsReady)
+		return
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err != nil {
+		setSnapshotsCondition(efkStack, metav1.ConditionFalse, "ApplyFailed", err.Error())
+		return
+	}
+
+	if spec == nil {
+		// Arrêter les snapshots planifiés ; le dépôt et ses snapshots sont conservés
+		remaining := deleteSnapshotPolicies(ctx, esClient, esStatus.Snapshot.Policies, nil)
+		if len(remaining) > 0 {
+			esStatus.Snapshot.Policies = remaining
+			setSnapshotsCondition(efkStack, metav1.ConditionFalse, "ApplyFailed", "Failed to delete SLM policies")
+			return
+		}
+		esStatus.Snapshot = nil
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionSnapshotsReady)
+		return
+	}
+
+	repo := &spec.Repository
+	if repo.CredentialsSecret != nil {
+		err := r.Get(ctx, types.NamespacedName{Name: repo.CredentialsSecret.Name, Namespace: namespace}, &corev1.Secret{})
+		if errors.IsNotFound(err) {
+			setSnapshotsCondition(efkStack, metav1.ConditionFalse, "CredentialsNotFound",
+				fmt.Sprintf("Secret %s not found", repo.CredentialsSecret.Name))
+			return
+		}
+	}
+
+	if err := ensureSnapshotRepository(ctx, esClient, repo); err != nil {
+		logger.Error(err, "Failed to register snapshot repository", "repository", repo.RepositoryName())
+		setSnapshotsCondition(efkStack, metav1.ConditionFalse, "RepositoryFailed", err.Error())
+		return
+	}
+
+	var previous []loggingv1.SnapshotPolicyStatus
+	if esStatus.Snapshot != nil {
+		previous = esStatus.Snapshot.Policies
+	}
+	status, failed := syncSnapshotPolicies(ctx, esClient, spec, previous)
+	esStatus.Snapshot = status
+
+	switch {
+	case len(failed) > 0:
+		setSnapshotsCondition(efkStack, metav1.ConditionFalse, "PolicyFailed", fmt.Sprintf("Failed SLM policies: %s", strings.Join(failed, ", ")))
+	default:
+		setSnapshotsCondition(efkStack, metav1.ConditionTrue, "Applied",
+			fmt.Sprintf("Repository %s and %d SLM policies are applied", repo.RepositoryName(), len(spec.Policies)))
+	}
+}
+
+func setSnapshotsCondition(efkStack *loggingv1.EFKStack, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&efkStack.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionSnapshotsReady,
+		Status:             status,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// snapshotRepository builds the repository registration from the spec
+func snapshotRepository(repo *loggingv1.SnapshotRepositorySpec) *elasticsearch.SnapshotRepository {
+	settings := map[string]interface{}{}
+	setIfNotEmpty := func(name, value string) {
+		if value != "" {
+			settings[name] = value
+		}
+	}
+	switch {
+	case repo.S3 != nil:
+		settings["bucket"] = repo.S3.Bucket
+		settings["client"] = snapshotClient
+		setIfNotEmpty("base_path", repo.S3.BasePath)
+	case repo.GCS != nil:
+		settings["bucket"] = repo.GCS.Bucket
+		settings["client"] = snapshotClient
+		setIfNotEmpty("base_path", repo.GCS.BasePath)
+	case repo.Azure != nil:
+		settings["container"] = repo.Azure.Container
+		settings["client"] = snapshotClient
+		setIfNotEmpty("base_path", repo.Azure.BasePath)
+	case repo.FS != nil:
+		settings["location"] = snapshotMountPath(repo.FS)
+	}
+	return &elasticsearch.SnapshotRepository{Type: repo.RepositoryType(), Settings: settings}
+}
+
+// ensureSnapshotRepository registers the repository when it is missing or drifted
+func ensureSnapshotRepository(ctx context.Context, esClient *elasticsearch.Client, repo *loggingv1.SnapshotRepositorySpec) error {
+	desired := snapshotRepository(repo)
+	current, err := esClient.GetSnapshotRepository(ctx, repo.RepositoryName())
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return fmt.Errorf("failed to get snapshot repository: %w", err)
+	}
+	if err == nil {
+		inSync, err := elasticsearch.Contains(current, desired)
+		if err != nil || inSync {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("Registering snapshot repository", "repository", repo.RepositoryName(), "type", desired.Type)
+	if err := esClient.PutSnapshotRepository(ctx, repo.RepositoryName(), desired); err != nil {
+		return fmt.Errorf("failed to register snapshot repository: %w", err)
+	}
+	return nil
+}
+
+// slmPolicy builds the SLM policy from the spec
+func slmPolicy(policy loggingv1.SnapshotPolicySpec, repository string) *elasticsearch.SLMPolicy {
+	snapshotName := policy.SnapshotName
+	if snapshotName == "" {
+		snapshotName = fmt.Sprintf("<%s-{now/d}>", policy.Name)
+	}
+
+	config := map[string]interface{}{"include_global_state": policy.IncludeGlobalState}
+	if len(policy.Indices) > 0 {
+		config["indices"] = policy.Indices
+	}
+
+	var retention map[string]interface{}
+	if policy.Retention != nil {
+		retention = map[string]interface{}{}
+		if policy.Retention.ExpireAfter != "" {
+			retention["expire_after"] = policy.Retention.ExpireAfter
+		}
+		if policy.Retention.MinCount > 0 {
+			retention["min_count"] = policy.Retention.MinCount
+		}
+		if policy.Retention.MaxCount > 0 {
+			retention["max_count"] = policy.Retention.MaxCount
+		}
+	}
+
+	return &elasticsearch.SLMPolicy{
+		Name:       snapshotName,
+		Schedule:   policy.Schedule,
+		Repository: repository,
+		Config:     config,
+		Retention:  retention,
+	}
+}
+
+// syncSnapshotPolicies applies the SLM policies, deletes the ones removed from the spec
+// and returns the snapshot status with the names of the policies that could not be applied
+func syncSnapshotPolicies(ctx context.Context, esClient *elasticsearch.Client, spec *loggingv1.SnapshotSpec, previous []loggingv1.SnapshotPolicyStatus) (*loggingv1.SnapshotStatus, []string) {
+	logger := log.FromContext(ctx)
+	repository := spec.Repository.RepositoryName()
+	status := &loggingv1.SnapshotStatus{Repository: repository}
+	var failed []string
+
+	for _, policy := range spec.Policies {
+		policyStatus := loggingv1.SnapshotPolicyStatus{Name: policy.Name}
+		info, err := ensureSLMPolicy(ctx, esClient, policy.Name, slmPolicy(policy, repository))
+		if err != nil {
+			logger.Error(err, "Failed to apply SLM policy", "policy", policy.Name)
+			failed = append(failed, policy.Name)
+			policyStatus.Message = err.Error()
+			status.Policies = append(status.Policies, policyStatus)
+			continue
+		}
+
+		if success := info.LastSuccess; success != nil {
+			successTime := metav1.NewTime(success.Timestamp())
+			policyStatus.LastSuccessSnapshot = success.SnapshotName
+			policyStatus.LastSuccessTime = &successTime
+			if status.LastSuccessTime == nil || status.LastSuccessTime.Before(&successTime) {
+				status.LastSuccessTime = &successTime
+			}
+		}
+		if failure := info.LastFailure; failure != nil {
+			failureTime := metav1.NewTime(failure.Timestamp())
+			policyStatus.LastFailureTime = &failureTime
+			// Un échec suivi d'un succès n'est plus d'actualité
+			if info.LastSuccess == nil || failure.Time > info.LastSuccess.Time {
+				policyStatus.Message = failure.Details
+			}
+		}
+		status.Policies = append(status.Policies, policyStatus)
+	}
+
+	status.Policies = append(status.Policies, deleteSnapshotPolicies(ctx, esClient, previous, spec.Policies)...)
+	sort.SliceStable(status.Policies, func(i, j int) bool { return status.Policies[i].Name < status.Policies[j].Name })
+	return status, failed
+}
+
+// ensureSLMPolicy writes the SLM policy when it is missing or drifted, and returns it
+// with its last executions
+func ensureSLMPolicy(ctx context.Context, esClient *elasticsearch.Client, id string, desired *elasticsearch.SLMPolicy) (*elasticsearch.SLMPolicyInfo, error) {
+	info, err := esClient.GetSLMPolicy(ctx, id)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get SLM policy: %w", err)
+	}
+	if err == nil {
+		// Comparaison dans les deux sens : Elasticsearch renvoie la politique telle qu'elle a été écrite
+		inSync, err := elasticsearch.Contains(&info.Policy, desired)
+		if err != nil {
+			return nil, err
+		}
+		if inSync {
+			if inSync, err = elasticsearch.Contains(desired, &info.Policy); err != nil {
+				return nil, err
+			}
+		}
+		if inSync {
+			return info, nil
+		}
+	}
+
+	log.FromContext(ctx).Info("Applying SLM policy", "policy", id)
+	if err := esClient.PutSLMPolicy(ctx, id, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply SLM policy: %w", err)
+	}
+	if info == nil {
+		return &elasticsearch.SLMPolicyInfo{Policy: *desired}, nil
+	}
+	info.Policy = *desired
+	return info, nil
+}
+
+// deleteSnapshotPolicies deletes the policies of the previous status that are no longer declared
+// and returns the status of those that could not be deleted
+func deleteSnapshotPolicies(ctx context.Context, esClient *elasticsearch.Client, previous []loggingv1.SnapshotPolicyStatus, declared []loggingv1.SnapshotPolicySpec) []loggingv1.SnapshotPolicyStatus {
+	logger := log.FromContext(ctx)
+	names := map[string]bool{}
+	for _, policy := range declared {
+		names[policy.Name] = true
+	}
+
+	var remaining []loggingv1.SnapshotPolicyStatus
+	for _, policy := range previous {
+		if names[policy.Name] {
+			continue
+		}
+		if err := esClient.DeleteSLMPolicy(ctx, policy.Name); err != nil {
+			logger.Error(err, "Failed to delete SLM policy", "policy", policy.Name)
+			policy.Message = fmt.Sprintf("failed to delete: %v", err)
+			remaining = append(remaining, policy)
+			continue
+		}
+		logger.Info("Deleted SLM policy", "policy", policy.Name)
+	}
+	return remaining
+}
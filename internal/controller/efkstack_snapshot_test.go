@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Snapshots", func() {
+	var (
+		ctx      context.Context
+		efkStack *loggingv1.EFKStack
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					Snapshot: &loggingv1.SnapshotSpec{
+						Repository: loggingv1.SnapshotRepositorySpec{
+							S3: &loggingv1.S3RepositorySpec{
+								Bucket:          "backups",
+								Endpoint:        "minio.minio.svc:9000",
+								Protocol:        "http",
+								PathStyleAccess: true,
+							},
+							CredentialsSecret: &corev1.LocalObjectReference{Name: "minio-credentials"},
+						},
+						Policies: []loggingv1.SnapshotPolicySpec{{
+							Name:      "nightly",
+							Schedule:  "0 30 1 * * ?",
+							Retention: &loggingv1.SnapshotRetentionSpec{ExpireAfter: "30d", MaxCount: 30},
+						}},
+					},
+				},
+			},
+		}
+	})
+
+	It("Should mount the repository credentials in the keystore", func() {
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "minio-credentials", Namespace: "logging"},
+			Data: map[string][]byte{
+				loggingv1.S3AccessKeyKey: []byte("minio"),
+				loggingv1.S3SecretKeyKey: []byte("minio123"),
+			},
+		}
+		reconciler := &EFKStackReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()}
+
+		values := reconciler.snapshotValues(ctx, efkStack, "logging")
+
+		Expect(values["keystore"]).To(ConsistOf(
+			map[string]interface{}{"setting": "s3.client.default.access_key", "secretName": "minio-credentials", "key": "access_key"},
+			map[string]interface{}{"setting": "s3.client.default.secret_key", "secretName": "minio-credentials", "key": "secret_key"},
+		))
+		Expect(values["extraEnv"]).To(ContainElement(map[string]interface{}{"name": "s3.client.default.endpoint", "value": "minio.minio.svc:9000"}))
+		Expect(values["extraEnv"]).To(ContainElement(map[string]interface{}{"name": "s3.client.default.path_style_access", "value": "true"}))
+		Expect(values).To(HaveKey("podAnnotations"))
+	})
+
+	It("Should mount a shared filesystem repository", func() {
+		efkStack.Spec.Elasticsearch.Snapshot.Repository = loggingv1.SnapshotRepositorySpec{
+			FS: &loggingv1.FSRepositorySpec{ClaimName: "es-snapshots"},
+		}
+		reconciler := &EFKStackReconciler{}
+
+		values := reconciler.snapshotValues(ctx, efkStack, "logging")
+
+		Expect(values["snapshotVolume"]).To(Equal(map[string]interface{}{
+			"claimName": "es-snapshots",
+			"mountPath": loggingv1.DefaultSnapshotMountPath,
+		}))
+		Expect(values["extraEnv"]).To(ContainElement(map[string]interface{}{"name": "path.repo", "value": loggingv1.DefaultSnapshotMountPath}))
+		Expect(snapshotRepository(&efkStack.Spec.Elasticsearch.Snapshot.Repository).Settings).To(
+			Equal(map[string]interface{}{"location": loggingv1.DefaultSnapshotMountPath}))
+	})
+
+	Context("With a fake Elasticsearch", func() {
+		var (
+			server   *httptest.Server
+			esClient *elasticsearch.Client
+			objects  map[string]interface{}
+			writes   []string
+		)
+
+		BeforeEach(func() {
+			objects = map[string]interface{}{}
+			writes = nil
+
+			mux := http.NewServeMux()
+			handle := func(prefix string) {
+				mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+					name := strings.TrimPrefix(r.URL.Path, prefix)
+					switch r.Method {
+					case http.MethodPut:
+						var body map[string]interface{}
+						Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+						if prefix == "/_slm/policy/" {
+							body = map[string]interface{}{
+								"policy":       body,
+								"last_success": map[string]interface{}{"snapshot_name": "nightly-2025.01.02-abc", "time": 1735783200000},
+								"last_failure": map[string]interface{}{"snapshot_name": "nightly-2025.01.01-abc", "time": 1735696800000, "details": "repository unavailable"},
+							}
+						}
+						objects[r.URL.Path] = body
+						writes = append(writes, "PUT "+r.URL.Path)
+					case http.MethodDelete:
+						delete(objects, r.URL.Path)
+						writes = append(writes, "DELETE "+r.URL.Path)
+					default:
+						body, ok := objects[r.URL.Path]
+						if !ok {
+							w.WriteHeader(http.StatusNotFound)
+							return
+						}
+						Expect(json.NewEncoder(w).Encode(map[string]interface{}{name: body})).To(Succeed())
+					}
+				})
+			}
+			handle("/_snapshot/")
+			handle("/_slm/policy/")
+			server = httptest.NewServer(mux)
+			esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Should register the repository and the policies once", func() {
+			spec := efkStack.Spec.Elasticsearch.Snapshot
+			Expect(ensureSnapshotRepository(ctx, esClient, &spec.Repository)).To(Succeed())
+			status, failed := syncSnapshotPolicies(ctx, esClient, spec, nil)
+			Expect(failed).To(BeEmpty())
+			Expect(writes).To(Equal([]string{"PUT /_snapshot/backups", "PUT /_slm/policy/nightly"}))
+			Expect(objects["/_snapshot/backups"]).To(Equal(map[string]interface{}{
+				"type":     "s3",
+				"settings": map[string]interface{}{"bucket": "backups", "client": "default"},
+			}))
+
+			writes = nil
+			Expect(ensureSnapshotRepository(ctx, esClient, &spec.Repository)).To(Succeed())
+			status, _ = syncSnapshotPolicies(ctx, esClient, spec, status.Policies)
+			Expect(writes).To(BeEmpty())
+
+			Expect(status.Repository).To(Equal("backups"))
+			Expect(status.LastSuccessTime).NotTo(BeNil())
+			Expect(status.Policies).To(HaveLen(1))
+			Expect(status.Policies[0].LastSuccessSnapshot).To(Equal("nightly-2025.01.02-abc"))
+			Expect(status.Policies[0].LastFailureTime).NotTo(BeNil())
+			// The failure happened before the last success
+			Expect(status.Policies[0].Message).To(BeEmpty())
+		})
+
+		It("Should update a drifted policy and delete the removed ones", func() {
+			spec := efkStack.Spec.Elasticsearch.Snapshot
+			status, _ := syncSnapshotPolicies(ctx, esClient, spec, nil)
+
+			spec.Policies[0].Retention = nil
+			writes = nil
+			status, _ = syncSnapshotPolicies(ctx, esClient, spec, status.Policies)
+			Expect(writes).To(Equal([]string{"PUT /_slm/policy/nightly"}))
+
+			spec.Policies = nil
+			writes = nil
+			status, _ = syncSnapshotPolicies(ctx, esClient, spec, status.Policies)
+			Expect(writes).To(Equal([]string{"DELETE /_slm/policy/nightly"}))
+			Expect(status.Policies).To(BeEmpty())
+		})
+	})
+
+	It("Should report a missing credentials Secret", func() {
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		reconciler := &EFKStackReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+		reconciler.reconcileSnapshots(ctx, efkStack, "logging")
+
+		condition := meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionSnapshotsReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("CredentialsNotFound"))
+	})
+})
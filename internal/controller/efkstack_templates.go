@@ -0,0 +1,259 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// Template states reported in the status
+const (
+	templateStateApplied = "Applied"
+	templateStateError   = "Error"
+)
+
+// reconcileTemplates applies the component and index templates declared on the stack,
+// deletes the ones removed from the spec and reports each of them in the status
+func (r *EFKStackReconciler) reconcileTemplates(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	spec := &efkStack.Spec.Elasticsearch
+	esStatus := &efkStack.Status.Elasticsearch
+	if len(spec.ComponentTemplates) == 0 && len(spec.IndexTemplates) == 0 && len(esStatus.Templates) == 0 {
+		meta.RemoveStatusCondition(&efkStack.Status.Conditions, loggingv1.ConditionTemplatesReady)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionTemplatesReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             "Applied",
+		Message:            "All templates are applied",
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, namespace)
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ApplyFailed"
+		condition.Message = err.Error()
+		meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+		return
+	}
+
+	esStatus.Templates = syncTemplates(ctx, esClient, spec, esStatus.Templates)
+
+	var failed []string
+	for _, status := range esStatus.Templates {
+		if status.State != templateStateApplied {
+			failed = append(failed, fmt.Sprintf("%s %s", status.Kind, status.Name))
+		}
+	}
+	if len(failed) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ApplyFailed"
+		condition.Message = fmt.Sprintf("Failed templates: %s", strings.Join(failed, ", "))
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+}
+
+// syncTemplates applies the component templates, then the index templates composing them,
+// then deletes the templates of the previous status that are no longer declared
+func syncTemplates(ctx context.Context, esClient *elasticsearch.Client, spec *loggingv1.ElasticsearchSpec, previous []loggingv1.TemplateStatus) []loggingv1.TemplateStatus {
+	logger := log.FromContext(ctx)
+	now := metav1.Now()
+
+	lastApplied := map[string]*metav1.Time{}
+	for _, status := range previous {
+		lastApplied[status.Kind+"/"+status.Name] = status.LastAppliedTime
+	}
+
+	var statuses []loggingv1.TemplateStatus
+	declared := map[string]bool{}
+	record := func(kind, name string, applied bool, err error) {
+		key := kind + "/" + name
+		declared[key] = true
+		status := loggingv1.TemplateStatus{Name: name, Kind: kind, State: templateStateApplied, LastAppliedTime: lastApplied[key]}
+		switch {
+		case err != nil:
+			logger.Error(err, "Failed to apply template", "kind", kind, "name", name)
+			status.State = templateStateError
+			status.Message = err.Error()
+		case applied:
+			logger.Info("Applied template", "kind", kind, "name", name)
+			status.LastAppliedTime = &now
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, component := range spec.ComponentTemplates {
+		applied, err := ensureComponentTemplate(ctx, esClient, component)
+		record(loggingv1.TemplateKindComponent, component.Name, applied, err)
+	}
+	for _, template := range spec.IndexTemplates {
+		applied, err := ensureIndexTemplate(ctx, esClient, template)
+		record(loggingv1.TemplateKindIndex, template.Name, applied, err)
+	}
+
+	// Les index templates sont supprimés avant les component templates qu'ils référencent
+	for _, kind := range []string{loggingv1.TemplateKindIndex, loggingv1.TemplateKindComponent} {
+		for _, status := range previous {
+			if status.Kind != kind || declared[kind+"/"+status.Name] {
+				continue
+			}
+			var err error
+			if kind == loggingv1.TemplateKindIndex {
+				err = esClient.DeleteIndexTemplate(ctx, status.Name)
+			} else {
+				err = esClient.DeleteComponentTemplate(ctx, status.Name)
+			}
+			if err != nil {
+				// Conserver l'entrée pour retenter la suppression au prochain cycle
+				logger.Error(err, "Failed to delete template", "kind", kind, "name", status.Name)
+				status.State = templateStateError
+				status.Message = fmt.Sprintf("failed to delete: %v", err)
+				statuses = append(statuses, status)
+				continue
+			}
+			logger.Info("Deleted template", "kind", kind, "name", status.Name)
+		}
+	}
+
+	return statuses
+}
+
+// ensureComponentTemplate writes the component template when it is missing or drifted
+func ensureComponentTemplate(ctx context.Context, esClient *elasticsearch.Client, spec loggingv1.ComponentTemplateSpec) (bool, error) {
+	body, err := templateBody(spec.Template)
+	if err != nil {
+		return false, err
+	}
+	desired := &elasticsearch.ComponentTemplate{Template: body}
+	desired.Meta, err = managedMeta(desired)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := esClient.GetComponentTemplate(ctx, spec.Name)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get component template: %w", err)
+	}
+	if err == nil {
+		if inSync, err := elasticsearch.Contains(current, desired); err != nil || inSync {
+			return false, err
+		}
+	}
+	if err := esClient.PutComponentTemplate(ctx, spec.Name, desired); err != nil {
+		return false, fmt.Errorf("failed to apply component template: %w", err)
+	}
+	return true, nil
+}
+
+// ensureIndexTemplate writes the index template when it is missing or drifted
+func ensureIndexTemplate(ctx context.Context, esClient *elasticsearch.Client, spec loggingv1.IndexTemplateSpec) (bool, error) {
+	body, err := templateBody(spec.Template)
+	if err != nil {
+		return false, err
+	}
+	desired := &elasticsearch.IndexTemplate{
+		IndexPatterns: spec.IndexPatterns,
+		ComposedOf:    spec.ComposedOf,
+		Priority:      spec.Priority,
+	}
+	if body.Settings != nil || body.Mappings != nil {
+		desired.Template = &body
+	}
+	if spec.DataStream {
+		desired.DataStream = &elasticsearch.DataStreamTemplate{}
+	}
+	desired.Meta, err = managedMeta(desired)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := esClient.GetIndexTemplate(ctx, spec.Name)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get index template: %w", err)
+	}
+	if err == nil {
+		if inSync, err := elasticsearch.Contains(current, desired); err != nil || inSync {
+			return false, err
+		}
+	}
+	if err := esClient.PutIndexTemplate(ctx, spec.Name, desired); err != nil {
+		return false, fmt.Errorf("failed to apply index template: %w", err)
+	}
+	return true, nil
+}
+
+// templateBody converts the typed settings and the mappings into the Elasticsearch template body
+func templateBody(spec loggingv1.TemplateBodySpec) (elasticsearch.TemplateBody, error) {
+	body := elasticsearch.TemplateBody{}
+
+	settings := map[string]interface{}{}
+	for name, value := range spec.Settings {
+		// Elasticsearch préfixe les settings d'index par "index."
+		if !strings.HasPrefix(name, "index.") {
+			name = "index." + name
+		}
+		settings[name] = value
+	}
+	if spec.Shards != nil {
+		settings["index.number_of_shards"] = *spec.Shards
+	}
+	if spec.Replicas != nil {
+		settings["index.number_of_replicas"] = *spec.Replicas
+	}
+	if spec.TotalFieldsLimit != nil {
+		settings["index.mapping.total_fields.limit"] = *spec.TotalFieldsLimit
+	}
+	if len(settings) > 0 {
+		body.Settings = elasticsearch.ExpandSettings(settings)
+	}
+
+	if spec.Mappings != nil && len(spec.Mappings.Raw) > 0 {
+		if err := json.Unmarshal(spec.Mappings.Raw, &body.Mappings); err != nil {
+			return body, fmt.Errorf("invalid mappings: %w", err)
+		}
+	}
+
+	return body, nil
+}
+
+// managedMeta returns the _meta of a managed template. The hash of the definition lets
+// elasticsearch.Contains detect the fields removed from the spec
+func managedMeta(template interface{}) (map[string]interface{}, error) {
+	payload, err := json.Marshal(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return map[string]interface{}{
+		"managed_by": managedByMeta["managed_by"],
+		"hash":       hex.EncodeToString(sum[:8]),
+	}, nil
+}
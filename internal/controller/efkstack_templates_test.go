@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Templates", func() {
+	var (
+		ctx       context.Context
+		server    *httptest.Server
+		esClient  *elasticsearch.Client
+		templates map[string]interface{}
+		writes    []string
+		spec      *loggingv1.ElasticsearchSpec
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		templates = map[string]interface{}{}
+		writes = nil
+		shards := int32(1)
+		spec = &loggingv1.ElasticsearchSpec{
+			ComponentTemplates: []loggingv1.ComponentTemplateSpec{{
+				Name: "logs-mappings",
+				Template: loggingv1.TemplateBodySpec{
+					Shards:   &shards,
+					Settings: map[string]string{"refresh_interval": "30s"},
+					Mappings: &runtime.RawExtension{Raw: []byte(`{"dynamic":false,"properties":{"message":{"type":"text"}}}`)},
+				},
+			}},
+			IndexTemplates: []loggingv1.IndexTemplateSpec{{
+				Name:          "app-logs",
+				IndexPatterns: []string{"app-*"},
+				ComposedOf:    []string{"logs-mappings"},
+				Priority:      150,
+			}},
+		}
+
+		// Fake Elasticsearch returning the settings as nested strings, like the real API
+		handle := func(w http.ResponseWriter, r *http.Request, listKey, itemKey string) {
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			switch r.Method {
+			case http.MethodPut:
+				var body interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				payload, _ := json.Marshal(body)
+				payload = []byte(strings.ReplaceAll(string(payload), `"number_of_shards":1`, `"number_of_shards":"1"`))
+				Expect(json.Unmarshal(payload, &body)).To(Succeed())
+				templates[r.URL.Path] = body
+				writes = append(writes, "PUT "+r.URL.Path)
+			case http.MethodDelete:
+				delete(templates, r.URL.Path)
+				writes = append(writes, "DELETE "+r.URL.Path)
+			default:
+				body, ok := templates[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+					listKey: []interface{}{map[string]interface{}{"name": name, itemKey: body}},
+				})).To(Succeed())
+			}
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_component_template/", func(w http.ResponseWriter, r *http.Request) {
+			handle(w, r, "component_templates", "component_template")
+		})
+		mux.HandleFunc("/_index_template/", func(w http.ResponseWriter, r *http.Request) {
+			handle(w, r, "index_templates", "index_template")
+		})
+		server = httptest.NewServer(mux)
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should apply the templates once and report them", func() {
+		statuses := syncTemplates(ctx, esClient, spec, nil)
+		Expect(writes).To(Equal([]string{"PUT /_component_template/logs-mappings", "PUT /_index_template/app-logs"}))
+		Expect(statuses).To(HaveLen(2))
+		for _, status := range statuses {
+			Expect(status.State).To(Equal(templateStateApplied))
+			Expect(status.LastAppliedTime).NotTo(BeNil())
+		}
+
+		settings := templates["/_component_template/logs-mappings"].(map[string]interface{})["template"].(map[string]interface{})["settings"]
+		Expect(settings).To(Equal(map[string]interface{}{
+			"index": map[string]interface{}{"number_of_shards": "1", "refresh_interval": "30s"},
+		}))
+
+		writes = nil
+		statuses = syncTemplates(ctx, esClient, spec, statuses)
+		Expect(writes).To(BeEmpty())
+		Expect(statuses).To(HaveLen(2))
+	})
+
+	It("Should correct drift and delete the templates removed from the spec", func() {
+		statuses := syncTemplates(ctx, esClient, spec, nil)
+
+		// Removing a field of the spec changes the hash stored in _meta
+		spec.ComponentTemplates[0].Template.Settings = nil
+		writes = nil
+		statuses = syncTemplates(ctx, esClient, spec, statuses)
+		Expect(writes).To(Equal([]string{"PUT /_component_template/logs-mappings"}))
+
+		spec.IndexTemplates = nil
+		spec.ComponentTemplates = nil
+		writes = nil
+		statuses = syncTemplates(ctx, esClient, spec, statuses)
+		Expect(writes).To(Equal([]string{"DELETE /_index_template/app-logs", "DELETE /_component_template/logs-mappings"}))
+		Expect(statuses).To(BeEmpty())
+	})
+
+	It("Should report the templates that cannot be applied", func() {
+		spec.IndexTemplates[0].Template.Mappings = &runtime.RawExtension{Raw: []byte(`[]`)}
+
+		statuses := syncTemplates(ctx, esClient, spec, nil)
+		Expect(statuses[1].State).To(Equal(templateStateError))
+		Expect(statuses[1].Message).To(ContainSubstring("invalid mappings"))
+	})
+})
@@ -0,0 +1,281 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// Reasons of the ElasticsearchUpgrading condition
+const (
+	upgradeReasonUpToDate         = "UpToDate"
+	upgradeReasonRestartingNode   = "RestartingNode"
+	upgradeReasonWaitingForNode   = "WaitingForNode"
+	upgradeReasonWaitingForHealth = "WaitingForHealth"
+	upgradeReasonFailed           = "Failed"
+	upgradeReasonUnsupported      = "UnsupportedUpgrade"
+)
+
+// upgradeProgress is the outcome of a step of a rolling upgrade
+type upgradeProgress struct {
+	// Done is true when every node runs the last revision and shard allocation is enabled
+	Done bool
+	// Reason and Message describe the current step
+	Reason  string
+	Message string
+}
+
+// reconcileRollingUpgrade restarts the Elasticsearch pods running an outdated revision of their
+// StatefulSet one at a time across the node sets, and records the progress in the ElasticsearchUpgrading
+// condition. The StatefulSets use the OnDelete strategy, so their pods are only replaced by this function
+func (r *EFKStackReconciler) reconcileRollingUpgrade(ctx context.Context, efkStack *loggingv1.EFKStack, namespace string) {
+	logger := log.FromContext(ctx)
+
+	var statefulSets []*appsv1.StatefulSet
+	for _, nodeSet := range elasticsearchNodeSets(efkStack) {
+		statefulSet := &appsv1.StatefulSet{}
+		name := types.NamespacedName{Name: nodeSet.StatefulSet, Namespace: namespace}
+		if err := r.Get(ctx, name, statefulSet); err != nil {
+			// L'absence du workload est déjà remontée par updateElasticsearchReadiness
+			return
+		}
+		statefulSets = append(statefulSets, statefulSet)
+	}
+
+	inProgress := meta.IsStatusConditionTrue(efkStack.Status.Conditions, loggingv1.ConditionElasticsearchUpgrading)
+	var progress upgradeProgress
+	pods := &corev1.PodList{}
+	err := r.List(ctx, pods, client.InNamespace(namespace),
+		client.MatchingLabels{"app.kubernetes.io/instance": fmt.Sprintf("%s-elasticsearch", efkStack.Name)})
+	if err == nil {
+		var esClient *elasticsearch.Client
+		if esClient, err = newElasticsearchClient(ctx, r.Client, efkStack, namespace); err == nil {
+			progress, err = rollingUpgrade(ctx, r.Client, esClient, statefulSets, pods.Items, inProgress)
+		}
+	}
+	if err != nil {
+		logger.Error(err, "Rolling upgrade of Elasticsearch failed, retrying")
+		progress = upgradeProgress{Reason: upgradeReasonFailed, Message: err.Error()}
+	}
+	setUpgradeProgress(efkStack, progress)
+}
+
+// setUpgradeProgress records a step of a rolling upgrade in the status. The deployed version
+// only changes once every node runs the last revision, so that the upgrades requested during
+// the rollout are still checked against the version of the nodes not yet restarted
+func setUpgradeProgress(efkStack *loggingv1.EFKStack, progress upgradeProgress) {
+	condition := metav1.Condition{
+		Type:               loggingv1.ConditionElasticsearchUpgrading,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: efkStack.Generation,
+		Reason:             progress.Reason,
+		Message:            progress.Message,
+	}
+	if progress.Done {
+		condition.Status = metav1.ConditionFalse
+		// Tous les nœuds exécutent la version de la spec
+		efkStack.Status.Elasticsearch.Version = efkStack.Spec.Elasticsearch.Version
+	} else {
+		// Fluent Bit et Kibana ne sont pas mis à jour tant que tous les nœuds n'ont pas redémarré
+		efkStack.Status.Elasticsearch.State = "Upgrading"
+		efkStack.Status.Elasticsearch.Message = progress.Message
+	}
+	meta.SetStatusCondition(&efkStack.Status.Conditions, condition)
+}
+
+// rollingUpgrade performs the next step of a rolling upgrade, following the Elasticsearch
+// procedure: wait for the restarted node and a green cluster, disable the allocation of
+// replicas, flush, restart the next node, then enable the allocation again once it rejoined.
+// The StatefulSets are upgraded in the given order, the pods of each from the highest ordinal.
+// inProgress tells whether a previous step may have left the shard allocation disabled
+func rollingUpgrade(ctx context.Context, c client.Client, esClient *elasticsearch.Client, statefulSets []*appsv1.StatefulSet, pods []corev1.Pod, inProgress bool) (upgradeProgress, error) {
+	logger := log.FromContext(ctx)
+
+	// Tant que le contrôleur StatefulSet n'a pas observé la spec, UpdateRevision est celle de
+	// la révision précédente et les pods paraîtraient à jour
+	for _, statefulSet := range statefulSets {
+		if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+			return upgradeProgress{
+				Reason:  upgradeReasonWaitingForNode,
+				Message: fmt.Sprintf("Waiting for StatefulSet %s to observe its last revision", statefulSet.Name),
+			}, nil
+		}
+	}
+
+	desired := int32(0)
+	order := map[string]int{}
+	revisions := map[string]string{}
+	for i, statefulSet := range statefulSets {
+		desired += statefulSetReplicas(statefulSet)
+		order[statefulSet.Name] = i
+		revisions[statefulSet.Name] = statefulSet.Status.UpdateRevision
+	}
+
+	var outdated []*corev1.Pod
+	ready := int32(0)
+	total := 0
+	for i := range pods {
+		pod := &pods[i]
+		revision, ok := revisions[podStatefulSet(pod)]
+		if !ok {
+			// Pod d'un groupe retiré de la spec
+			continue
+		}
+		total++
+		if revision != "" && pod.Labels[appsv1.ControllerRevisionHashLabelKey] != revision {
+			outdated = append(outdated, pod)
+		}
+		if pod.DeletionTimestamp.IsZero() && podReady(pod) {
+			ready++
+		}
+	}
+	upgraded := int32(total - len(outdated))
+
+	// Rien à redémarrer : l'allocation n'a été désactivée que si une mise à jour était en cours
+	if len(outdated) == 0 && !inProgress {
+		return upgradeProgress{
+			Done:    true,
+			Reason:  upgradeReasonUpToDate,
+			Message: fmt.Sprintf("All %d nodes run the last revision", total),
+		}, nil
+	}
+
+	// Le nœud redémarré doit avoir rejoint le cluster avant toute autre action
+	if ready < desired {
+		return upgradeProgress{
+			Reason:  upgradeReasonWaitingForNode,
+			Message: fmt.Sprintf("Waiting for the restarted node, %d/%d pods ready (%d/%d nodes upgraded)", ready, desired, upgraded, desired),
+		}, nil
+	}
+
+	settings, err := esClient.GetClusterSettings(ctx)
+	if err != nil {
+		return upgradeProgress{}, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	if settings.Persistent[elasticsearch.AllocationEnableSetting] == elasticsearch.AllocationPrimaries {
+		logger.Info("Enabling shard allocation after node restart")
+		err := esClient.PutClusterSettings(ctx, &elasticsearch.ClusterSettings{
+			Persistent: map[string]interface{}{elasticsearch.AllocationEnableSetting: nil},
+		})
+		if err != nil {
+			return upgradeProgress{}, fmt.Errorf("failed to enable shard allocation: %w", err)
+		}
+	}
+
+	health, err := esClient.ClusterHealth(ctx)
+	if err != nil {
+		return upgradeProgress{}, fmt.Errorf("failed to get cluster health: %w", err)
+	}
+	// Un nœud seul ne peut pas héberger ses replicas : le jaune est alors l'état nominal
+	healthy := health.Status == elasticsearch.HealthGreen ||
+		(health.Status == elasticsearch.HealthYellow && desired == 1)
+	if !healthy {
+		return upgradeProgress{
+			Reason: upgradeReasonWaitingForHealth,
+			Message: fmt.Sprintf("Waiting for the cluster to be green, health is %s with %d unassigned shards (%d/%d nodes upgraded)",
+				health.Status, health.UnassignedShards, upgraded, desired),
+		}, nil
+	}
+
+	if len(outdated) == 0 {
+		return upgradeProgress{
+			Done:    true,
+			Reason:  upgradeReasonUpToDate,
+			Message: fmt.Sprintf("All %d nodes run the last revision", total),
+		}, nil
+	}
+
+	// Groupe par groupe (les nœuds éligibles master en dernier) puis, comme le contrôleur
+	// StatefulSet, en commençant par le pod d'ordinal le plus élevé
+	sort.Slice(outdated, func(i, j int) bool {
+		oi, oj := order[podStatefulSet(outdated[i])], order[podStatefulSet(outdated[j])]
+		if oi != oj {
+			return oi < oj
+		}
+		return podOrdinal(outdated[i]) > podOrdinal(outdated[j])
+	})
+	pod := outdated[0]
+
+	// Les replicas du nœud arrêté ne sont pas recopiés ailleurs pendant son redémarrage
+	err = esClient.PutClusterSettings(ctx, &elasticsearch.ClusterSettings{
+		Persistent: map[string]interface{}{elasticsearch.AllocationEnableSetting: elasticsearch.AllocationPrimaries},
+	})
+	if err != nil {
+		return upgradeProgress{}, fmt.Errorf("failed to disable shard allocation: %w", err)
+	}
+	if err := esClient.Flush(ctx); err != nil {
+		// Le flush accélère seulement la récupération des shards
+		logger.Info("Failed to flush indices before restarting the node", "error", err)
+	}
+
+	logger.Info("Restarting Elasticsearch node", "pod", pod.Name, "revision", revisions[podStatefulSet(pod)])
+	if err := c.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+		return upgradeProgress{}, fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+	}
+
+	return upgradeProgress{
+		Reason:  upgradeReasonRestartingNode,
+		Message: fmt.Sprintf("Restarting node %s (%d/%d nodes upgraded)", pod.Name, upgraded, desired),
+	}, nil
+}
+
+// podReady returns true if the Ready condition of the pod is true
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podOrdinal returns the ordinal of a StatefulSet pod, or -1 when its name has none
+func podOrdinal(pod *corev1.Pod) int {
+	index := strings.LastIndex(pod.Name, "-")
+	if index < 0 {
+		return -1
+	}
+	ordinal, err := strconv.Atoi(pod.Name[index+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+// podStatefulSet returns the name of the StatefulSet owning a pod, derived from the pod name
+func podStatefulSet(pod *corev1.Pod) string {
+	index := strings.LastIndex(pod.Name, "-")
+	if index < 0 {
+		return ""
+	}
+	return pod.Name[:index]
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("Rolling upgrade", func() {
+	var (
+		ctx         context.Context
+		server      *httptest.Server
+		esClient    *elasticsearch.Client
+		fakeClient  client.Client
+		statefulSet *appsv1.StatefulSet
+		allocation  interface{}
+		health      string
+		flushes     int
+	)
+
+	newPod := func(ordinal int, revision string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("test-efk-elasticsearch-%d", ordinal),
+				Namespace: "logging",
+				Labels: map[string]string{
+					"app.kubernetes.io/instance":          "test-efk-elasticsearch",
+					appsv1.ControllerRevisionHashLabelKey: revision,
+				},
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+		}
+	}
+
+	step := func() upgradeProgress {
+		pods := &corev1.PodList{}
+		Expect(fakeClient.List(ctx, pods, client.InNamespace("logging"))).To(Succeed())
+		progress, err := rollingUpgrade(ctx, fakeClient, esClient, []*appsv1.StatefulSet{statefulSet}, pods.Items, true)
+		Expect(err).NotTo(HaveOccurred())
+		return progress
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		allocation = nil
+		health = elasticsearch.HealthGreen
+		flushes = 0
+
+		// Fake Elasticsearch keeping the allocation setting it receives
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_cluster/settings", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				settings := &elasticsearch.ClusterSettings{}
+				Expect(json.NewDecoder(r.Body).Decode(settings)).To(Succeed())
+				allocation = settings.Persistent[elasticsearch.AllocationEnableSetting]
+				return
+			}
+			persistent := map[string]interface{}{}
+			if allocation != nil {
+				persistent[elasticsearch.AllocationEnableSetting] = allocation
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"persistent": persistent})).To(Succeed())
+		})
+		mux.HandleFunc("/_cluster/health", func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"status": health})).To(Succeed())
+		})
+		mux.HandleFunc("/_flush", func(w http.ResponseWriter, r *http.Request) {
+			flushes++
+		})
+		server = httptest.NewServer(mux)
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+
+		replicas := int32(3)
+		statefulSet = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch", Namespace: "logging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{UpdateRevision: "rev-2"},
+		}
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(newPod(0, "rev-1", true), newPod(1, "rev-1", true), newPod(2, "rev-1", true)).
+			Build()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should be done when every pod runs the last revision", func() {
+		pods := []corev1.Pod{*newPod(0, "rev-2", true), *newPod(1, "rev-2", false)}
+		progress, err := rollingUpgrade(ctx, fakeClient, esClient, []*appsv1.StatefulSet{statefulSet}, pods, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Done).To(BeTrue())
+		Expect(flushes).To(BeZero())
+	})
+
+	It("Should restart one node at a time and wait for a green cluster", func() {
+		progress := step()
+		Expect(progress.Reason).To(Equal(upgradeReasonRestartingNode))
+		Expect(allocation).To(Equal(elasticsearch.AllocationPrimaries))
+		Expect(flushes).To(Equal(1))
+		err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-efk-elasticsearch-2", Namespace: "logging"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		// The StatefulSet recreates the pod with the new revision
+		Expect(fakeClient.Create(ctx, newPod(2, "rev-2", false))).To(Succeed())
+		progress = step()
+		Expect(progress.Reason).To(Equal(upgradeReasonWaitingForNode))
+		Expect(allocation).To(Equal(elasticsearch.AllocationPrimaries))
+
+		// Once the node rejoined, the allocation is enabled again before waiting for green
+		Expect(fakeClient.Delete(ctx, newPod(2, "rev-2", false))).To(Succeed())
+		Expect(fakeClient.Create(ctx, newPod(2, "rev-2", true))).To(Succeed())
+		health = elasticsearch.HealthYellow
+		progress = step()
+		Expect(progress.Reason).To(Equal(upgradeReasonWaitingForHealth))
+		Expect(allocation).To(BeNil())
+
+		health = elasticsearch.HealthGreen
+		progress = step()
+		Expect(progress.Reason).To(Equal(upgradeReasonRestartingNode))
+		Expect(progress.Message).To(ContainSubstring("test-efk-elasticsearch-1"))
+		Expect(progress.Message).To(ContainSubstring("1/3 nodes upgraded"))
+	})
+
+	It("Should restart the other node sets before the master-eligible nodes", func() {
+		one := int32(1)
+		hot := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-hot", Namespace: "logging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &one},
+			Status:     appsv1.StatefulSetStatus{UpdateRevision: "hot-2"},
+		}
+		master := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-master", Namespace: "logging"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &one},
+			Status:     appsv1.StatefulSetStatus{UpdateRevision: "master-2"},
+		}
+		masterPod := newPod(0, "master-1", true)
+		masterPod.Name = "test-efk-elasticsearch-master-0"
+		hotPod := newPod(0, "hot-1", true)
+		hotPod.Name = "test-efk-elasticsearch-hot-0"
+
+		pods := []corev1.Pod{*masterPod, *hotPod}
+		progress, err := rollingUpgrade(ctx, fakeClient, esClient, []*appsv1.StatefulSet{hot, master}, pods, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Reason).To(Equal(upgradeReasonRestartingNode))
+		Expect(progress.Message).To(ContainSubstring("test-efk-elasticsearch-hot-0"))
+		Expect(progress.Message).To(ContainSubstring("0/2 nodes upgraded"))
+	})
+
+	It("Should wait for the StatefulSet controller to observe the new revision", func() {
+		statefulSet.Generation = 2
+		statefulSet.Status.ObservedGeneration = 1
+		pods := []corev1.Pod{*newPod(0, "rev-2", true)}
+		progress, err := rollingUpgrade(ctx, fakeClient, esClient, []*appsv1.StatefulSet{statefulSet}, pods, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Done).To(BeFalse())
+		Expect(progress.Reason).To(Equal(upgradeReasonWaitingForNode))
+	})
+
+	It("Should check a chained upgrade against the version of the nodes not yet restarted", func() {
+		stackScheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(stackScheme)
+		efkStack := &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{Version: "8.18.0", Replicas: 3},
+			},
+			Status: loggingv1.EFKStackStatus{
+				Elasticsearch: loggingv1.ElasticsearchStatus{Version: "7.17.0"},
+			},
+		}
+		stackClient := fake.NewClientBuilder().
+			WithScheme(stackScheme).
+			WithObjects(efkStack).
+			WithStatusSubresource(efkStack).
+			Build()
+		Expect(stackClient.Get(ctx, client.ObjectKeyFromObject(efkStack), efkStack)).To(Succeed())
+
+		// The first node restarts with 8.18.0, the version of the other nodes is still recorded
+		setUpgradeProgress(efkStack, step())
+		Expect(efkStack.Status.Elasticsearch.Version).To(Equal("7.17.0"))
+		Expect(efkStack.Status.Elasticsearch.State).To(Equal("Upgrading"))
+
+		// 8.18.0 to 9.0.0 is valid, but the 7.17.0 nodes could not join the cluster
+		efkStack.Spec.Elasticsearch.Version = "9.0.0"
+		reconciler := &EFKStackReconciler{Client: stackClient, Scheme: stackScheme}
+		_, err := reconciler.reconcileElasticsearch(ctx, efkStack, nil, "logging")
+		Expect(err).NotTo(HaveOccurred())
+		condition := meta.FindStatusCondition(efkStack.Status.Conditions, loggingv1.ConditionElasticsearchUpgrading)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(upgradeReasonUnsupported))
+		Expect(condition.Message).To(ContainSubstring("7.17.0"))
+
+		// Once every node runs 8.18.0, the upgrade to 9.0.0 can start
+		efkStack.Spec.Elasticsearch.Version = "8.18.0"
+		setUpgradeProgress(efkStack, upgradeProgress{Done: true, Reason: upgradeReasonUpToDate})
+		Expect(efkStack.Status.Elasticsearch.Version).To(Equal("8.18.0"))
+		Expect(loggingv1.CheckElasticsearchUpgrade(efkStack.Status.Elasticsearch.Version, "9.0.0")).To(Succeed())
+	})
+})
@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// elasticsearchURL returns the in-cluster URL of the Elasticsearch service of a stack
+func elasticsearchURL(efkStack *loggingv1.EFKStack, namespace string) string {
+	return fmt.Sprintf("%s://%s-elasticsearch.%s.svc:9200", elasticsearchScheme(efkStack), efkStack.Name, namespace)
+}
+
+// elasticsearchScheme returns the scheme of the Elasticsearch service of a stack, HTTPS when
+// the operator generates its certificates
+func elasticsearchScheme(efkStack *loggingv1.EFKStack) string {
+	if managedCertificates(efkStack) {
+		return "https"
+	}
+	return "http"
+}
+
+// stackNamespace returns the namespace where the components of a stack are deployed
+func stackNamespace(efkStack *loggingv1.EFKStack) string {
+	if efkStack.Spec.Namespace != "" {
+		return efkStack.Spec.Namespace
+	}
+	return efkStack.Namespace
+}
+
+// newElasticsearchClient builds a client for the Elasticsearch API of a stack. When the operator
+// generates the certificates, the server is verified with the CA bundle of the stack. With
+// authentication enabled, the client authenticates as the elastic user
+func newElasticsearchClient(ctx context.Context, c client.Client, efkStack *loggingv1.EFKStack, namespace string) (*elasticsearch.Client, error) {
+	cfg := elasticsearch.Config{
+		URL: elasticsearchURL(efkStack, namespace),
+	}
+	if managedCertificates(efkStack) {
+		_, caName, _, _ := certificateSecretNames(efkStack)
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: caName, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Elasticsearch CA: %w", err)
+		}
+		httpClient, err := elasticsearchHTTPClients.get(client.ObjectKeyFromObject(efkStack), secret.Data[certificates.CAKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA in Secret %s: %w", caName, err)
+		}
+		cfg.HTTPClient = httpClient
+	}
+	if efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		elasticName, _, _ := credentialSecretNames(efkStack)
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: elasticName, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Elasticsearch credentials: %w", err)
+		}
+		cfg.Username = elasticUser
+		cfg.Password = string(secret.Data[loggingv1.PasswordKey])
+	}
+	return elasticsearch.NewClient(cfg), nil
+}
+
+// elasticsearchHTTPClients and kibanaHTTPClients hold the HTTPS clients of the stacks, shared by
+// the reconcilers calling their APIs
+var (
+	elasticsearchHTTPClients = &httpClientCache{timeout: 10 * time.Second}
+	kibanaHTTPClients        = &httpClientCache{timeout: 30 * time.Second}
+)
+
+// httpClientCache keeps one HTTPS client per stack so that the reconciles reuse the connections
+// of its transport instead of opening new ones on every call. The client of a stack is replaced
+// when its CA bundle changes
+type httpClientCache struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	clients map[types.NamespacedName]cachedHTTPClient
+}
+
+// cachedHTTPClient is a client of the cache with the hash of the CA bundle it trusts
+type cachedHTTPClient struct {
+	caHash string
+	client *http.Client
+}
+
+// get returns the client of the stack verifying the servers with the given PEM CA bundle
+func (c *httpClientCache) get(stack types.NamespacedName, caPEM []byte) (*http.Client, error) {
+	sum := sha256.Sum256(caPEM)
+	caHash := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.clients[stack]
+	if ok && cached.caHash == caHash {
+		return cached.client, nil
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in the CA bundle")
+	}
+	httpClient := &http.Client{
+		Timeout: c.timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+		},
+	}
+	if ok {
+		// Les connexions ouvertes avec l'ancienne CA ne sont plus utilisées
+		cached.client.CloseIdleConnections()
+	}
+	if c.clients == nil {
+		c.clients = map[types.NamespacedName]cachedHTTPClient{}
+	}
+	c.clients[stack] = cachedHTTPClient{caHash: caHash, client: httpClient}
+	return httpClient, nil
+}
+
+// forget closes the idle connections of the client of a stack and removes it from the cache
+func (c *httpClientCache) forget(stack types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[stack]; ok {
+		cached.client.CloseIdleConnections()
+		delete(c.clients, stack)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+)
+
+var _ = Describe("HTTP client cache", func() {
+	newCAPEM := func() []byte {
+		ca, err := certificates.NewCA("test-efk-ca", time.Hour)
+		Expect(err).NotTo(HaveOccurred())
+		encoded, err := ca.Encode()
+		Expect(err).NotTo(HaveOccurred())
+		return encoded.CertificatePEM
+	}
+
+	It("Should reuse the client of a stack until its CA changes", func() {
+		cache := &httpClientCache{timeout: 10 * time.Second}
+		stack := types.NamespacedName{Name: "test-efk", Namespace: "logging"}
+		caPEM := newCAPEM()
+
+		first, err := cache.get(stack, caPEM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Timeout).To(Equal(10 * time.Second))
+		second, err := cache.get(stack, caPEM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+
+		// Another stack gets its own transport
+		other, err := cache.get(types.NamespacedName{Name: "other", Namespace: "logging"}, caPEM)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(BeIdenticalTo(first))
+
+		rotated, err := cache.get(stack, newCAPEM())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated).NotTo(BeIdenticalTo(first))
+
+		cache.forget(stack)
+		Expect(cache.clients).NotTo(HaveKey(stack))
+		Expect(cache.clients).To(HaveLen(1))
+
+		_, err = cache.get(stack, []byte("not a certificate"))
+		Expect(err).To(HaveOccurred())
+	})
+})
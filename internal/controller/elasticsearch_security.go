@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// securityFinalizer removes the users and roles from Elasticsearch before their resource is deleted
+const securityFinalizer = "logging.efk.crds.io/elasticsearch-security"
+
+const (
+	// securityRetryInterval is the delay before retrying a user or a role that could not be applied
+	securityRetryInterval = 30 * time.Second
+	// securityResyncInterval is the delay between two checks of an applied user or role, to
+	// revert the changes made outside of the operator
+	securityResyncInterval = 5 * time.Minute
+)
+
+// Reasons of the Ready condition of ElasticsearchUsers and ElasticsearchRoles
+const (
+	securityReasonApplied          = "Applied"
+	securityReasonStackNotFound    = "StackNotFound"
+	securityReasonSecurityDisabled = "SecurityDisabled"
+	securityReasonNotAllowed       = "NamespaceNotAllowed"
+	securityReasonInvalid          = "Invalid"
+	securityReasonPending          = "Pending"
+	securityReasonSecretNotFound   = "SecretNotFound"
+	securityReasonConflict         = "Conflict"
+	securityReasonApplyFailed      = "ApplyFailed"
+)
+
+// securityOwnerMeta is the metadata key recording the resource that manages a user or a role
+const securityOwnerMeta = "owner"
+
+// errNotOwned is returned when a user or a role belongs to Elasticsearch or to another resource
+var errNotOwned = stderrors.New("not managed by this resource")
+
+// securityClient returns a client for the Elasticsearch cluster of the referenced stack. When the
+// security API cannot be used, the client is nil and the reason and message explain why. When the
+// stack does not allow the namespace of the resource, the client is returned with the reason
+// securityReasonNotAllowed, only to remove what the resource created before
+func securityClient(ctx context.Context, c client.Client, ref loggingv1.StackReference, namespace string) (*elasticsearch.Client, string, string, error) {
+	stackKey := types.NamespacedName{Name: ref.Name, Namespace: ref.NamespaceOr(namespace)}
+	efkStack := &loggingv1.EFKStack{}
+	if err := c.Get(ctx, stackKey, efkStack); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, "", "", err
+		}
+		return nil, securityReasonStackNotFound, fmt.Sprintf("EFKStack %s not found", stackKey), nil
+	}
+	if !efkStack.DeletionTimestamp.IsZero() {
+		return nil, securityReasonStackNotFound, fmt.Sprintf("EFKStack %s is being deleted", stackKey), nil
+	}
+	if !efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		return nil, securityReasonSecurityDisabled, fmt.Sprintf("Authentication is disabled on EFKStack %s", stackKey), nil
+	}
+	if efkStack.Status.Elasticsearch.State != "Ready" {
+		return nil, securityReasonPending, "Waiting for Elasticsearch to be ready", nil
+	}
+
+	esClient, err := newElasticsearchClient(ctx, c, efkStack, stackNamespace(efkStack))
+	if err != nil {
+		return nil, "", "", err
+	}
+	// Le client de l'opérateur authentifie en elastic : seuls les namespaces choisis par la stack l'utilisent
+	if !efkStack.Spec.Elasticsearch.Security.AllowsNamespace(stackKey.Namespace, namespace) {
+		return esClient, securityReasonNotAllowed, fmt.Sprintf("EFKStack %s does not list namespace %s in spec.elasticsearch.security.allowedNamespaces", stackKey, namespace), nil
+	}
+	return esClient, "", "", nil
+}
+
+// finalizeSecurityObject deletes a user or a role from Elasticsearch with remove, then releases
+// its resource. Without stack or security API, there is nothing left to delete
+func finalizeSecurityObject(ctx context.Context, c client.Client, obj client.Object, reason string, remove func() error) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(obj, securityFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	switch reason {
+	case "", securityReasonNotAllowed:
+		if err := remove(); err != nil {
+			return ctrl.Result{}, err
+		}
+	case securityReasonPending:
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, nil
+	}
+
+	controllerutil.RemoveFinalizer(obj, securityFinalizer)
+	return ctrl.Result{}, c.Update(ctx, obj)
+}
+
+// securityOwner identifies the resource managing a user or a role in its metadata
+func securityOwner(obj client.Object) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// securityMeta returns the metadata of a managed user or role: the hash of its definition,
+// like managedMeta, and the resource managing it
+func securityMeta(desired interface{}, obj client.Object) (map[string]interface{}, error) {
+	metadata, err := managedMeta(desired)
+	if err != nil {
+		return nil, err
+	}
+	metadata[securityOwnerMeta] = securityOwner(obj)
+	return metadata, nil
+}
+
+// checkSecurityOwner returns errNotOwned when a user or a role is reserved by Elasticsearch or
+// managed by another resource. The users and roles created by hand are adopted
+func checkSecurityOwner(metadata map[string]interface{}, obj client.Object) error {
+	if reserved, _ := metadata["_reserved"].(bool); reserved {
+		return fmt.Errorf("%w: reserved by Elasticsearch", errNotOwned)
+	}
+	if owner, ok := metadata[securityOwnerMeta]; ok && owner != securityOwner(obj) {
+		return fmt.Errorf("%w: managed by %v", errNotOwned, owner)
+	}
+	return nil
+}
+
+// securityReason returns the reason of the Ready condition for an error of ensureRole or ensureUser
+func securityReason(err error) string {
+	if stderrors.Is(err, errNotOwned) {
+		return securityReasonConflict
+	}
+	return securityReasonApplyFailed
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSecurityAPI stores the users and roles sent to the security API of Elasticsearch
+type fakeSecurityAPI struct {
+	*httptest.Server
+	roles     map[string]map[string]interface{}
+	users     map[string]map[string]interface{}
+	passwords map[string]string
+	writes    int
+}
+
+func newFakeSecurityAPI() *fakeSecurityAPI {
+	api := &fakeSecurityAPI{
+		roles:     map[string]map[string]interface{}{},
+		users:     map[string]map[string]interface{}{},
+		passwords: map[string]string{},
+	}
+	serve := func(objects map[string]map[string]interface{}, prefix string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			name := strings.TrimPrefix(r.URL.Path, prefix)
+			if strings.HasSuffix(name, "/_password") {
+				var body map[string]string
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				api.passwords[strings.TrimSuffix(name, "/_password")] = body["password"]
+				api.writes++
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				if objects[name] == nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{name: objects[name]})
+			case http.MethodPut:
+				var body map[string]interface{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				if password, ok := body["password"].(string); ok {
+					api.passwords[name] = password
+					delete(body, "password")
+				}
+				objects[name] = body
+				api.writes++
+			case http.MethodDelete:
+				if objects[name] == nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				delete(objects, name)
+			}
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_security/role/", serve(api.roles, "/_security/role/"))
+	mux.HandleFunc("/_security/user/", serve(api.users, "/_security/user/"))
+	mux.HandleFunc("/_security/_authenticate", func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		if stored, ok := api.passwords[user]; !ok || stored != password {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	api.Server = httptest.NewServer(mux)
+	return api
+}
+
+var _ = Describe("Elasticsearch security objects", func() {
+	It("Should adopt unmanaged objects and refuse the others", func() {
+		role := newTestRole()
+		Expect(checkSecurityOwner(nil, role)).To(Succeed())
+		Expect(checkSecurityOwner(map[string]interface{}{"owner": "team-a/team-a-read"}, role)).To(Succeed())
+		Expect(checkSecurityOwner(map[string]interface{}{"owner": "team-b/team-a-read"}, role)).To(MatchError(errNotOwned))
+		Expect(checkSecurityOwner(map[string]interface{}{"_reserved": true}, role)).To(MatchError(errNotOwned))
+	})
+})
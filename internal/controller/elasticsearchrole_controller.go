@@ -0,0 +1,272 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// kibanaApplication is the application of the Kibana privileges in the Elasticsearch roles
+const kibanaApplication = "kibana-.kibana"
+
+// ElasticsearchRoleReconciler applies ElasticsearchRoles to the Elasticsearch cluster of their
+// EFKStack and deletes them with their resource
+type ElasticsearchRoleReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchroles,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchroles/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchroles/finalizers,verbs=update
+
+// Reconcile applies the role once Elasticsearch is ready and checks it periodically, so that
+// the changes made through the API or Kibana are reverted
+func (r *ElasticsearchRoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	role := &loggingv1.ElasticsearchRole{}
+	if err := r.Get(ctx, req.NamespacedName, role); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	esClient, reason, message, err := securityClient(ctx, r.Client, role.Spec.StackRef, role.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !role.DeletionTimestamp.IsZero() {
+		return finalizeSecurityObject(ctx, r.Client, role, reason, func() error {
+			return deleteRole(ctx, esClient, role)
+		})
+	}
+	if controllerutil.AddFinalizer(role, securityFinalizer) {
+		if err := r.Update(ctx, role); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if errs := role.Validate(); len(errs) > 0 {
+		// Le webhook peut être désactivé
+		return ctrl.Result{}, r.setReady(ctx, role, metav1.ConditionFalse, securityReasonInvalid, errs.ToAggregate().Error())
+	}
+	if reason == securityReasonNotAllowed {
+		// Le namespace a pu être retiré de la liste après la création
+		if err := deleteRole(ctx, esClient, role); err != nil {
+			return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, role, metav1.ConditionFalse, securityReasonApplyFailed, err.Error())
+		}
+		role.Status.RoleName = ""
+		esClient = nil
+	}
+	if esClient == nil {
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, role, metav1.ConditionFalse, reason, message)
+	}
+
+	bindings, err := r.spaceBindings(ctx, role)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	name := role.ElasticsearchRoleName()
+	changed, err := ensureRole(ctx, esClient, role, bindings)
+	if err != nil {
+		logger.Error(err, "Failed to apply Elasticsearch role", "role", name)
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, role, metav1.ConditionFalse, securityReason(err), err.Error())
+	}
+	if changed {
+		logger.Info("Applied Elasticsearch role", "role", name)
+	}
+
+	role.Status.RoleName = name
+	return ctrl.Result{RequeueAfter: securityResyncInterval}, r.setReady(ctx, role, metav1.ConditionTrue, securityReasonApplied,
+		fmt.Sprintf("Role %s applied", name))
+}
+
+// setReady updates the Ready condition, only writing the status when it changed
+func (r *ElasticsearchRoleReconciler) setReady(ctx context.Context, role *loggingv1.ElasticsearchRole, status metav1.ConditionStatus, reason, message string) error {
+	previous := role.Status.DeepCopy()
+	meta.SetStatusCondition(&role.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: role.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	role.Status.ObservedGeneration = role.Generation
+	if equality.Semantic.DeepEqual(previous, &role.Status) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, role); err != nil {
+		return fmt.Errorf("failed to update ElasticsearchRole %s/%s status: %w", role.Namespace, role.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ElasticsearchRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.ElasticsearchRole{}).
+		Watches(&loggingv1.KibanaSpace{}, handler.EnqueueRequestsFromMapFunc(mapKibanaSpaceToElasticsearchRoles)).
+		Complete(r)
+}
+
+// spaceBindings returns the Kibana privileges granted to the role by the KibanaSpaces of its
+// namespace on the same stack
+func (r *ElasticsearchRoleReconciler) spaceBindings(ctx context.Context, role *loggingv1.ElasticsearchRole) ([]loggingv1.KibanaPrivilegesSpec, error) {
+	spaces := &loggingv1.KibanaSpaceList{}
+	if err := r.List(ctx, spaces, client.InNamespace(role.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list KibanaSpaces: %w", err)
+	}
+	// Ordre stable, le condensat du rôle en dépend
+	sort.Slice(spaces.Items, func(i, j int) bool { return spaces.Items[i].Name < spaces.Items[j].Name })
+
+	var bindings []loggingv1.KibanaPrivilegesSpec
+	for _, space := range spaces.Items {
+		if !space.DeletionTimestamp.IsZero() || space.Spec.StackRef.Name != role.Spec.StackRef.Name ||
+			space.Spec.StackRef.NamespaceOr(space.Namespace) != role.Spec.StackRef.NamespaceOr(role.Namespace) {
+			continue
+		}
+		for _, binding := range space.Spec.Roles {
+			if binding.Name == role.Name {
+				bindings = append(bindings, loggingv1.KibanaPrivilegesSpec{
+					Base:   binding.PrivilegeOrDefault(),
+					Spaces: []string{space.KibanaSpaceID()},
+				})
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// ensureRole creates the role or replaces it when it differs from the spec and the bindings of
+// the KibanaSpaces, and returns true when it was written
+func ensureRole(ctx context.Context, esClient *elasticsearch.Client, role *loggingv1.ElasticsearchRole, bindings []loggingv1.KibanaPrivilegesSpec) (bool, error) {
+	name := role.ElasticsearchRoleName()
+	desired := desiredRole(role, bindings)
+	metadata, err := securityMeta(desired, role)
+	if err != nil {
+		return false, err
+	}
+	desired.Metadata = metadata
+
+	current, err := esClient.GetRole(ctx, name)
+	if err != nil && !elasticsearch.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get role %s: %w", name, err)
+	}
+	if err == nil {
+		if err := checkSecurityOwner(current.Metadata, role); err != nil {
+			return false, fmt.Errorf("role %s is %w", name, err)
+		}
+		if inSync, err := elasticsearch.Contains(current, desired); err != nil || inSync {
+			return false, err
+		}
+	}
+	if err := esClient.PutRole(ctx, name, desired); err != nil {
+		return false, fmt.Errorf("failed to apply role %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// deleteRole deletes the role from Elasticsearch when it is managed by the resource
+func deleteRole(ctx context.Context, esClient *elasticsearch.Client, role *loggingv1.ElasticsearchRole) error {
+	name := role.ElasticsearchRoleName()
+	current, err := esClient.GetRole(ctx, name)
+	if elasticsearch.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get role %s: %w", name, err)
+	}
+	if current.Metadata[securityOwnerMeta] != securityOwner(role) {
+		return nil
+	}
+	if err := esClient.DeleteRole(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete role %s: %w", name, err)
+	}
+	log.FromContext(ctx).Info("Deleted Elasticsearch role", "role", name)
+	return nil
+}
+
+// desiredRole converts the spec of an ElasticsearchRole, completed by the bindings of the
+// KibanaSpaces, into a role of the security API
+func desiredRole(role *loggingv1.ElasticsearchRole, bindings []loggingv1.KibanaPrivilegesSpec) *elasticsearch.Role {
+	desired := &elasticsearch.Role{Cluster: role.Spec.Cluster}
+	for _, indices := range role.Spec.Indices {
+		desired.Indices = append(desired.Indices, elasticsearch.IndexPrivileges{
+			Names:      indices.Names,
+			Privileges: indices.Privileges,
+		})
+	}
+	for _, kibana := range append(append([]loggingv1.KibanaPrivilegesSpec(nil), role.Spec.Kibana...), bindings...) {
+		desired.Applications = append(desired.Applications, kibanaApplicationPrivileges(kibana))
+	}
+	return desired
+}
+
+// kibanaApplicationPrivileges translates Kibana privileges into the application privileges
+// stored by Kibana in its roles: base privileges are prefixed with space_ when limited to
+// some spaces, feature privileges are named feature_<feature>.<privilege>
+func kibanaApplicationPrivileges(spec loggingv1.KibanaPrivilegesSpec) elasticsearch.ApplicationPrivileges {
+	resources := []string{"*"}
+	if len(spec.Spaces) > 0 && spec.Spaces[0] != "*" {
+		resources = make([]string, 0, len(spec.Spaces))
+		for _, space := range spec.Spaces {
+			resources = append(resources, "space:"+space)
+		}
+	}
+
+	var privileges []string
+	if spec.Base != "" {
+		privilege := spec.Base
+		if resources[0] != "*" {
+			privilege = "space_" + privilege
+		}
+		privileges = []string{privilege}
+	} else {
+		features := make([]string, 0, len(spec.Features))
+		for feature := range spec.Features {
+			features = append(features, feature)
+		}
+		sort.Strings(features)
+		for _, feature := range features {
+			for _, privilege := range spec.Features[feature] {
+				privileges = append(privileges, fmt.Sprintf("feature_%s.%s", feature, privilege))
+			}
+		}
+	}
+
+	return elasticsearch.ApplicationPrivileges{
+		Application: kibanaApplication,
+		Privileges:  privileges,
+		Resources:   resources,
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+func newTestRole() *loggingv1.ElasticsearchRole {
+	return &loggingv1.ElasticsearchRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a-read", Namespace: "team-a"},
+		Spec: loggingv1.ElasticsearchRoleSpec{
+			StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+			Indices:  []loggingv1.IndexPrivilegesSpec{{Names: []string{"team-a-*"}, Privileges: []string{"read"}}},
+			Kibana: []loggingv1.KibanaPrivilegesSpec{
+				{Features: map[string][]string{"discover": {"read"}, "dashboard": {"read"}}, Spaces: []string{"team-a"}},
+			},
+		},
+	}
+}
+
+var _ = Describe("ElasticsearchRole Controller", func() {
+	var (
+		ctx      context.Context
+		role     *loggingv1.ElasticsearchRole
+		api      *fakeSecurityAPI
+		esClient *elasticsearch.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		role = newTestRole()
+		api = newFakeSecurityAPI()
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: api.URL})
+	})
+
+	AfterEach(func() {
+		api.Close()
+	})
+
+	It("Should translate the Kibana privileges", func() {
+		desired := desiredRole(role, nil)
+		Expect(desired.Applications).To(Equal([]elasticsearch.ApplicationPrivileges{{
+			Application: "kibana-.kibana",
+			Privileges:  []string{"feature_dashboard.read", "feature_discover.read"},
+			Resources:   []string{"space:team-a"},
+		}}))
+
+		base := kibanaApplicationPrivileges(loggingv1.KibanaPrivilegesSpec{Base: "read", Spaces: []string{"team-a"}})
+		Expect(base.Privileges).To(Equal([]string{"space_read"}))
+		base = kibanaApplicationPrivileges(loggingv1.KibanaPrivilegesSpec{Base: "all"})
+		Expect(base.Privileges).To(Equal([]string{"all"}))
+		Expect(base.Resources).To(Equal([]string{"*"}))
+	})
+
+	It("Should apply the role and revert the changes made outside of the operator", func() {
+		changed, err := ensureRole(ctx, esClient, role, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(api.roles["team-a-read"]["metadata"]).To(HaveKeyWithValue("owner", "team-a/team-a-read"))
+
+		changed, err = ensureRole(ctx, esClient, role, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		// Privilège ajouté à la main dans Kibana
+		api.roles["team-a-read"]["cluster"] = []interface{}{"all"}
+		changed, err = ensureRole(ctx, esClient, role, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(api.roles["team-a-read"]["cluster"]).To(BeEmpty())
+
+		// Un autre ElasticsearchRole ne peut pas prendre le rôle
+		other := newTestRole()
+		other.Namespace = "team-b"
+		_, err = ensureRole(ctx, esClient, other, nil)
+		Expect(err).To(MatchError(errNotOwned))
+		Expect(securityReason(err)).To(Equal(securityReasonConflict))
+		Expect(deleteRole(ctx, esClient, other)).To(Succeed())
+		Expect(api.roles).To(HaveKey("team-a-read"))
+
+		Expect(deleteRole(ctx, esClient, role)).To(Succeed())
+		Expect(api.roles).NotTo(HaveKey("team-a-read"))
+		Expect(deleteRole(ctx, esClient, role)).To(Succeed())
+	})
+
+	It("Should grant the spaces bound by KibanaSpaces", func() {
+		bound := func(name, namespace, stack string, bindings ...loggingv1.KibanaSpaceRoleBinding) *loggingv1.KibanaSpace {
+			return &loggingv1.KibanaSpace{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec: loggingv1.KibanaSpaceSpec{
+					StackRef: loggingv1.StackReference{Name: stack, Namespace: "logging"},
+					Roles:    bindings,
+				},
+			}
+		}
+		scheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(
+				bound("team-a", "team-a", "test-efk", loggingv1.KibanaSpaceRoleBinding{Name: "team-a-read"}),
+				bound("shared", "team-a", "test-efk", loggingv1.KibanaSpaceRoleBinding{Name: "team-a-read", Privilege: "all"}),
+				bound("other-stack", "team-a", "other-efk", loggingv1.KibanaSpaceRoleBinding{Name: "team-a-read"}),
+				bound("team-b", "team-b", "test-efk", loggingv1.KibanaSpaceRoleBinding{Name: "team-a-read"}),
+			).
+			Build()
+		reconciler := &ElasticsearchRoleReconciler{Client: fakeClient, Scheme: scheme}
+
+		bindings, err := reconciler.spaceBindings(ctx, role)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bindings).To(Equal([]loggingv1.KibanaPrivilegesSpec{
+			{Base: "all", Spaces: []string{"shared"}},
+			{Base: "read", Spaces: []string{"team-a"}},
+		}))
+		Expect(desiredRole(role, bindings).Applications[1:]).To(Equal([]elasticsearch.ApplicationPrivileges{
+			{Application: "kibana-.kibana", Privileges: []string{"space_all"}, Resources: []string{"space:shared"}},
+			{Application: "kibana-.kibana", Privileges: []string{"space_read"}, Resources: []string{"space:team-a"}},
+		}))
+		Expect(role.Spec.Kibana).To(HaveLen(1))
+	})
+
+	It("Should report a missing stack and release the role on deletion", func() {
+		scheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&loggingv1.ElasticsearchRole{}).
+			WithObjects(role).
+			Build()
+		reconciler := &ElasticsearchRoleReconciler{Client: fakeClient, Scheme: scheme}
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(role)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(securityRetryInterval))
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(role), role)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(role, securityFinalizer)).To(BeTrue())
+		condition := meta.FindStatusCondition(role.Status.Conditions, loggingv1.ConditionReady)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(securityReasonStackNotFound))
+
+		// Sans stack, il n'y a rien à supprimer dans Elasticsearch
+		Expect(fakeClient.Delete(ctx, role)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(role)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(role), role)).NotTo(Succeed())
+	})
+})
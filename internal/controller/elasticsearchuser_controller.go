@@ -0,0 +1,277 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// ElasticsearchUserReconciler applies ElasticsearchUsers to the Elasticsearch cluster of their
+// EFKStack and deletes them with their resource
+type ElasticsearchUserReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchusers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchusers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=elasticsearchusers/finalizers,verbs=update
+
+// Reconcile applies the user and its password once Elasticsearch is ready and checks them
+// periodically, so that the changes made through the API or Kibana are reverted
+func (r *ElasticsearchUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	user := &loggingv1.ElasticsearchUser{}
+	if err := r.Get(ctx, req.NamespacedName, user); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	esClient, reason, message, err := securityClient(ctx, r.Client, user.Spec.StackRef, user.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !user.DeletionTimestamp.IsZero() {
+		return finalizeSecurityObject(ctx, r.Client, user, reason, func() error {
+			return deleteUser(ctx, esClient, user)
+		})
+	}
+	if controllerutil.AddFinalizer(user, securityFinalizer) {
+		if err := r.Update(ctx, user); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	password, err := r.userPassword(ctx, user)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, user, metav1.ConditionFalse, securityReasonSecretNotFound, err.Error())
+	}
+	user.Status.SecretName = user.PasswordSecretName()
+
+	if errs := user.Validate(); len(errs) > 0 {
+		// Le webhook peut être désactivé
+		return ctrl.Result{}, r.setReady(ctx, user, metav1.ConditionFalse, securityReasonInvalid, errs.ToAggregate().Error())
+	}
+	if reason == securityReasonNotAllowed {
+		// Le namespace a pu être retiré de la liste après la création
+		if err := deleteUser(ctx, esClient, user); err != nil {
+			return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, user, metav1.ConditionFalse, securityReasonApplyFailed, err.Error())
+		}
+		user.Status.Username = ""
+		esClient = nil
+	}
+	if esClient == nil {
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, user, metav1.ConditionFalse, reason, message)
+	}
+
+	name := user.ElasticsearchUsername()
+	changed, err := ensureUser(ctx, esClient, user, password)
+	if err != nil {
+		logger.Error(err, "Failed to apply Elasticsearch user", "user", name)
+		return ctrl.Result{RequeueAfter: securityRetryInterval}, r.setReady(ctx, user, metav1.ConditionFalse, securityReason(err), err.Error())
+	}
+	if changed {
+		logger.Info("Applied Elasticsearch user", "user", name)
+	}
+
+	user.Status.Username = name
+	return ctrl.Result{RequeueAfter: securityResyncInterval}, r.setReady(ctx, user, metav1.ConditionTrue, securityReasonApplied,
+		fmt.Sprintf("User %s applied", name))
+}
+
+// userPassword returns the password of the user. Without passwordSecret, the password is
+// generated once in a Secret owned by the ElasticsearchUser
+func (r *ElasticsearchUserReconciler) userPassword(ctx context.Context, user *loggingv1.ElasticsearchUser) (string, error) {
+	name := user.PasswordSecretName()
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: user.Namespace}, secret)
+	if err != nil && (user.Spec.PasswordSecret != nil || !errors.IsNotFound(err)) {
+		return "", err
+	}
+	if err == nil {
+		password := string(secret.Data[loggingv1.PasswordKey])
+		if password == "" {
+			return "", errors.NewNotFound(corev1.Resource("secrets"), fmt.Sprintf("%s (key %s)", name, loggingv1.PasswordKey))
+		}
+		return password, nil
+	}
+
+	password, err := generatePassword()
+	if err != nil {
+		return "", err
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: user.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "efk-operator"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			loggingv1.UsernameKey: []byte(user.ElasticsearchUsername()),
+			loggingv1.PasswordKey: []byte(password),
+		},
+	}
+	if err := controllerutil.SetControllerReference(user, secret, r.Scheme); err != nil {
+		return "", err
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return "", fmt.Errorf("failed to create Secret %s: %w", name, err)
+	}
+	return password, nil
+}
+
+// setReady updates the Ready condition, only writing the status when it changed
+func (r *ElasticsearchUserReconciler) setReady(ctx context.Context, user *loggingv1.ElasticsearchUser, status metav1.ConditionStatus, reason, message string) error {
+	previous := user.Status.DeepCopy()
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: user.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	user.Status.ObservedGeneration = user.Generation
+	if equality.Semantic.DeepEqual(previous, &user.Status) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update ElasticsearchUser %s/%s status: %w", user.Namespace, user.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ElasticsearchUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.ElasticsearchUser{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.mapSecretToElasticsearchUsers)).
+		Complete(r)
+}
+
+// mapSecretToElasticsearchUsers enqueues the ElasticsearchUsers whose password is held by a Secret
+func (r *ElasticsearchUserReconciler) mapSecretToElasticsearchUsers(ctx context.Context, obj client.Object) []reconcile.Request {
+	users := &loggingv1.ElasticsearchUserList{}
+	if err := r.List(ctx, users, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list ElasticsearchUsers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, user := range users.Items {
+		if user.PasswordSecretName() == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&user)})
+		}
+	}
+	return requests
+}
+
+// ensureUser creates the user or updates it when it differs from the spec, then checks its
+// password, which Elasticsearch never returns, by authenticating. It returns true when the
+// user was written
+func ensureUser(ctx context.Context, esClient *elasticsearch.Client, user *loggingv1.ElasticsearchUser, password string) (bool, error) {
+	name := user.ElasticsearchUsername()
+	desired := &elasticsearch.User{
+		Roles:    user.Spec.Roles,
+		FullName: user.Spec.FullName,
+		Email:    user.Spec.Email,
+	}
+	if desired.Roles == nil {
+		desired.Roles = []string{}
+	}
+	metadata, err := securityMeta(desired, user)
+	if err != nil {
+		return false, err
+	}
+	desired.Metadata = metadata
+
+	current, err := esClient.GetUser(ctx, name)
+	if elasticsearch.IsNotFound(err) {
+		desired.Password = password
+		if err := esClient.PutUser(ctx, name, desired); err != nil {
+			return false, fmt.Errorf("failed to create user %s: %w", name, err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user %s: %w", name, err)
+	}
+	if err := checkSecurityOwner(current.Metadata, user); err != nil {
+		return false, fmt.Errorf("user %s is %w", name, err)
+	}
+
+	changed := false
+	inSync, err := elasticsearch.Contains(current, desired)
+	if err != nil {
+		return false, err
+	}
+	if !inSync {
+		// Sans mot de passe, Elasticsearch conserve celui de l'utilisateur
+		if err := esClient.PutUser(ctx, name, desired); err != nil {
+			return false, fmt.Errorf("failed to update user %s: %w", name, err)
+		}
+		changed = true
+	}
+
+	err = ensureUserPassword(ctx, esClient, name, password, func() error {
+		return esClient.ChangePassword(ctx, name, password)
+	})
+	return changed, err
+}
+
+// deleteUser deletes the user from Elasticsearch when it is managed by the resource
+func deleteUser(ctx context.Context, esClient *elasticsearch.Client, user *loggingv1.ElasticsearchUser) error {
+	name := user.ElasticsearchUsername()
+	current, err := esClient.GetUser(ctx, name)
+	if elasticsearch.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user %s: %w", name, err)
+	}
+	if current.Metadata[securityOwnerMeta] != securityOwner(user) {
+		return nil
+	}
+	if err := esClient.DeleteUser(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete user %s: %w", name, err)
+	}
+	log.FromContext(ctx).Info("Deleted Elasticsearch user", "user", name)
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("ElasticsearchUser Controller", func() {
+	var (
+		ctx        context.Context
+		user       *loggingv1.ElasticsearchUser
+		efkStack   *loggingv1.EFKStack
+		fakeClient client.Client
+		reconciler *ElasticsearchUserReconciler
+		api        *fakeSecurityAPI
+		esClient   *elasticsearch.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		user = &loggingv1.ElasticsearchUser{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "team-a", UID: "alice-uid"},
+			Spec: loggingv1.ElasticsearchUserSpec{
+				StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+				Roles:    []string{"team-a-read"},
+				FullName: "Alice",
+			},
+		}
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+		}
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&loggingv1.ElasticsearchUser{}).
+			WithObjects(user, efkStack).
+			Build()
+		reconciler = &ElasticsearchUserReconciler{Client: fakeClient, Scheme: scheme}
+		api = newFakeSecurityAPI()
+		esClient = elasticsearch.NewClient(elasticsearch.Config{URL: api.URL})
+	})
+
+	AfterEach(func() {
+		api.Close()
+	})
+
+	It("Should generate the password and apply the user", func() {
+		password, err := reconciler.userPassword(ctx, user)
+		Expect(err).NotTo(HaveOccurred())
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "alice-es-user", Namespace: "team-a"}, secret)).To(Succeed())
+		Expect(string(secret.Data[loggingv1.UsernameKey])).To(Equal("alice"))
+		Expect(string(secret.Data[loggingv1.PasswordKey])).To(Equal(password))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(reconciler.userPassword(ctx, user)).To(Equal(password))
+
+		changed, err := ensureUser(ctx, esClient, user, password)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(api.passwords).To(HaveKeyWithValue("alice", password))
+		Expect(api.users["alice"]).To(HaveKeyWithValue("full_name", "Alice"))
+
+		api.writes = 0
+		changed, err = ensureUser(ctx, esClient, user, password)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(api.writes).To(BeZero())
+
+		// Rôle et mot de passe modifiés hors de l'opérateur
+		api.users["alice"]["roles"] = []interface{}{"superuser"}
+		api.passwords["alice"] = "changed-by-hand"
+		changed, err = ensureUser(ctx, esClient, user, password)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(api.users["alice"]["roles"]).To(Equal([]interface{}{"team-a-read"}))
+		Expect(api.passwords).To(HaveKeyWithValue("alice", password))
+
+		Expect(deleteUser(ctx, esClient, user)).To(Succeed())
+		Expect(api.users).NotTo(HaveKey("alice"))
+	})
+
+	It("Should only use the stack from the namespaces it allows", func() {
+		efkStack.Spec.Elasticsearch.Security.AuthEnabled = true
+		efkStack.Status.Elasticsearch.State = "Ready"
+		Expect(fakeClient.Update(ctx, efkStack)).To(Succeed())
+		Expect(fakeClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk-elasticsearch-elastic-user", Namespace: "logging"},
+			Data:       map[string][]byte{loggingv1.PasswordKey: []byte("elastic-password")},
+		})).To(Succeed())
+
+		// Le client reste fourni pour supprimer ce que la ressource avait créé
+		stackClient, reason, _, err := securityClient(ctx, fakeClient, user.Spec.StackRef, user.Namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stackClient).NotTo(BeNil())
+		Expect(reason).To(Equal(securityReasonNotAllowed))
+
+		_, reason, _, err = securityClient(ctx, fakeClient, loggingv1.StackReference{Name: "test-efk"}, "logging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reason).To(BeEmpty())
+
+		efkStack.Spec.Elasticsearch.Security.AllowedNamespaces = []string{"team-a"}
+		Expect(fakeClient.Update(ctx, efkStack)).To(Succeed())
+		_, reason, _, err = securityClient(ctx, fakeClient, user.Spec.StackRef, user.Namespace)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reason).To(BeEmpty())
+	})
+
+	It("Should not apply a user granting a privileged role from another namespace", func() {
+		user.Spec.Roles = []string{"superuser"}
+		Expect(fakeClient.Update(ctx, user)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(user)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(user), user)).To(Succeed())
+		Expect(meta.FindStatusCondition(user.Status.Conditions, loggingv1.ConditionReady).Reason).To(Equal(securityReasonInvalid))
+	})
+
+	It("Should read the password from passwordSecret", func() {
+		user.Spec.PasswordSecret = &corev1.LocalObjectReference{Name: "alice-password"}
+		Expect(fakeClient.Update(ctx, user)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(user)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(user), user)).To(Succeed())
+		Expect(meta.FindStatusCondition(user.Status.Conditions, loggingv1.ConditionReady).Reason).To(Equal(securityReasonSecretNotFound))
+
+		Expect(fakeClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: "team-a"},
+			Data:       map[string][]byte{loggingv1.PasswordKey: []byte("user-defined")},
+		})).To(Succeed())
+		Expect(reconciler.userPassword(ctx, user)).To(Equal("user-defined"))
+		Expect(reconciler.mapSecretToElasticsearchUsers(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-password", Namespace: "team-a"},
+		})).To(HaveLen(1))
+
+		// L'authentification est désactivée sur la stack
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(user)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(user), user)).To(Succeed())
+		Expect(user.Status.SecretName).To(Equal("alice-password"))
+		Expect(meta.FindStatusCondition(user.Status.Conditions, loggingv1.ConditionReady).Reason).To(Equal(securityReasonSecurityDisabled))
+	})
+})
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+// Reasons of the Accepted condition of a FluentBitPipeline
+const (
+	pipelineReasonAccepted       = "Accepted"
+	pipelineReasonInvalid        = "Invalid"
+	pipelineReasonParserConflict = "ParserConflict"
+	pipelineReasonIndexIgnored   = "IndexIgnored"
+	pipelineReasonStackNotFound  = "StackNotFound"
+)
+
+// FluentBitPipelineReconciler reports the FluentBitPipelines whose EFKStack does not exist.
+// The fragments of an existing stack are merged and reported by the EFKStackReconciler
+type FluentBitPipelineReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=fluentbitpipelines,verbs=get;list;watch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=fluentbitpipelines/status,verbs=get;update;patch
+
+// Reconcile marks the FluentBitPipeline as not accepted when its EFKStack is missing
+func (r *FluentBitPipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	pipeline := &loggingv1.FluentBitPipeline{}
+	if err := r.Get(ctx, req.NamespacedName, pipeline); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	stackKey := types.NamespacedName{
+		Name:      pipeline.Spec.StackRef.Name,
+		Namespace: pipeline.Spec.StackRef.NamespaceOr(pipeline.Namespace),
+	}
+	err := r.Get(ctx, stackKey, &loggingv1.EFKStack{})
+	if err == nil {
+		// Le fragment est fusionné par la réconciliation de l'EFKStack
+		return ctrl.Result{}, nil
+	}
+	if !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("EFKStack referenced by FluentBitPipeline not found", "stack", stackKey)
+	return ctrl.Result{}, setPipelineAccepted(ctx, r.Client, pipeline, metav1.ConditionFalse, pipelineReasonStackNotFound,
+		fmt.Sprintf("EFKStack %s not found", stackKey))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *FluentBitPipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.FluentBitPipeline{}).
+		Complete(r)
+}
+
+// acceptedPipelines returns the FluentBitPipelines merged into the Fluent Bit configuration
+// of the stack, oldest first, and reports on each of them whether it was accepted
+func (r *EFKStackReconciler) acceptedPipelines(ctx context.Context, efkStack *loggingv1.EFKStack) ([]loggingv1.FluentBitPipeline, error) {
+	logger := log.FromContext(ctx)
+
+	candidates, err := r.stackPipelines(ctx, efkStack)
+	if err != nil {
+		return nil, err
+	}
+	// Le plus ancien fragment l'emporte en cas de conflit
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].CreationTimestamp.Equal(&candidates[j].CreationTimestamp) {
+			return candidates[i].CreationTimestamp.Before(&candidates[j].CreationTimestamp)
+		}
+		return candidates[i].Namespace+"/"+candidates[i].Name < candidates[j].Namespace+"/"+candidates[j].Name
+	})
+
+	// Les noms de parsers sont globaux dans parsers.conf
+	parserOwners := map[string]string{}
+	for _, name := range loggingv1.BuiltinFluentBitParsers {
+		parserOwners[name] = "built-in"
+	}
+	for _, parser := range efkStack.Spec.FluentBit.Config.Parsers {
+		parserOwners[parser.Name] = "EFKStack " + efkStack.Name
+	}
+
+	var accepted []loggingv1.FluentBitPipeline
+	for i := range candidates {
+		pipeline := &candidates[i]
+		status, reason, message := metav1.ConditionTrue, pipelineReasonAccepted, "Merged into the Fluent Bit configuration"
+
+		if errs := pipeline.Validate(); len(errs) > 0 {
+			status, reason, message = metav1.ConditionFalse, pipelineReasonInvalid, errs.ToAggregate().Error()
+		} else if pipeline.Spec.Index != "" && !pipelineIndexRouted(efkStack) {
+			status, reason = metav1.ConditionFalse, pipelineReasonIndexIgnored
+			message = fmt.Sprintf("index %s would be ignored: EFKStack %s has no elasticsearch output using logstashFormat", pipeline.Spec.Index, efkStack.Name)
+			if efkStack.Spec.Elasticsearch.ILM != nil && len(efkStack.Spec.FluentBit.Config.Outputs) == 0 {
+				message = fmt.Sprintf("index %s would be ignored: with elasticsearch.ilm, EFKStack %s writes the logs to the %s data stream", pipeline.Spec.Index, efkStack.Name, logsIndex)
+			}
+		} else {
+			for _, parser := range pipeline.Spec.Parsers {
+				if owner, ok := parserOwners[parser.Name]; ok {
+					status, reason = metav1.ConditionFalse, pipelineReasonParserConflict
+					message = fmt.Sprintf("parser %s is already defined by %s", parser.Name, owner)
+					break
+				}
+			}
+		}
+
+		if status == metav1.ConditionTrue {
+			for _, parser := range pipeline.Spec.Parsers {
+				parserOwners[parser.Name] = fmt.Sprintf("FluentBitPipeline %s/%s", pipeline.Namespace, pipeline.Name)
+			}
+			accepted = append(accepted, *pipeline)
+		} else {
+			logger.Info("Rejected FluentBitPipeline", "pipeline", client.ObjectKeyFromObject(pipeline), "reason", reason, "message", message)
+		}
+
+		if err := setPipelineAccepted(ctx, r.Client, pipeline, status, reason, message); err != nil {
+			return nil, err
+		}
+	}
+
+	return accepted, nil
+}
+
+// stackPipelines returns the FluentBitPipelines referencing the stack
+func (r *EFKStackReconciler) stackPipelines(ctx context.Context, efkStack *loggingv1.EFKStack) ([]loggingv1.FluentBitPipeline, error) {
+	pipelines := &loggingv1.FluentBitPipelineList{}
+	if err := r.List(ctx, pipelines); err != nil {
+		return nil, fmt.Errorf("failed to list FluentBitPipelines: %w", err)
+	}
+
+	var referencing []loggingv1.FluentBitPipeline
+	for _, pipeline := range pipelines.Items {
+		if pipeline.Spec.StackRef.Name == efkStack.Name && pipeline.Spec.StackRef.NamespaceOr(pipeline.Namespace) == efkStack.Namespace {
+			referencing = append(referencing, pipeline)
+		}
+	}
+	return referencing, nil
+}
+
+// pipelineIndexRouted reports whether an output of the stack takes its index prefix from the
+// FluentBitPipelines. Only the elasticsearch outputs using logstashFormat do, the data stream
+// written by the default output with ILM does not
+func pipelineIndexRouted(efkStack *loggingv1.EFKStack) bool {
+	outputs := efkStack.Spec.FluentBit.Config.Outputs
+	if len(outputs) == 0 {
+		return efkStack.Spec.Elasticsearch.ILM == nil
+	}
+	for _, output := range outputs {
+		if output.Elasticsearch != nil && output.Elasticsearch.LogstashFormat {
+			return true
+		}
+	}
+	return false
+}
+
+// setPipelineAccepted updates the Accepted condition, only writing the status when it changed
+func setPipelineAccepted(ctx context.Context, c client.Client, pipeline *loggingv1.FluentBitPipeline, status metav1.ConditionStatus, reason, message string) error {
+	changed := meta.SetStatusCondition(&pipeline.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionAccepted,
+		Status:             status,
+		ObservedGeneration: pipeline.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if !changed && pipeline.Status.ObservedGeneration == pipeline.Generation {
+		return nil
+	}
+	pipeline.Status.ObservedGeneration = pipeline.Generation
+
+	if err := c.Status().Update(ctx, pipeline); err != nil {
+		return fmt.Errorf("failed to update FluentBitPipeline %s/%s status: %w", pipeline.Namespace, pipeline.Name, err)
+	}
+	return nil
+}
+
+// mapFluentBitPipelineToEFKStack enqueues the EFKStack referenced by a FluentBitPipeline
+func (r *EFKStackReconciler) mapFluentBitPipelineToEFKStack(ctx context.Context, obj client.Object) []reconcile.Request {
+	pipeline, ok := obj.(*loggingv1.FluentBitPipeline)
+	if !ok {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      pipeline.Spec.StackRef.Name,
+			Namespace: pipeline.Spec.StackRef.NamespaceOr(pipeline.Namespace),
+		},
+	}}
+}
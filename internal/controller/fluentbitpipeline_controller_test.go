@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+var _ = Describe("FluentBitPipeline Controller", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		efkStack   *loggingv1.EFKStack
+	)
+
+	newPipeline := func(name, namespace string, age time.Duration, parsers ...string) *loggingv1.FluentBitPipeline {
+		pipeline := &loggingv1.FluentBitPipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Spec: loggingv1.FluentBitPipelineSpec{
+				StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+			},
+		}
+		for _, parser := range parsers {
+			pipeline.Spec.Parsers = append(pipeline.Spec.Parsers, loggingv1.FluentBitParser{Name: parser, Format: "json"})
+		}
+		return pipeline
+	}
+
+	accepted := func(name, namespace string) *metav1.Condition {
+		pipeline := &loggingv1.FluentBitPipeline{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, pipeline)).To(Succeed())
+		return meta.FindStatusCondition(pipeline.Status.Conditions, loggingv1.ConditionAccepted)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(scheme)
+
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+		}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&loggingv1.FluentBitPipeline{}).
+			WithObjects(
+				efkStack,
+				newPipeline("first", "team-a", 2*time.Hour, "shared"),
+				newPipeline("second", "team-b", time.Hour, "shared"),
+				newPipeline("other-stack", "team-c", time.Hour),
+			).
+			Build()
+
+		other := &loggingv1.FluentBitPipeline{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "other-stack", Namespace: "team-c"}, other)).To(Succeed())
+		other.Spec.StackRef.Name = "missing"
+		Expect(fakeClient.Update(ctx, other)).To(Succeed())
+	})
+
+	It("Should merge the fragments of the stack and reject parser conflicts", func() {
+		reconciler := &EFKStackReconciler{Client: fakeClient}
+
+		pipelines, err := reconciler.acceptedPipelines(ctx, efkStack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pipelines).To(HaveLen(1))
+		Expect(pipelines[0].Name).To(Equal("first"))
+
+		Expect(accepted("first", "team-a").Status).To(Equal(metav1.ConditionTrue))
+		condition := accepted("second", "team-b")
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(pipelineReasonParserConflict))
+		Expect(accepted("other-stack", "team-c")).To(BeNil())
+	})
+
+	It("Should reject an index ignored by the data stream of the stack", func() {
+		efkStack.Spec.Elasticsearch.ILM = &loggingv1.IndexLifecycleSpec{RetentionDays: 30}
+		pipeline := &loggingv1.FluentBitPipeline{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "first", Namespace: "team-a"}, pipeline)).To(Succeed())
+		pipeline.Spec.Index = "team-a"
+		Expect(fakeClient.Update(ctx, pipeline)).To(Succeed())
+		reconciler := &EFKStackReconciler{Client: fakeClient}
+
+		pipelines, err := reconciler.acceptedPipelines(ctx, efkStack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pipelines).To(HaveLen(1))
+		Expect(pipelines[0].Name).To(Equal("second"))
+		condition := accepted("first", "team-a")
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(pipelineReasonIndexIgnored))
+		Expect(condition.Message).To(ContainSubstring("fluent-bit data stream"))
+
+		// An elasticsearch output of the stack using logstashFormat routes the logs to the index
+		efkStack.Spec.FluentBit.Config.Outputs = []loggingv1.FluentBitOutput{
+			{Elasticsearch: &loggingv1.ElasticsearchOutput{LogstashFormat: true}},
+		}
+		pipelines, err = reconciler.acceptedPipelines(ctx, efkStack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pipelines).To(HaveLen(1))
+		Expect(pipelines[0].Name).To(Equal("first"))
+		Expect(accepted("first", "team-a").Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("Should report a missing stack", func() {
+		reconciler := &FluentBitPipelineReconciler{Client: fakeClient}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "other-stack", Namespace: "team-c"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		condition := accepted("other-stack", "team-c")
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(pipelineReasonStackNotFound))
+	})
+})
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/certificates"
+	"github.com/zlorgoncho1/efk-operator/internal/kibana"
+)
+
+const (
+	// kibanaRetryInterval is the delay before retrying a resource that could not be applied to Kibana
+	kibanaRetryInterval = 30 * time.Second
+	// kibanaResyncInterval is the delay between two checks of a resource applied to Kibana
+	kibanaResyncInterval = 5 * time.Minute
+)
+
+// Reasons of the Ready condition shared by the resources applied through the Kibana API
+const (
+	kibanaReasonStackNotFound = "StackNotFound"
+	kibanaReasonPending       = "Pending"
+)
+
+// kibanaURL returns the in-cluster URL of the Kibana service of a stack. Kibana serves HTTPS
+// when it has a certificate, see kibanaTLSEnabled
+func kibanaURL(efkStack *loggingv1.EFKStack, namespace string) string {
+	scheme := "http"
+	if kibanaTLSEnabled(efkStack) {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s-kibana.%s.svc:5601", scheme, efkStack.Name, namespace)
+}
+
+// kibanaTLSEnabled returns true when Kibana serves a certificate requested from cert-manager or,
+// with spec.global.tls.enabled, signed by the CA of the stack
+func kibanaTLSEnabled(efkStack *loggingv1.EFKStack) bool {
+	return managedCertificates(efkStack) && (efkStack.Spec.CertificateIssuer() != nil || efkStack.Spec.Global.TLS.Enabled)
+}
+
+// newKibanaClient builds a client for the Kibana API of a stack. With TLS, the server is verified
+// with the CA of its certificate Secret. With authentication enabled, the client authenticates
+// as the elastic user
+func newKibanaClient(ctx context.Context, c client.Client, efkStack *loggingv1.EFKStack, namespace string) (*kibana.Client, error) {
+	cfg := kibana.Config{
+		URL: kibanaURL(efkStack, namespace),
+	}
+	if kibanaTLSEnabled(efkStack) {
+		secretName := kibanaCertificateSecretName(efkStack)
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Kibana certificate: %w", err)
+		}
+		httpClient, err := kibanaHTTPClients.get(client.ObjectKeyFromObject(efkStack), secret.Data[certificates.CAKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA in Secret %s: %w", secretName, err)
+		}
+		cfg.HTTPClient = httpClient
+	}
+	if efkStack.Spec.Elasticsearch.Security.AuthEnabled {
+		elasticName, _, _ := credentialSecretNames(efkStack)
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, types.NamespacedName{Name: elasticName, Namespace: namespace}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Elasticsearch credentials: %w", err)
+		}
+		cfg.Username = elasticUser
+		cfg.Password = string(secret.Data[loggingv1.PasswordKey])
+	}
+	return kibana.NewClient(cfg), nil
+}
+
+// kibanaStackClient returns a client for the Kibana of the referenced stack. When Kibana cannot
+// be used, the client is nil and the reason and message explain why
+func kibanaStackClient(ctx context.Context, c client.Client, ref loggingv1.StackReference, namespace string) (*kibana.Client, string, string, error) {
+	stackKey := types.NamespacedName{Name: ref.Name, Namespace: ref.NamespaceOr(namespace)}
+	efkStack := &loggingv1.EFKStack{}
+	if err := c.Get(ctx, stackKey, efkStack); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, "", "", err
+		}
+		return nil, kibanaReasonStackNotFound, fmt.Sprintf("EFKStack %s not found", stackKey), nil
+	}
+	if !efkStack.DeletionTimestamp.IsZero() {
+		return nil, kibanaReasonStackNotFound, fmt.Sprintf("EFKStack %s is being deleted", stackKey), nil
+	}
+	if efkStack.Status.Kibana.State != "Ready" {
+		return nil, kibanaReasonPending, "Waiting for Kibana to be ready", nil
+	}
+
+	kbClient, err := newKibanaClient(ctx, c, efkStack, stackNamespace(efkStack))
+	if err != nil {
+		return nil, "", "", err
+	}
+	return kbClient, "", "", nil
+}
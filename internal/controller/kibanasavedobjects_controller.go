@@ -0,0 +1,258 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/kibana"
+)
+
+// Reasons of the Ready condition of KibanaSavedObjects
+const (
+	savedObjectsReasonImported          = "Imported"
+	savedObjectsReasonImportErrors      = "ImportErrors"
+	savedObjectsReasonImportFailed      = "ImportFailed"
+	savedObjectsReasonInvalid           = "Invalid"
+	savedObjectsReasonConfigMapNotFound = "ConfigMapNotFound"
+)
+
+// KibanaSavedObjectsReconciler imports KibanaSavedObjects into the Kibana of their EFKStack
+type KibanaSavedObjectsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=kibanasavedobjects,verbs=get;list;watch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=kibanasavedobjects/status,verbs=get;update;patch
+
+// Reconcile imports the objects once Kibana is ready, then again when they change or when
+// some of them disappear from Kibana, for instance after the stack was recreated
+func (r *KibanaSavedObjectsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	savedObjects := &loggingv1.KibanaSavedObjects{}
+	if err := r.Get(ctx, req.NamespacedName, savedObjects); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ndjson, reason, message, err := r.savedObjectsContent(ctx, savedObjects)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if reason != "" {
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, savedObjects, metav1.ConditionFalse, reason, message)
+	}
+
+	kbClient, reason, message, err := kibanaStackClient(ctx, r.Client, savedObjects.Spec.StackRef, savedObjects.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if kbClient == nil {
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, savedObjects, metav1.ConditionFalse, reason, message)
+	}
+	imported, err := importSavedObjects(ctx, kbClient, savedObjects, ndjson)
+	if err != nil {
+		logger.Error(err, "Failed to import Kibana saved objects")
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, savedObjects, metav1.ConditionFalse,
+			savedObjectsReasonImportFailed, err.Error())
+	}
+	if imported {
+		logger.Info("Imported Kibana saved objects", "space", savedObjectsSpace(savedObjects),
+			"imported", len(savedObjects.Status.ImportedObjects), "errors", len(savedObjects.Status.Errors))
+	}
+
+	if count := len(savedObjects.Status.Errors); count > 0 {
+		return ctrl.Result{RequeueAfter: kibanaResyncInterval}, r.setReady(ctx, savedObjects, metav1.ConditionFalse,
+			savedObjectsReasonImportErrors, fmt.Sprintf("%d objects rejected by Kibana, see status.errors", count))
+	}
+	return ctrl.Result{RequeueAfter: kibanaResyncInterval}, r.setReady(ctx, savedObjects, metav1.ConditionTrue,
+		savedObjectsReasonImported, fmt.Sprintf("%d objects imported", len(savedObjects.Status.ImportedObjects)))
+}
+
+// savedObjectsContent concatenates the inline objects and the exports of the ConfigMaps, sorted
+// by key. A missing ConfigMap or an invalid export is reported with a reason and a message
+func (r *KibanaSavedObjectsReconciler) savedObjectsContent(ctx context.Context, savedObjects *loggingv1.KibanaSavedObjects) (string, string, string, error) {
+	var chunks []string
+	if savedObjects.Spec.Objects != "" {
+		chunks = append(chunks, savedObjects.Spec.Objects)
+	}
+
+	for _, ref := range savedObjects.Spec.ConfigMapRefs {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: savedObjects.Namespace}, configMap); err != nil {
+			if !errors.IsNotFound(err) {
+				return "", "", "", err
+			}
+			return "", savedObjectsReasonConfigMapNotFound, fmt.Sprintf("ConfigMap %s not found", ref.Name), nil
+		}
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := loggingv1.ValidateSavedObjectsNDJSON(configMap.Data[key]); err != nil {
+				return "", savedObjectsReasonInvalid, fmt.Sprintf("ConfigMap %s, key %s: %v", ref.Name, key, err), nil
+			}
+			chunks = append(chunks, configMap.Data[key])
+		}
+	}
+
+	for i := range chunks {
+		chunks[i] = strings.TrimRight(chunks[i], "\n")
+	}
+	return strings.Join(chunks, "\n") + "\n", "", "", nil
+}
+
+// setReady updates the Ready condition, only writing the status when it changed
+func (r *KibanaSavedObjectsReconciler) setReady(ctx context.Context, savedObjects *loggingv1.KibanaSavedObjects, status metav1.ConditionStatus, reason, message string) error {
+	previous := savedObjects.Status.DeepCopy()
+	meta.SetStatusCondition(&savedObjects.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: savedObjects.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	savedObjects.Status.ObservedGeneration = savedObjects.Generation
+	if equality.Semantic.DeepEqual(previous, &savedObjects.Status) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, savedObjects); err != nil {
+		return fmt.Errorf("failed to update KibanaSavedObjects %s/%s status: %w", savedObjects.Namespace, savedObjects.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KibanaSavedObjectsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.KibanaSavedObjects{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapConfigMapToKibanaSavedObjects)).
+		Complete(r)
+}
+
+// mapConfigMapToKibanaSavedObjects enqueues the KibanaSavedObjects importing a ConfigMap
+func (r *KibanaSavedObjectsReconciler) mapConfigMapToKibanaSavedObjects(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &loggingv1.KibanaSavedObjectsList{}
+	if err := r.List(ctx, list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list KibanaSavedObjects")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, savedObjects := range list.Items {
+		for _, ref := range savedObjects.Spec.ConfigMapRefs {
+			if ref.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&savedObjects)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// importSavedObjects imports the objects when their content, space or overwrite option changed,
+// when the previous import had errors, or when imported objects are missing from Kibana. The
+// status lists the imported and rejected objects. It returns true when an import was done
+func importSavedObjects(ctx context.Context, kbClient *kibana.Client, savedObjects *loggingv1.KibanaSavedObjects, ndjson string) (bool, error) {
+	space := savedObjectsSpace(savedObjects)
+	overwrite := savedObjects.OverwriteEnabled()
+	sum := sha256.Sum256([]byte(space + "\n" + strconv.FormatBool(overwrite) + "\n" + ndjson))
+	checksum := hex.EncodeToString(sum[:8])
+	status := &savedObjects.Status
+
+	if status.Checksum == checksum && len(status.Errors) == 0 {
+		refs := make([]kibana.SavedObjectReference, 0, len(status.ImportedObjects))
+		for _, object := range status.ImportedObjects {
+			refs = append(refs, kibana.SavedObjectReference{Type: object.Type, ID: object.ID})
+		}
+		missing, err := kbClient.MissingSavedObjects(ctx, space, refs)
+		if err != nil {
+			return false, fmt.Errorf("failed to check the imported saved objects: %w", err)
+		}
+		if len(missing) == 0 {
+			return false, nil
+		}
+	}
+
+	result, err := kbClient.ImportSavedObjects(ctx, space, []byte(ndjson), overwrite)
+	if err != nil {
+		return false, fmt.Errorf("failed to import saved objects into space %s: %w", space, err)
+	}
+
+	now := metav1.Now()
+	status.Checksum = checksum
+	status.LastImportTime = &now
+	status.ImportedObjects = nil
+	for _, object := range result.SuccessResults {
+		id := object.ID
+		if object.DestinationID != "" {
+			id = object.DestinationID
+		}
+		status.ImportedObjects = append(status.ImportedObjects, loggingv1.ImportedSavedObject{Type: object.Type, ID: id, Title: object.Meta.Title})
+	}
+	status.Errors = nil
+	for _, object := range result.Errors {
+		title := object.Meta.Title
+		if title == "" {
+			title = object.Title
+		}
+		message := object.Error.Message
+		if message == "" && len(object.Error.References) > 0 {
+			missing := make([]string, 0, len(object.Error.References))
+			for _, ref := range object.Error.References {
+				missing = append(missing, ref.Type+"/"+ref.ID)
+			}
+			message = "missing references: " + strings.Join(missing, ", ")
+		}
+		status.Errors = append(status.Errors, loggingv1.SavedObjectError{
+			Type: object.Type, ID: object.ID, Title: title, Reason: object.Error.Type, Message: message,
+		})
+	}
+	return true, nil
+}
+
+// savedObjectsSpace returns the Kibana space receiving the objects
+func savedObjectsSpace(savedObjects *loggingv1.KibanaSavedObjects) string {
+	if savedObjects.Spec.Space != "" {
+		return savedObjects.Spec.Space
+	}
+	return kibana.DefaultSpace
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/kibana"
+)
+
+var _ = Describe("KibanaSavedObjects Controller", func() {
+	const dashboard = `{"type":"dashboard","id":"team-a-overview","attributes":{"title":"Overview"}}`
+
+	var (
+		ctx          context.Context
+		savedObjects *loggingv1.KibanaSavedObjects
+		server       *httptest.Server
+		kbClient     *kibana.Client
+		stored       map[string]bool
+		imports      []string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		savedObjects = &loggingv1.KibanaSavedObjects{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a-dashboards", Namespace: "team-a", Generation: 1},
+			Spec: loggingv1.KibanaSavedObjectsSpec{
+				StackRef: loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+				Space:    "team-a",
+				Objects:  dashboard,
+			},
+		}
+
+		// Faux Kibana : les objets importés sont conservés par type/id, sauf ceux sans titre
+		stored = map[string]bool{}
+		imports = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/s/team-a/api/saved_objects/_import", func(w http.ResponseWriter, r *http.Request) {
+			imports = append(imports, r.URL.Query().Get("overwrite"))
+			file, _, err := r.FormFile("file")
+			Expect(err).NotTo(HaveOccurred())
+			content, _ := io.ReadAll(file)
+			result := kibana.ImportResult{Success: true}
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				object := struct {
+					Type       string                 `json:"type"`
+					ID         string                 `json:"id"`
+					Attributes map[string]interface{} `json:"attributes"`
+				}{}
+				Expect(json.Unmarshal([]byte(line), &object)).To(Succeed())
+				if object.Attributes["title"] == nil {
+					result.Success = false
+					result.Errors = append(result.Errors, kibana.ImportError{Type: object.Type, ID: object.ID,
+						Error: kibana.ImportErrorDetail{Type: "missing_references",
+							References: []kibana.SavedObjectReference{{Type: "index-pattern", ID: "logs"}}}})
+					continue
+				}
+				stored[object.Type+"/"+object.ID] = true
+				result.SuccessCount++
+				result.SuccessResults = append(result.SuccessResults, kibana.ImportSuccess{Type: object.Type, ID: object.ID,
+					Meta: kibana.SavedObjectMeta{Title: object.Attributes["title"].(string)}})
+			}
+			Expect(json.NewEncoder(w).Encode(result)).To(Succeed())
+		})
+		mux.HandleFunc("/s/team-a/api/saved_objects/_bulk_get", func(w http.ResponseWriter, r *http.Request) {
+			var refs []kibana.SavedObjectReference
+			Expect(json.NewDecoder(r.Body).Decode(&refs)).To(Succeed())
+			var objects []map[string]interface{}
+			for _, ref := range refs {
+				object := map[string]interface{}{"type": ref.Type, "id": ref.ID}
+				if !stored[ref.Type+"/"+ref.ID] {
+					object["error"] = map[string]interface{}{"statusCode": 404}
+				}
+				objects = append(objects, object)
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"saved_objects": objects})).To(Succeed())
+		})
+		server = httptest.NewServer(mux)
+		kbClient = kibana.NewClient(kibana.Config{URL: server.URL})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should import the objects and only import them again when needed", func() {
+		imported, err := importSavedObjects(ctx, kbClient, savedObjects, dashboard+"\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeTrue())
+		Expect(imports).To(Equal([]string{"true"}))
+		Expect(savedObjects.Status.ImportedObjects).To(Equal([]loggingv1.ImportedSavedObject{
+			{Type: "dashboard", ID: "team-a-overview", Title: "Overview"},
+		}))
+		Expect(savedObjects.Status.Errors).To(BeEmpty())
+		Expect(savedObjects.Status.LastImportTime).NotTo(BeNil())
+
+		// Contenu inchangé et objets présents : pas de nouvel import
+		imported, err = importSavedObjects(ctx, kbClient, savedObjects, dashboard+"\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeFalse())
+
+		// Objet supprimé dans Kibana
+		delete(stored, "dashboard/team-a-overview")
+		imported, err = importSavedObjects(ctx, kbClient, savedObjects, dashboard+"\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeTrue())
+
+		// Option overwrite modifiée
+		overwrite := false
+		savedObjects.Spec.Overwrite = &overwrite
+		_, err = importSavedObjects(ctx, kbClient, savedObjects, dashboard+"\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imports).To(Equal([]string{"true", "true", "false"}))
+	})
+
+	It("Should report the objects rejected by Kibana", func() {
+		ndjson := dashboard + "\n" + `{"type":"visualization","id":"errors","attributes":{}}` + "\n"
+		_, err := importSavedObjects(ctx, kbClient, savedObjects, ndjson)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(savedObjects.Status.ImportedObjects).To(HaveLen(1))
+		Expect(savedObjects.Status.Errors).To(Equal([]loggingv1.SavedObjectError{{
+			Type: "visualization", ID: "errors", Reason: "missing_references", Message: "missing references: index-pattern/logs",
+		}}))
+
+		// Les erreurs sont retentées à chaque resynchronisation
+		imported, err := importSavedObjects(ctx, kbClient, savedObjects, ndjson)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeTrue())
+	})
+
+	It("Should read the objects of the ConfigMaps and wait for the stack", func() {
+		savedObjects.Spec.ConfigMapRefs = []corev1.LocalObjectReference{{Name: "dashboards"}}
+		efkStack := &loggingv1.EFKStack{ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"}}
+
+		scheme := runtime.NewScheme()
+		_ = clientgoscheme.AddToScheme(scheme)
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&loggingv1.KibanaSavedObjects{}).
+			WithObjects(savedObjects, efkStack).
+			Build()
+		reconciler := &KibanaSavedObjectsReconciler{Client: fakeClient, Scheme: scheme}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(savedObjects)}
+		reason := func() string {
+			current := &loggingv1.KibanaSavedObjects{}
+			Expect(fakeClient.Get(ctx, req.NamespacedName, current)).To(Succeed())
+			return meta.FindStatusCondition(current.Status.Conditions, loggingv1.ConditionReady).Reason
+		}
+
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reason()).To(Equal(savedObjectsReasonConfigMapNotFound))
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "team-a"},
+			Data:       map[string]string{"b.ndjson": `{"type":"lens","id":"b"}`, "a.ndjson": `{"type":"lens","id":"a"}` + "\n"},
+		}
+		Expect(fakeClient.Create(ctx, configMap)).To(Succeed())
+		ndjson, invalid, _, err := reconciler.savedObjectsContent(ctx, savedObjects)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invalid).To(BeEmpty())
+		Expect(ndjson).To(Equal(dashboard + "\n" + `{"type":"lens","id":"a"}` + "\n" + `{"type":"lens","id":"b"}` + "\n"))
+		Expect(reconciler.mapConfigMapToKibanaSavedObjects(ctx, configMap)).To(HaveLen(1))
+
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reason()).To(Equal(kibanaReasonPending))
+
+		configMap.Data["c.ndjson"] = `{"type":"lens"}`
+		Expect(fakeClient.Update(ctx, configMap)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reason()).To(Equal(savedObjectsReasonInvalid))
+	})
+})
@@ -0,0 +1,328 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/kibana"
+)
+
+// kibanaSpaceFinalizer removes the spaces from Kibana before their resource is deleted
+const kibanaSpaceFinalizer = "logging.efk.crds.io/kibana-space"
+
+// Reasons of the Ready condition of KibanaSpaces
+const (
+	spaceReasonApplied     = "Applied"
+	spaceReasonConflict    = "Conflict"
+	spaceReasonApplyFailed = "ApplyFailed"
+)
+
+// KibanaSpaceReconciler applies KibanaSpaces to the Kibana of their EFKStack and deletes them
+// with their resource
+type KibanaSpaceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=kibanaspaces,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=kibanaspaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=kibanaspaces/finalizers,verbs=update
+
+// Reconcile applies the space and its default data view once Kibana is ready, and checks them
+// periodically so that the changes made in Kibana are reverted
+func (r *KibanaSpaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	space := &loggingv1.KibanaSpace{}
+	if err := r.Get(ctx, req.NamespacedName, space); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	kbClient, reason, message, err := kibanaStackClient(ctx, r.Client, space.Spec.StackRef, space.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	owner, err := r.spaceOwner(ctx, space)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	id := space.KibanaSpaceID()
+
+	if !space.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(space, kibanaSpaceFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		switch {
+		case reason == kibanaReasonPending:
+			return ctrl.Result{RequeueAfter: kibanaRetryInterval}, nil
+		case reason == "" && owner == client.ObjectKeyFromObject(space):
+			if err := deleteSpace(ctx, kbClient, id); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		controllerutil.RemoveFinalizer(space, kibanaSpaceFinalizer)
+		return ctrl.Result{}, r.Update(ctx, space)
+	}
+	if controllerutil.AddFinalizer(space, kibanaSpaceFinalizer) {
+		if err := r.Update(ctx, space); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if kbClient == nil {
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, space, metav1.ConditionFalse, reason, message)
+	}
+	if owner != client.ObjectKeyFromObject(space) {
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, space, metav1.ConditionFalse, spaceReasonConflict,
+			fmt.Sprintf("Space %s is managed by KibanaSpace %s", id, owner))
+	}
+
+	spaceChanged, err := ensureSpace(ctx, kbClient, space)
+	if err == nil {
+		var dataViewChanged bool
+		dataViewChanged, err = ensureDefaultDataView(ctx, kbClient, space)
+		spaceChanged = spaceChanged || dataViewChanged
+	}
+	if err != nil {
+		logger.Error(err, "Failed to apply Kibana space", "space", id)
+		return ctrl.Result{RequeueAfter: kibanaRetryInterval}, r.setReady(ctx, space, metav1.ConditionFalse, spaceReasonApplyFailed, err.Error())
+	}
+	if spaceChanged {
+		logger.Info("Applied Kibana space", "space", id)
+	}
+
+	space.Status.SpaceID = id
+	space.Status.DataViewID = ""
+	if space.Spec.DefaultDataView != nil {
+		space.Status.DataViewID = space.DefaultDataViewID()
+	}
+	return ctrl.Result{RequeueAfter: kibanaResyncInterval}, r.setReady(ctx, space, metav1.ConditionTrue, spaceReasonApplied,
+		fmt.Sprintf("Space %s applied", id))
+}
+
+// spaceOwner returns the KibanaSpace managing the space in Kibana: the oldest of the resources
+// declaring the same space on the same stack
+func (r *KibanaSpaceReconciler) spaceOwner(ctx context.Context, space *loggingv1.KibanaSpace) (types.NamespacedName, error) {
+	spaces := &loggingv1.KibanaSpaceList{}
+	if err := r.List(ctx, spaces); err != nil {
+		return types.NamespacedName{}, fmt.Errorf("failed to list KibanaSpaces: %w", err)
+	}
+
+	owner := space
+	for i := range spaces.Items {
+		candidate := &spaces.Items[i]
+		if candidate.KibanaSpaceID() != space.KibanaSpaceID() || candidate.Spec.StackRef.Name != space.Spec.StackRef.Name ||
+			candidate.Spec.StackRef.NamespaceOr(candidate.Namespace) != space.Spec.StackRef.NamespaceOr(space.Namespace) {
+			continue
+		}
+		if !candidate.CreationTimestamp.Equal(&owner.CreationTimestamp) {
+			if candidate.CreationTimestamp.Before(&owner.CreationTimestamp) {
+				owner = candidate
+			}
+			continue
+		}
+		if candidate.Namespace+"/"+candidate.Name < owner.Namespace+"/"+owner.Name {
+			owner = candidate
+		}
+	}
+	return client.ObjectKeyFromObject(owner), nil
+}
+
+// setReady updates the Ready condition, only writing the status when it changed
+func (r *KibanaSpaceReconciler) setReady(ctx context.Context, space *loggingv1.KibanaSpace, status metav1.ConditionStatus, reason, message string) error {
+	previous := space.Status.DeepCopy()
+	meta.SetStatusCondition(&space.Status.Conditions, metav1.Condition{
+		Type:               loggingv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: space.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	space.Status.ObservedGeneration = space.Generation
+	if equality.Semantic.DeepEqual(previous, &space.Status) {
+		return nil
+	}
+
+	if err := r.Status().Update(ctx, space); err != nil {
+		return fmt.Errorf("failed to update KibanaSpace %s/%s status: %w", space.Namespace, space.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KibanaSpaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.KibanaSpace{}).
+		Complete(r)
+}
+
+// ensureSpace creates the space or updates it when it differs from the spec, and returns true
+// when it was written
+func ensureSpace(ctx context.Context, kbClient *kibana.Client, space *loggingv1.KibanaSpace) (bool, error) {
+	desired := desiredSpace(space)
+	current, err := kbClient.GetSpace(ctx, desired.ID)
+	if kibana.IsNotFound(err) {
+		if err := kbClient.CreateSpace(ctx, desired); err != nil {
+			return false, fmt.Errorf("failed to create space %s: %w", desired.ID, err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get space %s: %w", desired.ID, err)
+	}
+	if spaceInSync(current, desired) {
+		return false, nil
+	}
+	if err := kbClient.UpdateSpace(ctx, desired); err != nil {
+		return false, fmt.Errorf("failed to update space %s: %w", desired.ID, err)
+	}
+	return true, nil
+}
+
+// desiredSpace converts the spec of a KibanaSpace into a space of the Kibana API
+func desiredSpace(space *loggingv1.KibanaSpace) *kibana.Space {
+	desired := &kibana.Space{
+		ID:          space.KibanaSpaceID(),
+		Name:        space.Spec.DisplayName,
+		Description: space.Spec.Description,
+		Color:       space.Spec.Color,
+		Initials:    space.Spec.Initials,
+	}
+	if desired.Name == "" {
+		desired.Name = desired.ID
+	}
+	if len(space.Spec.DisabledFeatures) > 0 {
+		desired.DisabledFeatures = append([]string(nil), space.Spec.DisabledFeatures...)
+		sort.Strings(desired.DisabledFeatures)
+	}
+	return desired
+}
+
+// spaceInSync returns true when the space of Kibana matches the desired one. The color and the
+// initials left empty are chosen by Kibana
+func spaceInSync(current, desired *kibana.Space) bool {
+	if current.Name != desired.Name || current.Description != desired.Description {
+		return false
+	}
+	if desired.Color != "" && !strings.EqualFold(current.Color, desired.Color) {
+		return false
+	}
+	if desired.Initials != "" && current.Initials != desired.Initials {
+		return false
+	}
+	features := append([]string(nil), current.DisabledFeatures...)
+	sort.Strings(features)
+	return strings.Join(features, ",") == strings.Join(desired.DisabledFeatures, ",")
+}
+
+// ensureDefaultDataView creates or replaces the default data view of the space when it differs
+// from the spec, and makes it the default one. It returns true when something was written
+func ensureDefaultDataView(ctx context.Context, kbClient *kibana.Client, space *loggingv1.KibanaSpace) (bool, error) {
+	spec := space.Spec.DefaultDataView
+	if spec == nil {
+		return false, nil
+	}
+	id := space.KibanaSpaceID()
+	desired := &kibana.DataView{
+		ID:            space.DefaultDataViewID(),
+		Title:         spec.Pattern,
+		Name:          spec.Name,
+		TimeFieldName: spec.TimeField,
+	}
+	if desired.Name == "" {
+		desired.Name = spec.Pattern
+	}
+	if desired.TimeFieldName == "" {
+		desired.TimeFieldName = loggingv1.DefaultKibanaTimeField
+	}
+
+	changed := false
+	current, err := kbClient.GetDataView(ctx, id, desired.ID)
+	if err != nil && !kibana.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get data view %s: %w", desired.ID, err)
+	}
+	if err != nil || *current != *desired {
+		if err := kbClient.PutDataView(ctx, id, desired); err != nil {
+			return false, fmt.Errorf("failed to apply data view %s: %w", desired.ID, err)
+		}
+		changed = true
+	}
+
+	defaultID, err := kbClient.GetDefaultDataView(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the default data view of space %s: %w", id, err)
+	}
+	if defaultID != desired.ID {
+		if err := kbClient.SetDefaultDataView(ctx, id, desired.ID); err != nil {
+			return false, fmt.Errorf("failed to set the default data view of space %s: %w", id, err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// deleteSpace deletes the space from Kibana with its saved objects. The default space is
+// reserved and cannot be deleted
+func deleteSpace(ctx context.Context, kbClient *kibana.Client, id string) error {
+	current, err := kbClient.GetSpace(ctx, id)
+	if kibana.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get space %s: %w", id, err)
+	}
+	if current.Reserved {
+		return nil
+	}
+	if err := kbClient.DeleteSpace(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete space %s: %w", id, err)
+	}
+	log.FromContext(ctx).Info("Deleted Kibana space", "space", id)
+	return nil
+}
+
+// mapKibanaSpaceToElasticsearchRoles enqueues the ElasticsearchRoles bound to a KibanaSpace
+func mapKibanaSpaceToElasticsearchRoles(ctx context.Context, obj client.Object) []reconcile.Request {
+	space, ok := obj.(*loggingv1.KibanaSpace)
+	if !ok {
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(space.Spec.Roles))
+	for _, binding := range space.Spec.Roles {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: binding.Name, Namespace: space.Namespace},
+		})
+	}
+	return requests
+}
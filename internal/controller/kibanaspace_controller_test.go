@@ -0,0 +1,207 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/kibana"
+)
+
+var _ = Describe("KibanaSpace Controller", func() {
+	var (
+		ctx         context.Context
+		space       *loggingv1.KibanaSpace
+		server      *httptest.Server
+		kbClient    *kibana.Client
+		spaces      map[string]*kibana.Space
+		dataViews   map[string]*kibana.DataView
+		defaultView string
+		writes      int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		space = &loggingv1.KibanaSpace{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+			Spec: loggingv1.KibanaSpaceSpec{
+				StackRef:         loggingv1.StackReference{Name: "test-efk", Namespace: "logging"},
+				DisplayName:      "Team A",
+				Color:            "#AABBCC",
+				DisabledFeatures: []string{"ml", "canvas"},
+				DefaultDataView:  &loggingv1.DataViewSpec{Pattern: "team-a-*"},
+			},
+		}
+
+		// Faux Kibana : espaces, data views et data view par défaut de l'espace team-a
+		spaces = map[string]*kibana.Space{"default": {ID: "default", Name: "Default", Reserved: true}}
+		dataViews = map[string]*kibana.DataView{}
+		defaultView = ""
+		writes = 0
+		decode := func(r *http.Request, out interface{}) {
+			writes++
+			Expect(json.NewDecoder(r.Body).Decode(out)).To(Succeed())
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/spaces/space", func(w http.ResponseWriter, r *http.Request) {
+			created := &kibana.Space{}
+			decode(r, created)
+			spaces[created.ID] = created
+		})
+		mux.HandleFunc("/api/spaces/space/", func(w http.ResponseWriter, r *http.Request) {
+			id := strings.TrimPrefix(r.URL.Path, "/api/spaces/space/")
+			switch r.Method {
+			case http.MethodGet:
+				if spaces[id] == nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				Expect(json.NewEncoder(w).Encode(spaces[id])).To(Succeed())
+			case http.MethodPut:
+				updated := &kibana.Space{}
+				decode(r, updated)
+				spaces[id] = updated
+			case http.MethodDelete:
+				writes++
+				delete(spaces, id)
+			}
+		})
+		mux.HandleFunc("/s/team-a/api/data_views/data_view", func(w http.ResponseWriter, r *http.Request) {
+			body := struct {
+				DataView *kibana.DataView `json:"data_view"`
+			}{}
+			decode(r, &body)
+			dataViews[body.DataView.ID] = body.DataView
+		})
+		mux.HandleFunc("/s/team-a/api/data_views/data_view/", func(w http.ResponseWriter, r *http.Request) {
+			id := strings.TrimPrefix(r.URL.Path, "/s/team-a/api/data_views/data_view/")
+			if dataViews[id] == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{"data_view": dataViews[id]})).To(Succeed())
+		})
+		mux.HandleFunc("/s/team-a/api/data_views/default", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				Expect(json.NewEncoder(w).Encode(map[string]string{"data_view_id": defaultView})).To(Succeed())
+				return
+			}
+			body := map[string]interface{}{}
+			decode(r, &body)
+			defaultView = body["data_view_id"].(string)
+		})
+		server = httptest.NewServer(mux)
+		kbClient = kibana.NewClient(kibana.Config{URL: server.URL})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Should apply the space and revert the changes made in Kibana", func() {
+		changed, err := ensureSpace(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(spaces["team-a"].Name).To(Equal("Team A"))
+		Expect(spaces["team-a"].DisabledFeatures).To(Equal([]string{"canvas", "ml"}))
+
+		changed, err = ensureSpace(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		// Fonctionnalité réactivée à la main dans Kibana
+		spaces["team-a"].DisabledFeatures = []string{"canvas"}
+		spaces["team-a"].Color = "#aabbcc"
+		changed, err = ensureSpace(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(spaces["team-a"].DisabledFeatures).To(Equal([]string{"canvas", "ml"}))
+
+		Expect(deleteSpace(ctx, kbClient, "team-a")).To(Succeed())
+		Expect(spaces).NotTo(HaveKey("team-a"))
+		Expect(deleteSpace(ctx, kbClient, "team-a")).To(Succeed())
+		// L'espace par défaut est réservé
+		Expect(deleteSpace(ctx, kbClient, "default")).To(Succeed())
+		Expect(spaces).To(HaveKey("default"))
+	})
+
+	It("Should create the default data view of the space", func() {
+		changed, err := ensureDefaultDataView(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(dataViews["team-a-default"]).To(Equal(&kibana.DataView{
+			ID: "team-a-default", Title: "team-a-*", Name: "team-a-*", TimeFieldName: "@timestamp",
+		}))
+		Expect(defaultView).To(Equal("team-a-default"))
+
+		writes = 0
+		changed, err = ensureDefaultDataView(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(writes).To(BeZero())
+
+		// Data view par défaut changée dans Kibana
+		defaultView = "other"
+		space.Spec.DefaultDataView.TimeField = "timestamp"
+		changed, err = ensureDefaultDataView(ctx, kbClient, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(dataViews["team-a-default"].TimeFieldName).To(Equal("timestamp"))
+		Expect(defaultView).To(Equal("team-a-default"))
+	})
+
+	It("Should leave the space to the oldest KibanaSpace declaring it", func() {
+		older := space.DeepCopy()
+		older.Namespace = "platform"
+		older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		space.CreationTimestamp = metav1.NewTime(time.Now())
+		otherStack := space.DeepCopy()
+		otherStack.Name = "team-a-other"
+		otherStack.Spec.SpaceID = "team-a"
+		otherStack.Spec.StackRef.Name = "other-efk"
+		otherStack.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+
+		scheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(space, older, otherStack).Build()
+		reconciler := &KibanaSpaceReconciler{Client: fakeClient, Scheme: scheme}
+
+		owner, err := reconciler.spaceOwner(ctx, space)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner).To(Equal(types.NamespacedName{Name: "team-a", Namespace: "platform"}))
+		owner, err = reconciler.spaceOwner(ctx, otherStack)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(owner.Name).To(Equal("team-a-other"))
+
+		Expect(mapKibanaSpaceToElasticsearchRoles(ctx, space)).To(BeEmpty())
+		space.Spec.Roles = []loggingv1.KibanaSpaceRoleBinding{{Name: "team-a-read"}}
+		Expect(mapKibanaSpaceToElasticsearchRoles(ctx, space)[0].NamespacedName).To(Equal(types.NamespacedName{Name: "team-a-read", Namespace: "team-a"}))
+	})
+})
@@ -0,0 +1,316 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+// restorePollInterval is the delay between two checks of a running restore
+const restorePollInterval = 10 * time.Second
+
+// dataStreamBackingPrefix is the prefix of the backing indices of the data streams
+const dataStreamBackingPrefix = ".ds-"
+
+// SnapshotRestoreReconciler restores a snapshot into the Elasticsearch cluster of an EFKStack
+// and tracks the recovery of the restored shards
+type SnapshotRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=snapshotrestores,verbs=get;list;watch
+//+kubebuilder:rbac:groups=logging.efk.crds.io,resources=snapshotrestores/status,verbs=get;update;patch
+
+// Reconcile starts the restore once Elasticsearch is ready, then follows it to completion
+func (r *SnapshotRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	restore := &loggingv1.SnapshotRestore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Une restauration terminée n'est jamais rejouée
+	if restore.Status.Phase == loggingv1.RestorePhaseCompleted || restore.Status.Phase == loggingv1.RestorePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	stackKey := types.NamespacedName{
+		Name:      restore.Spec.StackRef.Name,
+		Namespace: restore.Spec.StackRef.NamespaceOr(restore.Namespace),
+	}
+	efkStack := &loggingv1.EFKStack{}
+	if err := r.Get(ctx, stackKey, efkStack); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return r.setPending(ctx, restore, fmt.Sprintf("EFKStack %s not found", stackKey))
+	}
+	if efkStack.Status.Elasticsearch.State != "Ready" {
+		return r.setPending(ctx, restore, "Waiting for Elasticsearch to be ready")
+	}
+
+	esClient, err := newElasticsearchClient(ctx, r.Client, efkStack, stackNamespace(efkStack))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if restore.Status.Phase != loggingv1.RestorePhaseRunning {
+		if err := startRestore(ctx, esClient, restore, efkStack); err != nil {
+			logger.Error(err, "Failed to start snapshot restore", "snapshot", restore.Spec.Snapshot)
+			now := metav1.Now()
+			restore.Status.Phase = loggingv1.RestorePhaseFailed
+			restore.Status.CompletionTime = &now
+			restore.Status.Message = err.Error()
+			return ctrl.Result{}, r.Status().Update(ctx, restore)
+		}
+		logger.Info("Started snapshot restore", "snapshot", restore.Spec.Snapshot, "indices", restore.Status.Indices)
+		if err := r.Status().Update(ctx, restore); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+
+	shards, err := esClient.Shards(ctx, restore.Status.Indices)
+	if err != nil {
+		// L'état du cluster peut être momentanément indisponible : réessayer sans échouer la restauration
+		logger.Info("Failed to read restored shards", "error", err)
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+	if done := updateRestoreProgress(&restore.Status, shards); done {
+		logger.Info("Snapshot restore finished", "snapshot", restore.Spec.Snapshot, "phase", restore.Status.Phase)
+		return ctrl.Result{}, r.Status().Update(ctx, restore)
+	}
+	if err := r.Status().Update(ctx, restore); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+}
+
+// setPending records why the restore has not started yet and retries later
+func (r *SnapshotRestoreReconciler) setPending(ctx context.Context, restore *loggingv1.SnapshotRestore, message string) (ctrl.Result, error) {
+	if restore.Status.Phase != loggingv1.RestorePhasePending || restore.Status.Message != message {
+		restore.Status.Phase = loggingv1.RestorePhasePending
+		restore.Status.Message = message
+		if err := r.Status().Update(ctx, restore); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SnapshotRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.SnapshotRestore{}).
+		Complete(r)
+}
+
+// startRestore resolves the restored indices from the snapshot content and starts the restore.
+// The status is filled with the indices and the shards to track
+func startRestore(ctx context.Context, esClient *elasticsearch.Client, restore *loggingv1.SnapshotRestore, efkStack *loggingv1.EFKStack) error {
+	spec := &restore.Spec
+	repository := spec.Repository
+	if repository == "" {
+		if efkStack.Spec.Elasticsearch.Snapshot == nil {
+			return fmt.Errorf("no repository set and EFKStack %s has no snapshot repository", efkStack.Name)
+		}
+		repository = efkStack.Spec.Elasticsearch.Snapshot.Repository.RepositoryName()
+	}
+
+	snapshot, err := esClient.GetSnapshot(ctx, repository, spec.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot %s/%s: %w", repository, spec.Snapshot, err)
+	}
+
+	var rename *regexp.Regexp
+	if spec.RenamePattern != "" {
+		if rename, err = regexp.Compile(spec.RenamePattern); err != nil {
+			return fmt.Errorf("invalid rename pattern: %w", err)
+		}
+	}
+
+	indices, total := restoredIndices(snapshot, spec, rename)
+	if len(indices) == 0 {
+		return fmt.Errorf("no index or data stream of snapshot %s matches %v", spec.Snapshot, spec.Indices)
+	}
+
+	// Le statut peut ne pas avoir été enregistré après un premier appel : un second _restore
+	// échouerait sur les index ouverts qu'il a créés
+	started, err := restoreStarted(ctx, esClient, repository, spec.Snapshot, indices)
+	if err != nil {
+		return fmt.Errorf("failed to check the recovery of the restored indices: %w", err)
+	}
+	if !started {
+		// Elasticsearch résout lui-même les motifs, y compris les data streams et leurs index .ds-*
+		err = esClient.RestoreSnapshot(ctx, repository, spec.Snapshot, &elasticsearch.RestoreRequest{
+			Indices:            strings.Join(spec.Indices, ","),
+			RenamePattern:      spec.RenamePattern,
+			RenameReplacement:  spec.RenameReplacement,
+			Partial:            spec.Partial,
+			IncludeGlobalState: spec.IncludeGlobalState,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore snapshot %s/%s: %w", repository, spec.Snapshot, err)
+		}
+	}
+
+	now := metav1.Now()
+	restore.Status.Phase = loggingv1.RestorePhaseRunning
+	restore.Status.Repository = repository
+	restore.Status.Indices = indices
+	restore.Status.Shards = loggingv1.RestoreShardsStatus{Total: total}
+	restore.Status.StartTime = &now
+	restore.Status.Message = ""
+	return nil
+}
+
+// restoredIndices returns the sorted names of the indices created by a restore and their shard
+// count. The requested patterns select the indices and the data streams of the snapshot, the
+// backing indices of a data stream being restored with it. A rename applies to the name of a
+// backing index without its .ds- prefix, as Elasticsearch does
+func restoredIndices(snapshot *elasticsearch.SnapshotInfo, spec *loggingv1.SnapshotRestoreSpec, rename *regexp.Regexp) ([]string, int32) {
+	var streams []string
+	for _, stream := range snapshot.DataStreams {
+		if matchIndexPatterns(stream, spec.Indices) {
+			streams = append(streams, stream)
+		}
+	}
+
+	var indices []string
+	var total int32
+	for _, index := range snapshot.Indices {
+		backing := false
+		for _, stream := range streams {
+			if strings.HasPrefix(index, dataStreamBackingPrefix+stream+"-") {
+				backing = true
+				break
+			}
+		}
+		if !backing && !matchIndexPatterns(index, spec.Indices) {
+			continue
+		}
+		total += snapshot.IndexDetails[index].ShardCount
+		if rename != nil {
+			replacement := javaReplacement(spec.RenameReplacement)
+			if backing {
+				index = dataStreamBackingPrefix + rename.ReplaceAllString(strings.TrimPrefix(index, dataStreamBackingPrefix), replacement)
+			} else {
+				index = rename.ReplaceAllString(index, replacement)
+			}
+		}
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, total
+}
+
+// restoreStarted returns true if a shard of the given indices is recovered from the snapshot,
+// meaning that a previous reconcile already started the restore
+func restoreStarted(ctx context.Context, esClient *elasticsearch.Client, repository, snapshot string, indices []string) (bool, error) {
+	recoveries, err := esClient.Recoveries(ctx, indices)
+	if err != nil {
+		return false, err
+	}
+	for _, shards := range recoveries {
+		for _, shard := range shards {
+			if shard.Type == elasticsearch.RecoverySnapshot &&
+				shard.Source.Repository == repository && shard.Source.Snapshot == snapshot {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// updateRestoreProgress counts the restored primary shards and returns true when every shard
+// is either started or failed. Replicas are recovered from the primaries and are not tracked
+func updateRestoreProgress(status *loggingv1.SnapshotRestoreStatus, shards []elasticsearch.Shard) bool {
+	var successful, failed int32
+	var failures []string
+	for _, shard := range shards {
+		if !shard.Primary() {
+			continue
+		}
+		switch {
+		case shard.State == elasticsearch.ShardStarted:
+			successful++
+		case shard.State == elasticsearch.ShardUnassigned && shard.UnassignedReason == "ALLOCATION_FAILED":
+			failed++
+			failures = append(failures, fmt.Sprintf("%s[%s]", shard.Index, shard.Shard))
+		}
+	}
+	status.Shards.Successful = successful
+	status.Shards.Failed = failed
+
+	if successful+failed < status.Shards.Total {
+		status.Message = fmt.Sprintf("%d/%d primary shards restored", successful, status.Shards.Total)
+		return false
+	}
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	if failed > 0 {
+		status.Phase = loggingv1.RestorePhaseFailed
+		status.Message = fmt.Sprintf("Failed to restore shards %s", strings.Join(failures, ", "))
+	} else {
+		status.Phase = loggingv1.RestorePhaseCompleted
+		status.Message = fmt.Sprintf("%d primary shards restored", successful)
+	}
+	return true
+}
+
+// matchIndexPatterns applies the index patterns of a restore, with "*" wildcards and "-" exclusions.
+// Without pattern, every index except the system ones is restored, like Elasticsearch does
+func matchIndexPatterns(index string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return !strings.HasPrefix(index, ".")
+	}
+	matched := false
+	for _, pattern := range patterns {
+		exclude := strings.HasPrefix(pattern, "-")
+		pattern = strings.TrimPrefix(pattern, "-")
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if regexp.MustCompile(expr).MatchString(index) {
+			matched = !exclude
+		}
+	}
+	return matched
+}
+
+// javaReplacement converts the $1 group references of Elasticsearch into the ${1} form of Go,
+// so that "$1_restored" does not reference a group named "1_restored"
+func javaReplacement(replacement string) string {
+	return regexp.MustCompile(`\$(\d+)`).ReplaceAllString(replacement, "$${$1}")
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+	"github.com/zlorgoncho1/efk-operator/internal/elasticsearch"
+)
+
+var _ = Describe("SnapshotRestore Controller", func() {
+	var (
+		ctx      context.Context
+		efkStack *loggingv1.EFKStack
+		restore  *loggingv1.SnapshotRestore
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		efkStack = &loggingv1.EFKStack{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-efk", Namespace: "logging"},
+			Spec: loggingv1.EFKStackSpec{
+				Elasticsearch: loggingv1.ElasticsearchSpec{
+					Snapshot: &loggingv1.SnapshotSpec{
+						Repository: loggingv1.SnapshotRepositorySpec{
+							FS: &loggingv1.FSRepositorySpec{ClaimName: "backups"},
+						},
+					},
+				},
+			},
+		}
+		restore = &loggingv1.SnapshotRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "logging"},
+			Spec: loggingv1.SnapshotRestoreSpec{
+				StackRef:          loggingv1.StackReference{Name: "test-efk"},
+				Snapshot:          "nightly-1",
+				Indices:           []string{"fluent-bit-*", "-fluent-bit-debug"},
+				RenamePattern:     "(.+)",
+				RenameReplacement: "restored-$1",
+			},
+		}
+	})
+
+	It("Should wait for Elasticsearch to be ready", func() {
+		scheme := runtime.NewScheme()
+		_ = loggingv1.AddToScheme(scheme)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithStatusSubresource(&loggingv1.SnapshotRestore{}).
+			WithObjects(efkStack, restore).
+			Build()
+		reconciler := &SnapshotRestoreReconciler{Client: fakeClient, Scheme: scheme}
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "restore", Namespace: "logging"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		updated := &loggingv1.SnapshotRestore{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "restore", Namespace: "logging"}, updated)).To(Succeed())
+		Expect(updated.Status.Phase).To(Equal(loggingv1.RestorePhasePending))
+		Expect(updated.Status.Message).To(ContainSubstring("Elasticsearch"))
+	})
+
+	// snapshotServer serves the nightly-1 snapshot, the given shard recoveries and records the restores
+	snapshotServer := func(recoveries map[string]interface{}, restores *[]map[string]interface{}) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_snapshot/backups/nightly-1", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Query().Get("index_details")).To(Equal("true"))
+			Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+				"snapshots": []map[string]interface{}{{
+					"snapshot":     "nightly-1",
+					"state":        "SUCCESS",
+					"indices":      []string{"fluent-bit-1", "fluent-bit-debug", ".kibana_1", "other", ".ds-fluent-bit-app-2025.01.01-000001", ".ds-other-app-2025.01.01-000001"},
+					"data_streams": []string{"fluent-bit-app", "other-app"},
+					"index_details": map[string]interface{}{
+						"fluent-bit-1":                         map[string]interface{}{"shard_count": 2},
+						"fluent-bit-debug":                     map[string]interface{}{"shard_count": 1},
+						".ds-fluent-bit-app-2025.01.01-000001": map[string]interface{}{"shard_count": 1},
+					},
+				}},
+			})).To(Succeed())
+		})
+		mux.HandleFunc("/_snapshot/backups/nightly-1/_restore", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			body := map[string]interface{}{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			*restores = append(*restores, body)
+			_, _ = w.Write([]byte(`{"accepted":true}`))
+		})
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(HaveSuffix("/_recovery"))
+			Expect(r.URL.Query().Get("ignore_unavailable")).To(Equal("true"))
+			Expect(json.NewEncoder(w).Encode(recoveries)).To(Succeed())
+		})
+		return httptest.NewServer(mux)
+	}
+
+	It("Should restore the matching indices and data streams of the snapshot", func() {
+		var restores []map[string]interface{}
+		server := snapshotServer(map[string]interface{}{}, &restores)
+		defer server.Close()
+
+		esClient := elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+		Expect(startRestore(ctx, esClient, restore, efkStack)).To(Succeed())
+		Expect(restores).To(HaveLen(1))
+		Expect(restores[0]).To(HaveKeyWithValue("indices", "fluent-bit-*,-fluent-bit-debug"))
+		Expect(restores[0]).To(HaveKeyWithValue("rename_replacement", "restored-$1"))
+		Expect(restore.Status.Phase).To(Equal(loggingv1.RestorePhaseRunning))
+		Expect(restore.Status.Repository).To(Equal("backups"))
+		// The backing indices keep their .ds- prefix when the data stream is renamed
+		Expect(restore.Status.Indices).To(Equal([]string{".ds-restored-fluent-bit-app-2025.01.01-000001", "restored-fluent-bit-1"}))
+		Expect(restore.Status.Shards.Total).To(BeEquivalentTo(3))
+		Expect(restore.Status.StartTime).NotTo(BeNil())
+	})
+
+	It("Should not restore again a snapshot whose restore was not recorded", func() {
+		var restores []map[string]interface{}
+		server := snapshotServer(map[string]interface{}{
+			"restored-fluent-bit-1": map[string]interface{}{
+				"shards": []map[string]interface{}{{
+					"id":      0,
+					"type":    elasticsearch.RecoverySnapshot,
+					"stage":   "INDEX",
+					"primary": true,
+					"source":  map[string]interface{}{"repository": "backups", "snapshot": "nightly-1", "index": "fluent-bit-1"},
+				}},
+			},
+		}, &restores)
+		defer server.Close()
+
+		esClient := elasticsearch.NewClient(elasticsearch.Config{URL: server.URL})
+		Expect(startRestore(ctx, esClient, restore, efkStack)).To(Succeed())
+		Expect(restores).To(BeEmpty())
+		Expect(restore.Status.Phase).To(Equal(loggingv1.RestorePhaseRunning))
+		Expect(restore.Status.Shards.Total).To(BeEquivalentTo(3))
+	})
+
+	It("Should complete once every primary shard is started", func() {
+		status := &loggingv1.SnapshotRestoreStatus{
+			Phase:     loggingv1.RestorePhaseRunning,
+			Shards:    loggingv1.RestoreShardsStatus{Total: 2},
+			StartTime: &metav1.Time{Time: time.Now()},
+		}
+		shards := []elasticsearch.Shard{
+			{Index: "restored-fluent-bit-1", Shard: "0", PriRep: "p", State: elasticsearch.ShardStarted},
+			{Index: "restored-fluent-bit-1", Shard: "0", PriRep: "r", State: elasticsearch.ShardStarted},
+			{Index: "restored-fluent-bit-1", Shard: "1", PriRep: "p", State: elasticsearch.ShardInitializing},
+		}
+		Expect(updateRestoreProgress(status, shards)).To(BeFalse())
+		Expect(status.Shards.Successful).To(BeEquivalentTo(1))
+		Expect(status.Phase).To(Equal(loggingv1.RestorePhaseRunning))
+
+		shards[2].State = elasticsearch.ShardUnassigned
+		shards[2].UnassignedReason = "ALLOCATION_FAILED"
+		Expect(updateRestoreProgress(status, shards)).To(BeTrue())
+		Expect(status.Phase).To(Equal(loggingv1.RestorePhaseFailed))
+		Expect(status.Shards.Failed).To(BeEquivalentTo(1))
+		Expect(status.Message).To(ContainSubstring("restored-fluent-bit-1[1]"))
+		Expect(status.CompletionTime).NotTo(BeNil())
+	})
+
+	It("Should match the indices like Elasticsearch", func() {
+		Expect(matchIndexPatterns("logs-1", nil)).To(BeTrue())
+		Expect(matchIndexPatterns(".kibana_1", nil)).To(BeFalse())
+		Expect(matchIndexPatterns(".kibana_1", []string{".kibana*"})).To(BeTrue())
+		Expect(matchIndexPatterns("logs-debug", []string{"logs-*", "-logs-debug"})).To(BeFalse())
+		Expect(javaReplacement("restored-$1_$2")).To(Equal("restored-${1}_${2}"))
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config holds the connection settings of an Elasticsearch cluster
+type Config struct {
+	// URL is the base URL of the cluster (e.g. http://efk-elasticsearch.logging.svc:9200)
+	URL string
+	// Username and Password are used for basic authentication when set
+	Username string
+	Password string
+	// HTTPClient is used to send requests, a client with a default timeout is used when nil
+	HTTPClient *http.Client
+}
+
+// Client is a minimal Elasticsearch REST API client used by the operator
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// Error is returned when Elasticsearch answers with a non 2xx status code
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("elasticsearch %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if the error is an Elasticsearch 404 response
+func IsNotFound(err error) bool {
+	var esErr *Error
+	return errors.As(err, &esErr) && esErr.StatusCode == http.StatusNotFound
+}
+
+// IsUnauthorized returns true if the error is an Elasticsearch 401 or 403 response
+func IsUnauthorized(err error) bool {
+	var esErr *Error
+	return errors.As(err, &esErr) &&
+		(esErr.StatusCode == http.StatusUnauthorized || esErr.StatusCode == http.StatusForbidden)
+}
+
+// NewClient creates a new Elasticsearch client
+func NewClient(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		username:   cfg.Username,
+		password:   cfg.Password,
+		httpClient: httpClient,
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out when it is not nil
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("elasticsearch %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &Error{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Path:       path,
+			Body:       string(respBody),
+		}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestElasticsearch(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Elasticsearch Client Suite")
+}
+
+var _ = Describe("Elasticsearch client", func() {
+	var (
+		ctx    context.Context
+		server *httptest.Server
+		mux    *http.ServeMux
+		client *Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		client = NewClient(Config{URL: server.URL, Username: "elastic", Password: "changeme"})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When reading the cluster health", func() {
+		It("Should decode the health response and authenticate", func() {
+			mux.HandleFunc("/_cluster/health", func(w http.ResponseWriter, r *http.Request) {
+				user, password, ok := r.BasicAuth()
+				Expect(ok).To(BeTrue())
+				Expect(user).To(Equal("elastic"))
+				Expect(password).To(Equal("changeme"))
+				_, _ = w.Write([]byte(`{"cluster_name":"efk","status":"yellow","number_of_nodes":3,"active_shards":10,"unassigned_shards":2}`))
+			})
+
+			health, err := client.ClusterHealth(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(health.Status).To(Equal(HealthYellow))
+			Expect(health.NumberOfNodes).To(Equal(int32(3)))
+			Expect(health.ActiveShards).To(Equal(int32(10)))
+			Expect(health.UnassignedShards).To(Equal(int32(2)))
+		})
+
+		It("Should return a typed error on authentication failures", func() {
+			mux.HandleFunc("/_cluster/health", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			})
+
+			_, err := client.ClusterHealth(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(IsUnauthorized(err)).To(BeTrue())
+			Expect(IsNotFound(err)).To(BeFalse())
+		})
+	})
+
+	Context("When managing lifecycle policies and index templates", func() {
+		It("Should unwrap the policy and the template from their responses", func() {
+			mux.HandleFunc("/_ilm/policy/fluent-bit", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"fluent-bit":{"version":2,"policy":{"phases":{"delete":{"min_age":"7d","actions":{"delete":{}}}}}}}`))
+			})
+			mux.HandleFunc("/_index_template/fluent-bit", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"index_templates":[{"name":"fluent-bit","index_template":{"index_patterns":["fluent-bit"],"priority":200,"data_stream":{"hidden":false}}}]}`))
+			})
+
+			policy, err := client.GetLifecyclePolicy(ctx, "fluent-bit")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Phases).To(HaveKey("delete"))
+			Expect(policy.Phases["delete"].MinAge).To(Equal("7d"))
+
+			template, err := client.GetIndexTemplate(ctx, "fluent-bit")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.IndexPatterns).To(Equal([]string{"fluent-bit"}))
+			Expect(template.Priority).To(Equal(int64(200)))
+			Expect(template.DataStream).NotTo(BeNil())
+		})
+
+		It("Should wrap the policy in the request body", func() {
+			var body map[string]interface{}
+			mux.HandleFunc("/_ilm/policy/fluent-bit", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPut))
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				_, _ = w.Write([]byte(`{"acknowledged":true}`))
+			})
+
+			err := client.PutLifecyclePolicy(ctx, "fluent-bit", &LifecyclePolicy{
+				Phases: map[string]LifecyclePhase{"delete": {MinAge: "7d", Actions: map[string]interface{}{"delete": map[string]interface{}{}}}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(HaveKey("policy"))
+		})
+	})
+
+	Context("When updating cluster settings", func() {
+		It("Should send null to reset a setting", func() {
+			var body string
+			mux.HandleFunc("/_cluster/settings", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPut))
+				var payload json.RawMessage
+				Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+				body = string(payload)
+				_, _ = w.Write([]byte(`{"acknowledged":true}`))
+			})
+
+			err := client.PutClusterSettings(ctx, &ClusterSettings{
+				Persistent: map[string]interface{}{AllocationEnableSetting: nil},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(Equal(`{"persistent":{"cluster.routing.allocation.enable":null}}`))
+		})
+	})
+
+	Context("When reloading the secure settings", func() {
+		It("Should report the nodes that failed to reload", func() {
+			mux.HandleFunc("/_nodes/reload_secure_settings", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				_, _ = w.Write([]byte(`{"_nodes":{"total":2,"successful":2,"failed":0},"cluster_name":"efk","nodes":{` +
+					`"a1":{"name":"efk-es-0"},` +
+					`"b2":{"name":"efk-es-1","reload_exception":{"type":"illegal_state_exception","reason":"keystore is missing"}}}}`))
+			})
+
+			err := client.ReloadSecureSettings(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("efk-es-1: keystore is missing"))
+			Expect(err.Error()).NotTo(ContainSubstring("efk-es-0"))
+		})
+	})
+
+	Context("When managing users", func() {
+		It("Should change a password as another user", func() {
+			var body map[string]string
+			mux.HandleFunc("/_security/user/kibana_system/_password", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				user, _, _ := r.BasicAuth()
+				Expect(user).To(Equal("elastic"))
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				_, _ = w.Write([]byte(`{}`))
+			})
+			mux.HandleFunc("/_security/_authenticate", func(w http.ResponseWriter, r *http.Request) {
+				if _, password, _ := r.BasicAuth(); password != body["password"] {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			})
+
+			kibana := client.WithBasicAuth("kibana_system", "s3cr3t")
+			Expect(IsUnauthorized(kibana.Authenticate(ctx))).To(BeTrue())
+			Expect(client.ChangePassword(ctx, "kibana_system", "s3cr3t")).To(Succeed())
+			Expect(kibana.Authenticate(ctx)).To(Succeed())
+		})
+
+		It("Should send the empty privileges of a role and ignore missing ones on delete", func() {
+			var sent map[string]interface{}
+			mux.HandleFunc("/_security/role/team-a", func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodPut:
+					Expect(json.NewDecoder(r.Body).Decode(&sent)).To(Succeed())
+				case http.MethodGet:
+					_, _ = w.Write([]byte(`{"team-a":{"cluster":[],"indices":[{"names":["team-a-*"],"privileges":["read"],"allow_restricted_indices":false}],"applications":[],"run_as":[],"metadata":{}}}`))
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			role := &Role{Indices: []IndexPrivileges{{Names: []string{"team-a-*"}, Privileges: []string{"read"}}}}
+			Expect(client.PutRole(ctx, "team-a", role)).To(Succeed())
+			Expect(sent).To(HaveKeyWithValue("cluster", BeEmpty()))
+			Expect(sent).To(HaveKeyWithValue("applications", BeEmpty()))
+
+			current, err := client.GetRole(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(Contains(current, role)).To(BeTrue())
+			role.Cluster = []string{"monitor"}
+			Expect(Contains(current, role)).To(BeFalse())
+
+			Expect(client.DeleteRole(ctx, "team-a")).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Contains", func() {
+	It("Should ignore the fields added by Elasticsearch", func() {
+		actual := map[string]interface{}{
+			"index_patterns": []string{"fluent-bit"},
+			"template": map[string]interface{}{
+				"settings": map[string]interface{}{
+					"index": map[string]interface{}{"number_of_shards": "1", "lifecycle": map[string]interface{}{"name": "fluent-bit"}},
+				},
+			},
+			"data_stream": map[string]interface{}{"hidden": false},
+		}
+		desired := &IndexTemplate{
+			IndexPatterns: []string{"fluent-bit"},
+			DataStream:    &DataStreamTemplate{},
+			Template: &TemplateBody{Settings: map[string]interface{}{
+				"index": map[string]interface{}{"number_of_shards": 1},
+			}},
+		}
+
+		Expect(Contains(actual, desired)).To(BeTrue())
+
+		desired.IndexPatterns = []string{"fluent-bit", "logs"}
+		Expect(Contains(actual, desired)).To(BeFalse())
+	})
+})
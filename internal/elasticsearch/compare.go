@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Contains reports whether every field of desired is set to the same value in actual.
+// Elasticsearch enriches the objects it returns (defaults, settings as strings), so the
+// operator only compares the fields it manages. Both values are compared as JSON
+func Contains(actual, desired interface{}) (bool, error) {
+	actualJSON, err := toJSON(actual)
+	if err != nil {
+		return false, err
+	}
+	desiredJSON, err := toJSON(desired)
+	if err != nil {
+		return false, err
+	}
+	return contains(actualJSON, desiredJSON), nil
+}
+
+func toJSON(value interface{}) (interface{}, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T: %w", value, err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", value, err)
+	}
+	return out, nil
+}
+
+func contains(actual, desired interface{}) bool {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range desired {
+			if !contains(actual[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok || len(actual) != len(desired) {
+			return false
+		}
+		for i := range desired {
+			if !contains(actual[i], desired[i]) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return actual == nil
+	default:
+		// Les settings sont renvoyés sous forme de chaînes ("1" pour 1, "true" pour true)
+		return fmt.Sprint(actual) == fmt.Sprint(desired)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Cluster health colors returned by _cluster/health
+const (
+	HealthGreen  = "green"
+	HealthYellow = "yellow"
+	HealthRed    = "red"
+)
+
+// ClusterHealth is the response of the _cluster/health API
+type ClusterHealth struct {
+	ClusterName         string `json:"cluster_name"`
+	Status              string `json:"status"`
+	TimedOut            bool   `json:"timed_out"`
+	NumberOfNodes       int32  `json:"number_of_nodes"`
+	NumberOfDataNodes   int32  `json:"number_of_data_nodes"`
+	ActivePrimaryShards int32  `json:"active_primary_shards"`
+	ActiveShards        int32  `json:"active_shards"`
+	RelocatingShards    int32  `json:"relocating_shards"`
+	InitializingShards  int32  `json:"initializing_shards"`
+	UnassignedShards    int32  `json:"unassigned_shards"`
+}
+
+// ClusterHealth returns the health of the cluster
+func (c *Client) ClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	health := &ClusterHealth{}
+	if err := c.do(ctx, http.MethodGet, "/_cluster/health", nil, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// Shard states returned by _cat/shards
+const (
+	ShardStarted      = "STARTED"
+	ShardInitializing = "INITIALIZING"
+	ShardUnassigned   = "UNASSIGNED"
+)
+
+// Shard is a line of the _cat/shards API
+type Shard struct {
+	Index            string `json:"index"`
+	Shard            string `json:"shard"`
+	PriRep           string `json:"prirep"`
+	State            string `json:"state"`
+	UnassignedReason string `json:"unassigned.reason"`
+	// Node is the node holding the shard, "<source> -> <ip> <id> <target>" while it relocates
+	Node string `json:"node"`
+}
+
+// Primary returns true if the shard is a primary shard
+func (s *Shard) Primary() bool {
+	return s.PriRep == "p"
+}
+
+// NodeName returns the node holding the shard, or the source node of a relocating shard
+func (s *Shard) NodeName() string {
+	if fields := strings.Fields(s.Node); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Shards returns the shards of the given indices, or of every index when none is given
+func (c *Client) Shards(ctx context.Context, indices []string) ([]Shard, error) {
+	var shards []Shard
+	path := "/_cat/shards"
+	if len(indices) > 0 {
+		path += "/" + url.PathEscape(strings.Join(indices, ","))
+	}
+	path += "?format=json&h=index,shard,prirep,state,unassigned.reason,node"
+	if err := c.do(ctx, http.MethodGet, path, nil, &shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
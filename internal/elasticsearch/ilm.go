@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// LifecyclePolicy is an Index Lifecycle Management policy
+type LifecyclePolicy struct {
+	Phases map[string]LifecyclePhase `json:"phases"`
+	Meta   map[string]interface{}    `json:"_meta,omitempty"`
+}
+
+// LifecyclePhase is a phase of a LifecyclePolicy, actions are kept as raw JSON objects
+type LifecyclePhase struct {
+	MinAge  string                 `json:"min_age,omitempty"`
+	Actions map[string]interface{} `json:"actions"`
+}
+
+// GetLifecyclePolicy returns the ILM policy with the given name
+func (c *Client) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicy, error) {
+	resp := map[string]struct {
+		Policy LifecyclePolicy `json:"policy"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/_ilm/policy/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	policy, ok := resp[name]
+	if !ok {
+		return nil, fmt.Errorf("ILM policy %s missing from the response", name)
+	}
+	return &policy.Policy, nil
+}
+
+// PutLifecyclePolicy creates or replaces an ILM policy
+func (c *Client) PutLifecyclePolicy(ctx context.Context, name string, policy *LifecyclePolicy) error {
+	body := map[string]interface{}{"policy": policy}
+	return c.do(ctx, http.MethodPut, "/_ilm/policy/"+url.PathEscape(name), body, nil)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// votingExclusionTimeout bounds the wait of Elasticsearch for the voting exclusions, below the
+// timeout of the HTTP clients so that a slow exclusion is reported by Elasticsearch
+const votingExclusionTimeout = "5s"
+
+// AddVotingConfigExclusions removes master-eligible nodes from the voting configuration,
+// so that stopping them does not break the quorum. The call returns once they are excluded,
+// or fails after votingExclusionTimeout and can be retried
+func (c *Client) AddVotingConfigExclusions(ctx context.Context, nodeNames []string) error {
+	query := url.Values{}
+	query.Set("node_names", strings.Join(nodeNames, ","))
+	query.Set("timeout", votingExclusionTimeout)
+	return c.do(ctx, http.MethodPost, "/_cluster/voting_config_exclusions?"+query.Encode(), nil, nil)
+}
+
+// ClearVotingConfigExclusions removes every voting configuration exclusion without waiting
+// for the excluded nodes to leave the cluster
+func (c *Client) ClearVotingConfigExclusions(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/_cluster/voting_config_exclusions?wait_for_removal=false", nil, nil)
+}
+
+// ReloadSecureSettings makes every node reload the reloadable settings of its keystore. The
+// nodes that failed to reload them are reported in the error
+func (c *Client) ReloadSecureSettings(ctx context.Context) error {
+	var resp struct {
+		Nodes map[string]struct {
+			Name            string `json:"name"`
+			ReloadException *struct {
+				Reason string `json:"reason"`
+			} `json:"reload_exception"`
+		} `json:"nodes"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_nodes/reload_secure_settings", nil, &resp); err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, node := range resp.Nodes {
+		if node.ReloadException != nil {
+			failures = append(failures, node.Name+": "+node.ReloadException.Reason)
+		}
+	}
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return fmt.Errorf("failed to reload the secure settings of %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IndexPrivileges grants privileges on the indices matching Names
+type IndexPrivileges struct {
+	Names      []string `json:"names"`
+	Privileges []string `json:"privileges"`
+}
+
+// ApplicationPrivileges grants privileges of an application, such as Kibana, on its resources
+type ApplicationPrivileges struct {
+	Application string   `json:"application"`
+	Privileges  []string `json:"privileges"`
+	Resources   []string `json:"resources"`
+}
+
+// Role is a native role of the security API
+type Role struct {
+	Cluster      []string                `json:"cluster"`
+	Indices      []IndexPrivileges       `json:"indices"`
+	Applications []ApplicationPrivileges `json:"applications"`
+	Metadata     map[string]interface{}  `json:"metadata,omitempty"`
+}
+
+// MarshalJSON sends the missing privileges as empty lists, so that Contains also detects
+// the privileges added to the role outside of the operator
+func (r Role) MarshalJSON() ([]byte, error) {
+	type role Role
+	out := role(r)
+	if out.Cluster == nil {
+		out.Cluster = []string{}
+	}
+	if out.Indices == nil {
+		out.Indices = []IndexPrivileges{}
+	}
+	if out.Applications == nil {
+		out.Applications = []ApplicationPrivileges{}
+	}
+	return json.Marshal(out)
+}
+
+// User is a native user of the security API. The password is only sent, never returned
+type User struct {
+	Password string                 `json:"password,omitempty"`
+	Roles    []string               `json:"roles"`
+	FullName string                 `json:"full_name,omitempty"`
+	Email    string                 `json:"email,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+}
+
+// WithBasicAuth returns a copy of the client authenticating as another user
+func (c *Client) WithBasicAuth(username, password string) *Client {
+	clone := *c
+	clone.username = username
+	clone.password = password
+	return &clone
+}
+
+// Authenticate checks the credentials of the client, an error satisfying IsUnauthorized
+// is returned when they are rejected
+func (c *Client) Authenticate(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_security/_authenticate", nil, nil)
+}
+
+// ChangePassword sets the password of a native or built-in user
+func (c *Client) ChangePassword(ctx context.Context, username, password string) error {
+	body := map[string]string{"password": password}
+	return c.do(ctx, http.MethodPost, "/_security/user/"+url.PathEscape(username)+"/_password", body, nil)
+}
+
+// GetRole returns the native role with the given name
+func (c *Client) GetRole(ctx context.Context, name string) (*Role, error) {
+	resp := map[string]*Role{}
+	if err := c.do(ctx, http.MethodGet, "/_security/role/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	role, ok := resp[name]
+	if !ok || role == nil {
+		return nil, fmt.Errorf("role %s missing from the response", name)
+	}
+	return role, nil
+}
+
+// PutRole creates or replaces a native role
+func (c *Client) PutRole(ctx context.Context, name string, role *Role) error {
+	return c.do(ctx, http.MethodPut, "/_security/role/"+url.PathEscape(name), role, nil)
+}
+
+// DeleteRole deletes a native role, a missing role is not an error
+func (c *Client) DeleteRole(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodDelete, "/_security/role/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// GetUser returns the native user with the given name, without its password
+func (c *Client) GetUser(ctx context.Context, name string) (*User, error) {
+	resp := map[string]*User{}
+	if err := c.do(ctx, http.MethodGet, "/_security/user/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	user, ok := resp[name]
+	if !ok || user == nil {
+		return nil, fmt.Errorf("user %s missing from the response", name)
+	}
+	return user, nil
+}
+
+// PutUser creates or updates a native user. Without password, the password of an existing
+// user is kept
+func (c *Client) PutUser(ctx context.Context, name string, user *User) error {
+	return c.do(ctx, http.MethodPut, "/_security/user/"+url.PathEscape(name), user, nil)
+}
+
+// DeleteUser deletes a native user, a missing user is not an error
+func (c *Client) DeleteUser(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodDelete, "/_security/user/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+)
+
+// AllocationEnableSetting controls which shards may be allocated to the nodes
+const AllocationEnableSetting = "cluster.routing.allocation.enable"
+
+// AllocationExcludeNameSetting lists the nodes from which every shard is moved away
+const AllocationExcludeNameSetting = "cluster.routing.allocation.exclude._name"
+
+// Values of AllocationEnableSetting
+const (
+	AllocationAll       = "all"
+	AllocationPrimaries = "primaries"
+)
+
+// ClusterSettings holds the persistent and transient cluster settings, in flat form.
+// A nil value resets a setting to its default
+type ClusterSettings struct {
+	Persistent map[string]interface{} `json:"persistent,omitempty"`
+	Transient  map[string]interface{} `json:"transient,omitempty"`
+}
+
+// GetClusterSettings returns the persistent and transient cluster settings
+func (c *Client) GetClusterSettings(ctx context.Context) (*ClusterSettings, error) {
+	settings := &ClusterSettings{}
+	if err := c.do(ctx, http.MethodGet, "/_cluster/settings?flat_settings=true", nil, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// PutClusterSettings updates the given cluster settings and leaves the others untouched
+func (c *Client) PutClusterSettings(ctx context.Context, settings *ClusterSettings) error {
+	return c.do(ctx, http.MethodPut, "/_cluster/settings", settings, nil)
+}
+
+// Flush writes the operations of the translog of every index to disk,
+// which speeds up the recovery of the shards of a restarted node
+func (c *Client) Flush(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/_flush", nil, nil)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SnapshotRepository is a snapshot repository registration
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// SLMPolicy is a Snapshot Lifecycle Management policy
+type SLMPolicy struct {
+	// Name is the name of the snapshots, date math is resolved at each execution
+	Name       string                 `json:"name"`
+	Schedule   string                 `json:"schedule"`
+	Repository string                 `json:"repository"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+	Retention  map[string]interface{} `json:"retention,omitempty"`
+}
+
+// SLMPolicyInfo is a SLM policy with its last executions
+type SLMPolicyInfo struct {
+	Policy      SLMPolicy      `json:"policy"`
+	LastSuccess *SLMInvocation `json:"last_success,omitempty"`
+	LastFailure *SLMInvocation `json:"last_failure,omitempty"`
+}
+
+// SLMInvocation is an execution of a SLM policy
+type SLMInvocation struct {
+	SnapshotName string `json:"snapshot_name"`
+	// Time is the epoch time of the execution in milliseconds
+	Time    int64  `json:"time"`
+	Details string `json:"details,omitempty"`
+}
+
+// Timestamp returns the time of the execution
+func (i *SLMInvocation) Timestamp() time.Time {
+	return time.UnixMilli(i.Time)
+}
+
+// GetSnapshotRepository returns the snapshot repository with the given name
+func (c *Client) GetSnapshotRepository(ctx context.Context, name string) (*SnapshotRepository, error) {
+	resp := map[string]SnapshotRepository{}
+	if err := c.do(ctx, http.MethodGet, "/_snapshot/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	repo, ok := resp[name]
+	if !ok {
+		return nil, fmt.Errorf("snapshot repository %s missing from the response", name)
+	}
+	return &repo, nil
+}
+
+// PutSnapshotRepository registers or updates a snapshot repository. Elasticsearch verifies
+// that every node can access the repository before acknowledging
+func (c *Client) PutSnapshotRepository(ctx context.Context, name string, repo *SnapshotRepository) error {
+	return c.do(ctx, http.MethodPut, "/_snapshot/"+url.PathEscape(name), repo, nil)
+}
+
+// GetSLMPolicy returns the SLM policy with the given name and its last executions
+func (c *Client) GetSLMPolicy(ctx context.Context, name string) (*SLMPolicyInfo, error) {
+	resp := map[string]SLMPolicyInfo{}
+	if err := c.do(ctx, http.MethodGet, "/_slm/policy/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	info, ok := resp[name]
+	if !ok {
+		return nil, fmt.Errorf("SLM policy %s missing from the response", name)
+	}
+	return &info, nil
+}
+
+// PutSLMPolicy creates or replaces a SLM policy
+func (c *Client) PutSLMPolicy(ctx context.Context, id string, policy *SLMPolicy) error {
+	return c.do(ctx, http.MethodPut, "/_slm/policy/"+url.PathEscape(id), policy, nil)
+}
+
+// DeleteSLMPolicy deletes a SLM policy, a missing policy is not an error
+func (c *Client) DeleteSLMPolicy(ctx context.Context, id string) error {
+	err := c.do(ctx, http.MethodDelete, "/_slm/policy/"+url.PathEscape(id), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// SnapshotInfo describes a snapshot and the shards of its indices
+type SnapshotInfo struct {
+	Snapshot string   `json:"snapshot"`
+	State    string   `json:"state"`
+	Indices  []string `json:"indices"`
+	// DataStreams are the data streams whose backing indices (.ds-<name>-*) are in Indices
+	DataStreams  []string                     `json:"data_streams,omitempty"`
+	IndexDetails map[string]SnapshotIndexInfo `json:"index_details,omitempty"`
+}
+
+// SnapshotIndexInfo describes an index of a snapshot
+type SnapshotIndexInfo struct {
+	ShardCount int32 `json:"shard_count"`
+}
+
+// RestoreRequest is the body of a snapshot restore
+type RestoreRequest struct {
+	Indices            string `json:"indices,omitempty"`
+	RenamePattern      string `json:"rename_pattern,omitempty"`
+	RenameReplacement  string `json:"rename_replacement,omitempty"`
+	Partial            bool   `json:"partial"`
+	IncludeGlobalState bool   `json:"include_global_state"`
+}
+
+// GetSnapshot returns a snapshot of a repository with the shard count of its indices
+func (c *Client) GetSnapshot(ctx context.Context, repository, snapshot string) (*SnapshotInfo, error) {
+	resp := struct {
+		Snapshots []SnapshotInfo `json:"snapshots"`
+	}{}
+	path := fmt.Sprintf("/_snapshot/%s/%s?index_details=true", url.PathEscape(repository), url.PathEscape(snapshot))
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, info := range resp.Snapshots {
+		if info.Snapshot == snapshot {
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s missing from the response", snapshot)
+}
+
+// RestoreSnapshot starts the restore of a snapshot without waiting for its completion
+func (c *Client) RestoreSnapshot(ctx context.Context, repository, snapshot string, req *RestoreRequest) error {
+	path := fmt.Sprintf("/_snapshot/%s/%s/_restore", url.PathEscape(repository), url.PathEscape(snapshot))
+	return c.do(ctx, http.MethodPost, path, req, nil)
+}
+
+// RecoverySnapshot is the type of the recovery of a shard restored from a snapshot
+const RecoverySnapshot = "SNAPSHOT"
+
+// ShardRecovery is the recovery of a shard returned by the _recovery API
+type ShardRecovery struct {
+	ID      int32          `json:"id"`
+	Type    string         `json:"type"`
+	Stage   string         `json:"stage"`
+	Primary bool           `json:"primary"`
+	Source  RecoverySource `json:"source"`
+}
+
+// RecoverySource is the origin of a shard recovery, the snapshot for a restored shard
+type RecoverySource struct {
+	Repository string `json:"repository,omitempty"`
+	Snapshot   string `json:"snapshot,omitempty"`
+	Index      string `json:"index,omitempty"`
+}
+
+// Recoveries returns the shard recoveries of the given indices by index. The indices that do not
+// exist are ignored
+func (c *Client) Recoveries(ctx context.Context, indices []string) (map[string][]ShardRecovery, error) {
+	resp := map[string]struct {
+		Shards []ShardRecovery `json:"shards"`
+	}{}
+	path := fmt.Sprintf("/%s/_recovery?ignore_unavailable=true", url.PathEscape(strings.Join(indices, ",")))
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	recoveries := make(map[string][]ShardRecovery, len(resp))
+	for index, info := range resp {
+		recoveries[index] = info.Shards
+	}
+	return recoveries, nil
+}
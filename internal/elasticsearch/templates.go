@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IndexTemplate is a composable index template
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	ComposedOf    []string               `json:"composed_of,omitempty"`
+	Priority      int64                  `json:"priority,omitempty"`
+	Template      *TemplateBody          `json:"template,omitempty"`
+	DataStream    *DataStreamTemplate    `json:"data_stream,omitempty"`
+	Meta          map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// TemplateBody holds the settings and mappings applied to the matching indices
+type TemplateBody struct {
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+}
+
+// ComponentTemplate is a reusable block of settings and mappings
+type ComponentTemplate struct {
+	Template TemplateBody           `json:"template"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// DataStreamTemplate makes the matching names data streams
+type DataStreamTemplate struct{}
+
+// GetIndexTemplate returns the composable index template with the given name
+func (c *Client) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	resp := struct {
+		IndexTemplates []struct {
+			Name          string        `json:"name"`
+			IndexTemplate IndexTemplate `json:"index_template"`
+		} `json:"index_templates"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/_index_template/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, template := range resp.IndexTemplates {
+		if template.Name == name {
+			return &template.IndexTemplate, nil
+		}
+	}
+	return nil, fmt.Errorf("index template %s missing from the response", name)
+}
+
+// PutIndexTemplate creates or replaces a composable index template
+func (c *Client) PutIndexTemplate(ctx context.Context, name string, template *IndexTemplate) error {
+	return c.do(ctx, http.MethodPut, "/_index_template/"+url.PathEscape(name), template, nil)
+}
+
+// DeleteIndexTemplate deletes a composable index template, a missing template is not an error
+func (c *Client) DeleteIndexTemplate(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodDelete, "/_index_template/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// GetComponentTemplate returns the component template with the given name
+func (c *Client) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	resp := struct {
+		ComponentTemplates []struct {
+			Name              string            `json:"name"`
+			ComponentTemplate ComponentTemplate `json:"component_template"`
+		} `json:"component_templates"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, "/_component_template/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, template := range resp.ComponentTemplates {
+		if template.Name == name {
+			return &template.ComponentTemplate, nil
+		}
+	}
+	return nil, fmt.Errorf("component template %s missing from the response", name)
+}
+
+// PutComponentTemplate creates or replaces a component template
+func (c *Client) PutComponentTemplate(ctx context.Context, name string, template *ComponentTemplate) error {
+	return c.do(ctx, http.MethodPut, "/_component_template/"+url.PathEscape(name), template, nil)
+}
+
+// DeleteComponentTemplate deletes a component template, a missing template is not an error
+func (c *Client) DeleteComponentTemplate(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodDelete, "/_component_template/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ExpandSettings turns dotted setting names (index.number_of_shards) into the nested
+// objects returned by Elasticsearch, so that desired and actual settings can be compared
+func ExpandSettings(flat map[string]interface{}) map[string]interface{} {
+	nested := map[string]interface{}{}
+	for key, value := range flat {
+		parts := strings.Split(key, ".")
+		current := nested
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = value
+	}
+	return nested
+}
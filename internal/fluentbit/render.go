@@ -0,0 +1,473 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fluentbit renders the typed Fluent Bit pipeline of an EFKStack into
+// the classic fluent-bit.conf format
+package fluentbit
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+const (
+	// ConfigDir is the directory where the Fluent Bit ConfigMap is mounted
+	ConfigDir = "/fluent-bit/etc"
+	// MainConfigFile is the main configuration file
+	MainConfigFile = "fluent-bit.conf"
+	// ParsersConfigFile is the parsers file referenced by the [SERVICE] section
+	ParsersConfigFile = "parsers.conf"
+	// IndexKey is the record key holding the index prefix set by a FluentBitPipeline
+	IndexKey = "log_index"
+)
+
+// Defaults holds the stack-specific values used when the pipeline omits them
+type Defaults struct {
+	// ElasticsearchHost is the Elasticsearch service of the stack
+	ElasticsearchHost string
+	// ElasticsearchPort is the HTTP port of the Elasticsearch service
+	ElasticsearchPort int32
+	// Index is the index prefix of the default output
+	Index string
+	// DataStream makes the default output write to the data stream Index, managed by ILM
+	DataStream bool
+	// CAFile is the CA bundle verifying the Elasticsearch service of the stack. When set,
+	// the es outputs sending to the stack use TLS
+	CAFile string
+	// Credentials makes the es outputs sending to the stack authenticate with the
+	// UsernameEnv and PasswordEnv environment variables
+	Credentials bool
+}
+
+// Environment variables holding the credentials of the es outputs sending to the stack
+const (
+	UsernameEnv = "ELASTICSEARCH_USERNAME"
+	PasswordEnv = "ELASTICSEARCH_PASSWORD"
+)
+
+// Render validates the pipeline and returns the content of the ConfigMap, keyed by file name.
+// The fragments of the FluentBitPipelines, expected to be validated already, are appended
+// to the stack pipeline and scoped to the pods of their namespace
+func Render(cfg loggingv1.FluentBitConfig, pipelines []loggingv1.FluentBitPipeline, defaults Defaults) (map[string]string, error) {
+	if errs := cfg.Validate(field.NewPath("config")); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid Fluent Bit configuration: %w", errs.ToAggregate())
+	}
+
+	files := map[string]string{}
+	var conf section
+
+	flush := cfg.Service.Flush
+	if flush == 0 {
+		flush = 1
+	}
+	logLevel := cfg.Service.LogLevel
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	conf.open("SERVICE")
+	conf.set("Flush", strconv.Itoa(int(flush)))
+	conf.set("Log_Level", logLevel)
+	conf.set("Daemon", "off")
+	conf.set("Parsers_File", ParsersConfigFile)
+	conf.set("HTTP_Server", "On")
+	conf.set("HTTP_Listen", "0.0.0.0")
+	conf.set("HTTP_Port", "2020")
+
+	inputs := cfg.Inputs
+	if len(inputs) == 0 {
+		inputs = DefaultInputs()
+	}
+	for _, input := range inputs {
+		renderInput(&conf, input)
+	}
+
+	filters := cfg.Filters
+	if len(filters) == 0 {
+		filters = DefaultFilters()
+	}
+	for i, filter := range filters {
+		renderFilter(&conf, files, fmt.Sprintf("filter-%d.lua", i), filter)
+	}
+
+	indexRouting := false
+	for _, pipeline := range pipelines {
+		match := PipelineMatch(pipeline.Namespace)
+		if pipeline.Spec.Index != "" {
+			indexRouting = true
+			renderFilter(&conf, files, "", loggingv1.FluentBitFilter{
+				Match:  match,
+				Modify: &loggingv1.ModifyFilter{Set: map[string]string{IndexKey: pipeline.Spec.Index}},
+			})
+		}
+		for i, filter := range pipeline.Spec.Filters {
+			filter.Match = match
+			renderFilter(&conf, files, fmt.Sprintf("pipeline-%s-%s-%d.lua", pipeline.Namespace, pipeline.Name, i), filter)
+		}
+	}
+
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []loggingv1.FluentBitOutput{{Match: "*", Elasticsearch: &loggingv1.ElasticsearchOutput{
+			LogstashFormat: !defaults.DataStream,
+			DataStream:     defaults.DataStream,
+		}}}
+	}
+	for _, output := range outputs {
+		renderOutput(&conf, output, defaults, indexRouting)
+	}
+	for _, pipeline := range pipelines {
+		for _, output := range pipeline.Spec.Outputs {
+			output.Match = PipelineMatch(pipeline.Namespace)
+			renderOutput(&conf, output, defaults, false)
+		}
+	}
+
+	parsers := cfg.Parsers
+	for _, pipeline := range pipelines {
+		parsers = append(parsers, pipeline.Spec.Parsers...)
+	}
+
+	files[MainConfigFile] = conf.String()
+	files[ParsersConfigFile] = renderParsers(parsers)
+
+	return files, nil
+}
+
+// PipelineMatch returns the tag pattern of the container logs of a namespace. The tail
+// input tags records as kube.var.log.containers.<pod>_<namespace>_<container>-<id>.log
+func PipelineMatch(namespace string) string {
+	return fmt.Sprintf("kube.*_%s_*", namespace)
+}
+
+// DefaultInputs tails the container logs of the node
+func DefaultInputs() []loggingv1.FluentBitInput {
+	return []loggingv1.FluentBitInput{{
+		Tag: "kube.*",
+		Tail: &loggingv1.TailInput{
+			Path:             "/var/log/containers/*.log",
+			MultilineParsers: []string{"docker", "cri"},
+			MemBufLimit:      "50MB",
+			SkipLongLines:    true,
+			RefreshInterval:  5,
+		},
+	}}
+}
+
+// DefaultFilters enriches the container logs with Kubernetes metadata
+func DefaultFilters() []loggingv1.FluentBitFilter {
+	return []loggingv1.FluentBitFilter{{
+		Match: "kube.*",
+		Kubernetes: &loggingv1.KubernetesFilter{
+			MergeLog:         true,
+			K8sLoggingParser: true,
+		},
+	}}
+}
+
+func renderInput(conf *section, input loggingv1.FluentBitInput) {
+	conf.open("INPUT")
+	switch {
+	case input.Tail != nil:
+		tail := input.Tail
+		conf.set("Name", "tail")
+		conf.set("Path", tail.Path)
+		conf.set("Exclude_Path", tail.ExcludePath)
+		conf.set("Parser", tail.Parser)
+		conf.set("multiline.parser", strings.Join(tail.MultilineParsers, ", "))
+		conf.set("DB", "/var/fluent-bit/state/tail.db")
+		conf.set("Mem_Buf_Limit", tail.MemBufLimit)
+		conf.setBool("Skip_Long_Lines", tail.SkipLongLines)
+		conf.setInt("Refresh_Interval", tail.RefreshInterval)
+	case input.Systemd != nil:
+		conf.set("Name", "systemd")
+		for _, filter := range input.Systemd.Filters {
+			conf.set("Systemd_Filter", filter)
+		}
+		conf.set("DB", "/var/fluent-bit/state/systemd.db")
+		conf.setBool("Read_From_Tail", input.Systemd.ReadFromTail)
+		conf.setBool("Strip_Underscores", input.Systemd.StripUnderscores)
+	case input.Forward != nil:
+		conf.set("Name", "forward")
+		conf.set("Listen", input.Forward.Listen)
+		conf.setInt("Port", input.Forward.Port)
+	}
+	conf.set("Tag", input.Tag)
+	conf.setMap("", input.Properties)
+}
+
+// renderFilter writes a [FILTER] section; the script of a lua filter is added to files
+func renderFilter(conf *section, files map[string]string, script string, filter loggingv1.FluentBitFilter) {
+	if filter.Match == "" {
+		filter.Match = "*"
+	}
+	conf.open("FILTER")
+	switch {
+	case filter.Kubernetes != nil:
+		k8s := filter.Kubernetes
+		conf.set("Name", "kubernetes")
+		conf.set("Match", filter.Match)
+		conf.set("Kube_URL", "https://kubernetes.default.svc:443")
+		conf.set("Kube_CA_File", "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt")
+		conf.set("Kube_Token_File", "/var/run/secrets/kubernetes.io/serviceaccount/token")
+		conf.set("Kube_Tag_Prefix", "kube.var.log.containers.")
+		conf.setOnOff("Merge_Log", k8s.MergeLog)
+		conf.setOnOff("Keep_Log", k8s.KeepLog)
+		if k8s.Labels != nil {
+			conf.setOnOff("Labels", *k8s.Labels)
+		}
+		if k8s.Annotations != nil {
+			conf.setOnOff("Annotations", *k8s.Annotations)
+		}
+		conf.setOnOff("K8S-Logging.Parser", k8s.K8sLoggingParser)
+		conf.setOnOff("K8S-Logging.Exclude", k8s.K8sLoggingExclude)
+	case filter.Modify != nil:
+		conf.set("Name", "modify")
+		conf.set("Match", filter.Match)
+		conf.setMap("Set", filter.Modify.Set)
+		conf.setMap("Add", filter.Modify.Add)
+		for _, key := range filter.Modify.Remove {
+			conf.set("Remove", key)
+		}
+		conf.setMap("Rename", filter.Modify.Rename)
+	case filter.Grep != nil:
+		conf.set("Name", "grep")
+		conf.set("Match", filter.Match)
+		for _, rule := range filter.Grep.Regex {
+			conf.set("Regex", rule.Key+" "+rule.Pattern)
+		}
+		for _, rule := range filter.Grep.Exclude {
+			conf.set("Exclude", rule.Key+" "+rule.Pattern)
+		}
+	case filter.Nest != nil:
+		nest := filter.Nest
+		conf.set("Name", "nest")
+		conf.set("Match", filter.Match)
+		conf.set("Operation", nest.Operation)
+		for _, wildcard := range nest.Wildcard {
+			conf.set("Wildcard", wildcard)
+		}
+		conf.set("Nest_under", nest.NestUnder)
+		conf.set("Nested_under", nest.NestedUnder)
+		conf.set("Add_prefix", nest.AddPrefix)
+		conf.set("Remove_prefix", nest.RemovePrefix)
+	case filter.Lua != nil:
+		conf.set("Name", "lua")
+		conf.set("Match", filter.Match)
+		files[script] = filter.Lua.Script
+		conf.set("script", path.Join(ConfigDir, script))
+		conf.set("call", filter.Lua.Call)
+	case filter.Multiline != nil:
+		conf.set("Name", "multiline")
+		conf.set("Match", filter.Match)
+		conf.set("multiline.parser", strings.Join(filter.Multiline.Parsers, ", "))
+		conf.set("multiline.key_content", filter.Multiline.KeyContent)
+	case filter.Parser != nil:
+		conf.set("Name", "parser")
+		conf.set("Match", filter.Match)
+		conf.set("Key_Name", filter.Parser.KeyName)
+		for _, parser := range filter.Parser.Parsers {
+			conf.set("Parser", parser)
+		}
+		conf.setBool("Reserve_Data", filter.Parser.ReserveData)
+	}
+	conf.setMap("", filter.Properties)
+}
+
+// renderOutput writes an [OUTPUT] section. With indexRouting, logstash-formatted es outputs
+// take their prefix from the IndexKey set by the FluentBitPipelines
+func renderOutput(conf *section, output loggingv1.FluentBitOutput, defaults Defaults, indexRouting bool) {
+	if output.Match == "" {
+		output.Match = "*"
+	}
+	conf.open("OUTPUT")
+	switch {
+	case output.Elasticsearch != nil:
+		es := output.Elasticsearch
+		host, port, index, tls := es.Host, es.Port, es.Index, es.TLS
+		caFile, credentials := "", false
+		if host == "" {
+			host = defaults.ElasticsearchHost
+			credentials = defaults.Credentials
+			// Le service Elasticsearch de la stack n'écoute qu'en HTTPS avec les certificats de l'opérateur
+			if defaults.CAFile != "" {
+				tls, caFile = true, defaults.CAFile
+			}
+		}
+		if port == 0 {
+			port = defaults.ElasticsearchPort
+		}
+		if index == "" {
+			index = defaults.Index
+		}
+		conf.set("Name", "es")
+		conf.set("Match", output.Match)
+		conf.set("Host", host)
+		conf.setInt("Port", port)
+		if es.LogstashFormat {
+			conf.set("Logstash_Format", "On")
+			conf.set("Logstash_Prefix", index)
+			if indexRouting {
+				conf.set("Logstash_Prefix_Key", IndexKey)
+			}
+			conf.set("Logstash_DateFormat", "%Y.%m.%d")
+		} else {
+			conf.set("Index", index)
+		}
+		if credentials {
+			// Les credentials restent dans le Secret, Fluent Bit substitue les variables d'environnement
+			conf.set("HTTP_User", "${"+UsernameEnv+"}")
+			conf.set("HTTP_Passwd", "${"+PasswordEnv+"}")
+		}
+		if es.DataStream {
+			// Les data streams n'acceptent que des créations de documents
+			conf.set("Write_Operation", "create")
+		}
+		// Elasticsearch 8 n'accepte plus le champ _type
+		conf.set("Suppress_Type_Name", "On")
+		conf.setOnOff("Replace_Dots", es.ReplaceDots)
+		conf.setOnOff("tls", tls)
+		if caFile != "" {
+			conf.set("tls.verify", "On")
+			conf.set("tls.ca_file", caFile)
+		}
+		conf.set("Retry_Limit", "6")
+		// Les propriétés brutes pourraient rediriger les credentials de la stack vers un autre hôte
+		if es.Host == "" {
+			return
+		}
+	case output.Forward != nil:
+		conf.set("Name", "forward")
+		conf.set("Match", output.Match)
+		conf.set("Host", output.Forward.Host)
+		conf.setInt("Port", output.Forward.Port)
+	case output.HTTP != nil:
+		http := output.HTTP
+		conf.set("Name", "http")
+		conf.set("Match", output.Match)
+		conf.set("Host", http.Host)
+		conf.setInt("Port", http.Port)
+		conf.set("URI", http.URI)
+		conf.set("Format", http.Format)
+		conf.setOnOff("tls", http.TLS)
+		conf.setMap("Header", http.Headers)
+	case output.Stdout != nil:
+		conf.set("Name", "stdout")
+		conf.set("Match", output.Match)
+		conf.set("Format", output.Stdout.Format)
+	}
+	conf.setMap("", output.Properties)
+}
+
+// renderParsers returns parsers.conf with the built-in parsers followed by the custom ones
+func renderParsers(parsers []loggingv1.FluentBitParser) string {
+	var conf section
+
+	conf.open("PARSER")
+	conf.set("Name", "docker")
+	conf.set("Format", "json")
+	conf.set("Time_Key", "time")
+	conf.set("Time_Format", "%Y-%m-%dT%H:%M:%S.%L")
+	conf.set("Time_Keep", "On")
+
+	conf.open("PARSER")
+	conf.set("Name", "cri")
+	conf.set("Format", "regex")
+	conf.set("Regex", `^(?<time>[^ ]+) (?<stream>stdout|stderr) (?<logtag>[^ ]*) (?<message>.*)$`)
+	conf.set("Time_Key", "time")
+	conf.set("Time_Format", "%Y-%m-%dT%H:%M:%S.%L%z")
+
+	conf.open("PARSER")
+	conf.set("Name", "json")
+	conf.set("Format", "json")
+
+	for _, parser := range parsers {
+		conf.open("PARSER")
+		conf.set("Name", parser.Name)
+		conf.set("Format", parser.Format)
+		conf.set("Regex", parser.Regex)
+		conf.set("Time_Key", parser.TimeKey)
+		conf.set("Time_Format", parser.TimeFormat)
+	}
+
+	return conf.String()
+}
+
+// section writes the classic Fluent Bit format, skipping empty values
+type section struct {
+	b strings.Builder
+}
+
+func (s *section) open(name string) {
+	if s.b.Len() > 0 {
+		s.b.WriteString("\n")
+	}
+	fmt.Fprintf(&s.b, "[%s]\n", name)
+}
+
+func (s *section) set(key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(&s.b, "    %s %s\n", key, value)
+}
+
+func (s *section) setInt(key string, value int32) {
+	if value != 0 {
+		s.set(key, strconv.Itoa(int(value)))
+	}
+}
+
+func (s *section) setBool(key string, value bool) {
+	if value {
+		s.set(key, "On")
+	}
+}
+
+func (s *section) setOnOff(key string, value bool) {
+	if value {
+		s.set(key, "On")
+	} else {
+		s.set(key, "Off")
+	}
+}
+
+// setMap writes one line per entry, sorted by key to keep the output stable. With a
+// prefix, entries are written as "<prefix> <key> <value>", otherwise as "<key> <value>"
+func (s *section) setMap(prefix string, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if prefix == "" {
+			s.set(key, values[key])
+		} else {
+			s.set(prefix, key+" "+values[key])
+		}
+	}
+}
+
+func (s *section) String() string {
+	return s.b.String()
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fluentbit
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
+)
+
+func TestFluentBit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fluent Bit Suite")
+}
+
+var _ = Describe("Render", func() {
+	defaults := Defaults{
+		ElasticsearchHost: "test-efk-stack-elasticsearch",
+		ElasticsearchPort: 9200,
+		Index:             "fluent-bit",
+	}
+
+	It("Should render the default pipeline", func() {
+		files, err := Render(loggingv1.FluentBitConfig{}, nil, defaults)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveKey(MainConfigFile))
+		Expect(files).To(HaveKey(ParsersConfigFile))
+
+		conf := files[MainConfigFile]
+		Expect(conf).To(ContainSubstring("[SERVICE]\n    Flush 1\n    Log_Level info\n"))
+		Expect(conf).To(ContainSubstring("    Name tail\n    Path /var/log/containers/*.log\n"))
+		Expect(conf).To(ContainSubstring("    multiline.parser docker, cri\n"))
+		Expect(conf).To(ContainSubstring("    Name kubernetes\n    Match kube.*\n"))
+		Expect(conf).To(ContainSubstring("    Host test-efk-stack-elasticsearch\n    Port 9200\n"))
+		Expect(conf).To(ContainSubstring("    Logstash_Prefix fluent-bit\n"))
+		Expect(files[ParsersConfigFile]).To(ContainSubstring("    Name cri\n"))
+	})
+
+	It("Should write the default output to a data stream", func() {
+		dataStream := defaults
+		dataStream.DataStream = true
+
+		files, err := Render(loggingv1.FluentBitConfig{}, nil, dataStream)
+		Expect(err).NotTo(HaveOccurred())
+
+		conf := files[MainConfigFile]
+		Expect(conf).To(ContainSubstring("    Index fluent-bit\n"))
+		Expect(conf).To(ContainSubstring("    Write_Operation create\n"))
+		Expect(conf).NotTo(ContainSubstring("Logstash_Format"))
+	})
+
+	It("Should verify the stack Elasticsearch with its CA and authenticate", func() {
+		secured := defaults
+		secured.CAFile = "/fluent-bit/tls/ca.crt"
+		secured.Credentials = true
+
+		cfg := loggingv1.FluentBitConfig{
+			Outputs: []loggingv1.FluentBitOutput{
+				{Match: "kube.*", Elasticsearch: &loggingv1.ElasticsearchOutput{LogstashFormat: true}},
+				{Match: "audit.*", Elasticsearch: &loggingv1.ElasticsearchOutput{Host: "archive", Port: 9200, Index: "audit"}},
+			},
+		}
+		files, err := Render(cfg, nil, secured)
+		Expect(err).NotTo(HaveOccurred())
+
+		conf := files[MainConfigFile]
+		Expect(conf).To(ContainSubstring("    tls On\n    tls.verify On\n    tls.ca_file /fluent-bit/tls/ca.crt\n"))
+		Expect(conf).To(ContainSubstring("    HTTP_User ${ELASTICSEARCH_USERNAME}\n    HTTP_Passwd ${ELASTICSEARCH_PASSWORD}\n"))
+		// Les clusters externes gardent leur propre configuration TLS et leurs credentials
+		Expect(strings.Count(conf, "tls.ca_file")).To(Equal(1))
+		Expect(strings.Count(conf, "HTTP_User")).To(Equal(1))
+		Expect(conf).To(ContainSubstring("    Host archive\n"))
+	})
+
+	It("Should not render raw properties on the outputs to the stack Elasticsearch", func() {
+		secured := defaults
+		secured.Credentials = true
+		pipelines := []loggingv1.FluentBitPipeline{{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a"},
+			Spec: loggingv1.FluentBitPipelineSpec{
+				Outputs: []loggingv1.FluentBitOutput{{
+					Elasticsearch: &loggingv1.ElasticsearchOutput{Index: "team-a"},
+					Properties:    map[string]string{"Host": "attacker.example", "tls.verify": "Off"},
+				}},
+			},
+		}}
+
+		files, err := Render(loggingv1.FluentBitConfig{}, pipelines, secured)
+		Expect(err).NotTo(HaveOccurred())
+
+		conf := files[MainConfigFile]
+		Expect(strings.Count(conf, "HTTP_User")).To(Equal(2))
+		Expect(conf).NotTo(ContainSubstring("attacker.example"))
+		Expect(conf).NotTo(ContainSubstring("tls.verify Off"))
+	})
+
+	It("Should render every plugin in order", func() {
+		labels := false
+		cfg := loggingv1.FluentBitConfig{
+			Service: loggingv1.FluentBitService{Flush: 5, LogLevel: "debug"},
+			Parsers: []loggingv1.FluentBitParser{{Name: "nginx", Format: "regex", Regex: `^(?<remote>[^ ]*) (?<message>.*)$`}},
+			Inputs: []loggingv1.FluentBitInput{
+				{Tag: "host.*", Systemd: &loggingv1.SystemdInput{Filters: []string{"_SYSTEMD_UNIT=kubelet.service"}, ReadFromTail: true}},
+				{Tag: "fwd.*", Forward: &loggingv1.ForwardInput{Listen: "0.0.0.0", Port: 24224}},
+			},
+			Filters: []loggingv1.FluentBitFilter{
+				{Match: "kube.*", Kubernetes: &loggingv1.KubernetesFilter{MergeLog: true, Labels: &labels}},
+				{Match: "*", Modify: &loggingv1.ModifyFilter{Set: map[string]string{"cluster": "prod", "env": "production"}, Remove: []string{"stream"}}},
+				{Match: "*", Grep: &loggingv1.GrepFilter{Exclude: []loggingv1.GrepRule{{Key: "log", Pattern: "^healthz"}}}},
+				{Match: "*", Nest: &loggingv1.NestFilter{Operation: "lift", NestedUnder: "kubernetes", AddPrefix: "k8s_"}},
+				{Match: "*", Lua: &loggingv1.LuaFilter{Script: "function tag(tag, ts, record)\n  return 0, ts, record\nend\n", Call: "tag"}},
+				{Match: "*", Multiline: &loggingv1.MultilineFilter{Parsers: []string{"java"}, KeyContent: "log"}},
+			},
+			Outputs: []loggingv1.FluentBitOutput{
+				{Match: "kube.*", Elasticsearch: &loggingv1.ElasticsearchOutput{Index: "k8s"}},
+				{Match: "host.*", HTTP: &loggingv1.HTTPOutput{Host: "collector", Port: 8080, URI: "/logs", Format: "json", Headers: map[string]string{"X-Tenant": "prod"}}},
+				{Match: "fwd.*", Forward: &loggingv1.ForwardOutput{Host: "aggregator", Port: 24224}},
+				{Match: "*", Stdout: &loggingv1.StdoutOutput{Format: "json_lines"}},
+			},
+		}
+
+		files, err := Render(cfg, nil, defaults)
+		Expect(err).NotTo(HaveOccurred())
+
+		conf := files[MainConfigFile]
+		Expect(conf).To(ContainSubstring("    Flush 5\n    Log_Level debug\n"))
+		Expect(conf).To(ContainSubstring("    Name systemd\n    Systemd_Filter _SYSTEMD_UNIT=kubelet.service\n"))
+		Expect(conf).To(ContainSubstring("    Name forward\n    Listen 0.0.0.0\n    Port 24224\n    Tag fwd.*\n"))
+		Expect(conf).To(ContainSubstring("    Labels Off\n"))
+		Expect(conf).To(ContainSubstring("    Set cluster prod\n    Set env production\n    Remove stream\n"))
+		Expect(conf).To(ContainSubstring("    Exclude log ^healthz\n"))
+		Expect(conf).To(ContainSubstring("    Operation lift\n    Nested_under kubernetes\n    Add_prefix k8s_\n"))
+		Expect(conf).To(ContainSubstring("    script /fluent-bit/etc/filter-4.lua\n    call tag\n"))
+		Expect(conf).To(ContainSubstring("    multiline.parser java\n    multiline.key_content log\n"))
+		Expect(conf).To(ContainSubstring("    Index k8s\n"))
+		Expect(conf).To(ContainSubstring("    Header X-Tenant prod\n"))
+		Expect(conf).To(ContainSubstring("    Name stdout\n    Match *\n    Format json_lines\n"))
+		Expect(conf).NotTo(ContainSubstring("Name tail"))
+
+		Expect(files).To(HaveKeyWithValue("filter-4.lua", cfg.Filters[4].Lua.Script))
+		Expect(files[ParsersConfigFile]).To(ContainSubstring("    Name nginx\n    Format regex\n"))
+	})
+
+	It("Should scope the FluentBitPipelines to their namespace", func() {
+		pipelines := []loggingv1.FluentBitPipeline{{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "team-a"},
+			Spec: loggingv1.FluentBitPipelineSpec{
+				Index:   "team-a",
+				Parsers: []loggingv1.FluentBitParser{{Name: "team-a-json", Format: "json"}},
+				Filters: []loggingv1.FluentBitFilter{
+					{Parser: &loggingv1.ParserFilter{KeyName: "log", Parsers: []string{"team-a-json"}}},
+					{Lua: &loggingv1.LuaFilter{Script: "function f(tag, ts, record) return 0, ts, record end", Call: "f"}},
+				},
+				Outputs: []loggingv1.FluentBitOutput{{Stdout: &loggingv1.StdoutOutput{}}},
+			},
+		}}
+
+		files, err := Render(loggingv1.FluentBitConfig{}, pipelines, defaults)
+		Expect(err).NotTo(HaveOccurred())
+
+		conf := files[MainConfigFile]
+		Expect(conf).To(ContainSubstring("    Name modify\n    Match kube.*_team-a_*\n    Set log_index team-a\n"))
+		Expect(conf).To(ContainSubstring("    Name parser\n    Match kube.*_team-a_*\n    Key_Name log\n    Parser team-a-json\n"))
+		Expect(conf).To(ContainSubstring("    script /fluent-bit/etc/pipeline-team-a-logs-1.lua\n"))
+		Expect(conf).To(ContainSubstring("    Logstash_Prefix fluent-bit\n    Logstash_Prefix_Key log_index\n"))
+		Expect(conf).To(ContainSubstring("    Name stdout\n    Match kube.*_team-a_*\n"))
+		// Stack filters run before the fragments
+		Expect(strings.Index(conf, "Name kubernetes")).To(BeNumerically("<", strings.Index(conf, "Name parser")))
+		Expect(files).To(HaveKey("pipeline-team-a-logs-1.lua"))
+		Expect(files[ParsersConfigFile]).To(ContainSubstring("    Name team-a-json\n"))
+	})
+
+	It("Should refuse an invalid pipeline", func() {
+		cfg := loggingv1.FluentBitConfig{
+			Inputs: []loggingv1.FluentBitInput{{
+				Tail:    &loggingv1.TailInput{Path: "/var/log/*.log"},
+				Systemd: &loggingv1.SystemdInput{},
+			}},
+		}
+		_, err := Render(cfg, nil, defaults)
+		Expect(err).To(MatchError(ContainSubstring("exactly one plugin")))
+	})
+})
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ClientCache keeps one Helm client per target namespace so that EFKStacks
+// deployed in different namespaces do not share the same action configuration
+type ClientCache struct {
+	restConfig *rest.Config
+	kubeClient kubernetes.Interface
+	charts     ChartLoader
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewClientCache creates an empty Helm client cache whose clients share the same chart loader
+func NewClientCache(restConfig *rest.Config, kubeClient kubernetes.Interface, charts ChartLoader) *ClientCache {
+	if charts == nil {
+		charts = NewEmbeddedChartLoader()
+	}
+	return &ClientCache{
+		restConfig: restConfig,
+		kubeClient: kubeClient,
+		charts:     charts,
+		clients:    make(map[string]*Client),
+	}
+}
+
+// Get returns the Helm client for the namespace, creating it on first use
+func (c *ClientCache) Get(namespace string) (*Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[namespace]; ok {
+		return client, nil
+	}
+
+	client, err := NewClient(c.restConfig, c.kubeClient, namespace, c.charts)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[namespace] = client
+
+	return client, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+func TestHelm(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Helm Suite")
+}
+
+var _ = Describe("ClientCache", func() {
+	var cache *ClientCache
+
+	BeforeEach(func() {
+		cache = NewClientCache(&rest.Config{Host: "https://127.0.0.1:6443"}, nil, nil)
+	})
+
+	It("Should reuse the client of a namespace", func() {
+		first, err := cache.Get("logging")
+		Expect(err).NotTo(HaveOccurred())
+		second, err := cache.Get("logging")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("Should create one client per namespace", func() {
+		logging, err := cache.Get("logging")
+		Expect(err).NotTo(HaveOccurred())
+		monitoring, err := cache.Get("monitoring")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(monitoring).NotTo(BeIdenticalTo(logging))
+		Expect(logging.namespace).To(Equal("logging"))
+		Expect(monitoring.namespace).To(Equal("monitoring"))
+	})
+
+	It("Should resolve the namespace from the rest client getter", func() {
+		getter := newRESTClientGetter(&rest.Config{Host: "https://127.0.0.1:6443"}, "logging")
+		namespace, _, err := getter.ToRawKubeConfigLoader().Namespace()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespace).To(Equal("logging"))
+	})
+
+	It("Should refuse to build a client without a rest config", func() {
+		_, err := NewClientCache(nil, nil, nil).Get("logging")
+		Expect(err).To(HaveOccurred())
+	})
+})
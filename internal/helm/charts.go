@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+
+	charts "github.com/zlorgoncho1/efk-operator/helm-charts"
+)
+
+// Names of the component charts
+const (
+	ElasticsearchChart = "elasticsearch"
+	FluentBitChart     = "fluentbit"
+	KibanaChart        = "kibana"
+)
+
+// ChartLoader loads a component chart by name
+type ChartLoader interface {
+	Load(name string) (*chart.Chart, error)
+}
+
+// NewChartLoader returns the loader matching the operator flags: an OCI repository
+// takes precedence over a chart directory, and the embedded charts are used otherwise
+func NewChartLoader(chartDir, ociRepository, ociVersion string) (ChartLoader, error) {
+	switch {
+	case ociRepository != "":
+		return NewOCIChartLoader(ociRepository, ociVersion)
+	case chartDir != "":
+		return NewDirChartLoader(chartDir), nil
+	default:
+		return NewEmbeddedChartLoader(), nil
+	}
+}
+
+// EmbeddedChartLoader loads the charts compiled into the operator binary
+type EmbeddedChartLoader struct {
+	fsys fs.FS
+	root string
+
+	mu     sync.Mutex
+	charts map[string]*chart.Chart
+}
+
+// NewEmbeddedChartLoader creates a loader for the embedded efk-stack charts
+func NewEmbeddedChartLoader() *EmbeddedChartLoader {
+	return &EmbeddedChartLoader{
+		fsys:   charts.FS,
+		root:   charts.Root,
+		charts: make(map[string]*chart.Chart),
+	}
+}
+
+// Load returns the embedded chart, parsing it only once
+func (l *EmbeddedChartLoader) Load(name string) (*chart.Chart, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c, ok := l.charts[name]; ok {
+		return c, nil
+	}
+
+	dir := path.Join(l.root, name)
+	var files []*loader.BufferedFile
+	err := fs.WalkDir(l.fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(l.fsys, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, &loader.BufferedFile{
+			Name: strings.TrimPrefix(p, dir+"/"),
+			Data: data,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded chart %s: %w", name, err)
+	}
+
+	c, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded chart %s: %w", name, err)
+	}
+	l.charts[name] = c
+
+	return c, nil
+}
+
+// DirChartLoader loads the charts from a directory on disk, e.g. a mounted volume
+// containing patched charts. Charts are read on every call so that updates of the
+// volume are picked up without restarting the operator
+type DirChartLoader struct {
+	dir string
+}
+
+// NewDirChartLoader creates a loader reading <dir>/<name> for each chart
+func NewDirChartLoader(dir string) *DirChartLoader {
+	return &DirChartLoader{dir: dir}
+}
+
+// Load reads the chart from disk
+func (l *DirChartLoader) Load(name string) (*chart.Chart, error) {
+	chartPath := filepath.Join(l.dir, name)
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart from path %s: %w", chartPath, err)
+	}
+	return c, nil
+}
+
+// OCIChartLoader pulls the charts from an OCI registry, as <repository>/<name>:<version>
+type OCIChartLoader struct {
+	repository string
+	version    string
+	client     *registry.Client
+
+	mu     sync.Mutex
+	charts map[string]*chart.Chart
+}
+
+// NewOCIChartLoader creates a loader for an OCI repository such as
+// oci://registry.example.com/efk-charts. Registry credentials are read from the
+// Docker config of the operator
+func NewOCIChartLoader(repository, version string) (*OCIChartLoader, error) {
+	if !registry.IsOCI(repository) {
+		return nil, fmt.Errorf("chart repository %s must start with %s://", repository, registry.OCIScheme)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("a chart version is required to pull charts from %s", repository)
+	}
+
+	client, err := registry.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCI registry client: %w", err)
+	}
+
+	return &OCIChartLoader{
+		repository: strings.TrimSuffix(strings.TrimPrefix(repository, registry.OCIScheme+"://"), "/"),
+		version:    version,
+		client:     client,
+		charts:     make(map[string]*chart.Chart),
+	}, nil
+}
+
+// Reference returns the OCI reference of a chart
+func (l *OCIChartLoader) Reference(name string) string {
+	return fmt.Sprintf("%s/%s:%s", l.repository, name, l.version)
+}
+
+// Load pulls the chart once and keeps it in memory, the version being immutable
+func (l *OCIChartLoader) Load(name string) (*chart.Chart, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if c, ok := l.charts[name]; ok {
+		return c, nil
+	}
+
+	ref := l.Reference(name)
+	result, err := l.client.Pull(ref, registry.PullOptWithChart(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull chart %s: %w", ref, err)
+	}
+
+	c, err := loader.LoadArchive(bytes.NewReader(result.Chart.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chart %s: %w", ref, err)
+	}
+	l.charts[name] = c
+
+	return c, nil
+}
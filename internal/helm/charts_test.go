@@ -0,0 +1,299 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+var _ = Describe("ChartLoader", func() {
+	It("Should load every component chart from the binary", func() {
+		loader := NewEmbeddedChartLoader()
+		for _, name := range []string{ElasticsearchChart, FluentBitChart, KibanaChart} {
+			c, err := loader.Load(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Name()).To(Equal(name))
+			Expect(c.Templates).NotTo(BeEmpty())
+			Expect(c.Values).NotTo(BeEmpty())
+		}
+	})
+
+	It("Should parse an embedded chart only once", func() {
+		loader := NewEmbeddedChartLoader()
+		first, err := loader.Load(KibanaChart)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := loader.Load(KibanaChart)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("Should render one Elasticsearch StatefulSet per node set", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+			"nodeSets": []interface{}{
+				map[string]interface{}{"name": "master", "roles": []string{"master"}, "replicas": 3,
+					"resources": map[string]interface{}{"requests": map[string]interface{}{"memory": "1Gi"}},
+					"storage":   map[string]interface{}{"size": "10Gi"}},
+				map[string]interface{}{"name": "hot", "roles": []string{"data_hot", "data_content"}, "replicas": 2,
+					"resources": map[string]interface{}{"requests": map[string]interface{}{"memory": "4Gi"}},
+					"storage":   map[string]interface{}{"size": "100Gi"}},
+			},
+		}, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		manifests, err := engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+		statefulSets := manifests["elasticsearch/templates/statefulset.yaml"]
+		Expect(statefulSets).To(ContainSubstring("name: test-elasticsearch-master\n"))
+		Expect(statefulSets).To(ContainSubstring("name: test-elasticsearch-hot\n"))
+		Expect(statefulSets).To(ContainSubstring(`value: "data_hot,data_content"`))
+		Expect(statefulSets).To(ContainSubstring(`value: "test-elasticsearch-master-0,test-elasticsearch-master-1,test-elasticsearch-master-2"`))
+		Expect(manifests["elasticsearch/templates/pdb.yaml"]).To(ContainSubstring("elasticsearch.efk.crds.io/node-set: hot"))
+	})
+
+	It("Should mount the PEM certificates of the operator on every node", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+			"security": map[string]interface{}{
+				"certificates": map[string]interface{}{
+					"httpSecretName":      "test-elasticsearch-http-certs",
+					"transportSecretName": "test-elasticsearch-transport-certs",
+				},
+			},
+		}, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		manifests, err := engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+		statefulSet := manifests["elasticsearch/templates/statefulset.yaml"]
+		Expect(statefulSet).To(ContainSubstring(`value: "/usr/share/elasticsearch/config/certs/transport/$(node.name).tls.crt"`))
+		Expect(statefulSet).To(ContainSubstring("- name: xpack.security.http.ssl.enabled\n          value: \"true\""))
+		Expect(statefulSet).To(ContainSubstring("secretName: test-elasticsearch-transport-certs"))
+		Expect(statefulSet).To(ContainSubstring("https://127.0.0.1:9200/"))
+		Expect(statefulSet).NotTo(ContainSubstring("elasticsearch.p12"))
+	})
+
+	It("Should share the cert-manager transport certificate and serve Kibana over HTTPS", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+			"security": map[string]interface{}{
+				"certificates": map[string]interface{}{
+					"httpSecretName":      "test-elasticsearch-http-certs",
+					"transportSecretName": "test-elasticsearch-transport-certs",
+					"sharedTransport":     true,
+				},
+			},
+		}, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		manifests, err := engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifests["elasticsearch/templates/statefulset.yaml"]).To(ContainSubstring(`value: "/usr/share/elasticsearch/config/certs/transport/tls.crt"`))
+
+		c, err = NewEmbeddedChartLoader().Load(KibanaChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err = chartutil.ToRenderValues(c, map[string]interface{}{
+			"tls": map[string]interface{}{"secretName": "test-kibana-certs", "checksum": "0123456789abcdef"},
+		}, chartutil.ReleaseOptions{Name: "test-kibana", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		manifests, err = engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+		deployment := manifests["kibana/templates/deployment.yaml"]
+		Expect(deployment).To(ContainSubstring("- name: SERVER_SSL_ENABLED\n          value: \"true\""))
+		Expect(deployment).To(ContainSubstring("secretName: test-kibana-certs"))
+		Expect(deployment).To(ContainSubstring(`efk.crds.io/certificate-checksum: "0123456789abcdef"`))
+		Expect(deployment).To(ContainSubstring("scheme: HTTPS"))
+	})
+
+	It("Should set the elastic bootstrap password and accept anonymous 401 in the probes", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+			"security": map[string]interface{}{"authEnabled": true, "authSecretName": "test-elasticsearch-elastic-user"},
+		}, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		manifests, err := engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+		statefulSet := manifests["elasticsearch/templates/statefulset.yaml"]
+		Expect(statefulSet).To(ContainSubstring("- name: ELASTIC_PASSWORD\n          valueFrom:\n            secretKeyRef:\n              name: test-elasticsearch-elastic-user"))
+		Expect(statefulSet).To(ContainSubstring("http://127.0.0.1:9200/"))
+		Expect(statefulSet).NotTo(ContainSubstring("httpGet"))
+	})
+
+	It("Should resolve the images of every chart from the registry, tag and digest", func() {
+		render := func(name, template string, overrides map[string]interface{}) string {
+			c, err := NewEmbeddedChartLoader().Load(name)
+			Expect(err).NotTo(HaveOccurred())
+			values, err := chartutil.ToRenderValues(c, overrides, chartutil.ReleaseOptions{Name: "test-" + name, Namespace: "logging"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			manifests, err := engine.Render(c, values)
+			Expect(err).NotTo(HaveOccurred())
+			return manifests[name+"/templates/"+template]
+		}
+
+		// Le tag suit la version du composant
+		Expect(render(KibanaChart, "deployment.yaml", map[string]interface{}{"version": "8.12.2"})).
+			To(ContainSubstring(`image: "docker.elastic.co/kibana/kibana:8.12.2"`))
+		fluentBit := render(FluentBitChart, "daemonset.yaml", map[string]interface{}{
+			"image":            map[string]interface{}{"registry": "registry.example.com", "tag": "3.0.4"},
+			"imagePullSecrets": []interface{}{map[string]interface{}{"name": "registry-credentials"}},
+		})
+		Expect(fluentBit).To(ContainSubstring(`image: "registry.example.com/fluent/fluent-bit:3.0.4"`))
+		Expect(fluentBit).To(ContainSubstring("- name: registry-credentials"))
+
+		digest := "sha256:" + strings.Repeat("a", 64)
+		elasticsearch := render(ElasticsearchChart, "statefulset.yaml", map[string]interface{}{
+			"image": map[string]interface{}{"registry": "", "repository": "mirror.local/elastic/elasticsearch", "digest": digest},
+		})
+		Expect(elasticsearch).To(ContainSubstring(`image: "mirror.local/elastic/elasticsearch@` + digest + `"`))
+	})
+
+	It("Should mount the elasticsearch.yml settings and restart the nodes when they change", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		render := func(overrides map[string]interface{}) map[string]string {
+			values, err := chartutil.ToRenderValues(c, overrides, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			manifests, err := engine.Render(c, values)
+			Expect(err).NotTo(HaveOccurred())
+			return manifests
+		}
+
+		manifests := render(nil)
+		Expect(strings.TrimSpace(manifests["elasticsearch/templates/configmap.yaml"])).To(BeEmpty())
+		Expect(manifests["elasticsearch/templates/statefulset.yaml"]).NotTo(ContainSubstring("elasticsearch.yml"))
+
+		manifests = render(map[string]interface{}{
+			"config": map[string]interface{}{"indices.memory.index_buffer_size": "20%", "action.destructive_requires_name": "true"},
+		})
+		Expect(manifests["elasticsearch/templates/configmap.yaml"]).To(ContainSubstring(
+			"  elasticsearch.yml: |\n    network.host: 0.0.0.0\n    action.destructive_requires_name: \"true\"\n    indices.memory.index_buffer_size: 20%\n"))
+		statefulSet := manifests["elasticsearch/templates/statefulset.yaml"]
+		Expect(statefulSet).To(ContainSubstring("subPath: elasticsearch.yml"))
+		Expect(statefulSet).To(ContainSubstring("name: test-elasticsearch-config"))
+		Expect(statefulSet).To(ContainSubstring("efk.crds.io/config-checksum:"))
+
+		// Une modification des settings change le template des pods
+		changed := render(map[string]interface{}{
+			"config": map[string]interface{}{"indices.memory.index_buffer_size": "30%"},
+		})["elasticsearch/templates/statefulset.yaml"]
+		Expect(changed).NotTo(Equal(statefulSet))
+	})
+
+	It("Should rebuild the keystore from optional Secrets in a sidecar", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		values, err := chartutil.ToRenderValues(c, map[string]interface{}{
+			"keystore": []interface{}{
+				map[string]interface{}{"setting": "s3.client.default.access_key", "secretName": "snapshot-credentials", "key": "access_key"},
+				map[string]interface{}{"secretName": "oidc-client-secrets"},
+			},
+			"keystoreUpdater": map[string]interface{}{"enabled": true},
+		}, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		manifests, err := engine.Render(c, values)
+		Expect(err).NotTo(HaveOccurred())
+
+		statefulSet, err := chartutil.ReadValues([]byte(manifests["elasticsearch/templates/statefulset.yaml"]))
+		Expect(err).NotTo(HaveOccurred())
+		podSpec, err := statefulSet.Table("spec.template.spec")
+		Expect(err).NotTo(HaveOccurred())
+		var names []interface{}
+		for _, container := range podSpec["containers"].([]interface{}) {
+			names = append(names, container.(map[string]interface{})["name"])
+		}
+		Expect(names).To(Equal([]interface{}{"elasticsearch", "keystore-updater"}))
+
+		sources := podSpec["volumes"].([]interface{})[0].(map[string]interface{})["projected"].(map[string]interface{})["sources"].([]interface{})
+		Expect(sources).To(HaveLen(2))
+		Expect(sources[0]).To(HaveKeyWithValue("secret", HaveKeyWithValue("optional", true)))
+		Expect(sources[0]).To(HaveKeyWithValue("secret", HaveKey("items")))
+		Expect(sources[1]).To(HaveKeyWithValue("secret", Equal(map[string]interface{}{"name": "oidc-client-secrets", "optional": true})))
+	})
+
+	It("Should set ES_JAVA_OPTS from javaOpts per node set", func() {
+		c, err := NewEmbeddedChartLoader().Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		render := func(overrides map[string]interface{}) map[string]string {
+			values, err := chartutil.ToRenderValues(c, overrides, chartutil.ReleaseOptions{Name: "test-elasticsearch", Namespace: "logging"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			manifests, err := engine.Render(c, values)
+			Expect(err).NotTo(HaveOccurred())
+			return manifests
+		}
+
+		Expect(render(nil)["elasticsearch/templates/statefulset.yaml"]).To(ContainSubstring(
+			"- name: ES_JAVA_OPTS\n          value: \"-Xms4g -Xmx4g\"\n"))
+		Expect(render(map[string]interface{}{"mode": "singleton", "javaOpts": "-Xms1024m -Xmx1024m"})["elasticsearch/templates/deployment.yaml"]).To(ContainSubstring(
+			"value: \"-Xms1024m -Xmx1024m\""))
+
+		statefulSets := render(map[string]interface{}{
+			"nodeSets": []interface{}{
+				map[string]interface{}{"name": "masters", "replicas": 3, "roles": []interface{}{"master"}, "javaOpts": "-Xms512m -Xmx512m",
+					"resources": map[string]interface{}{}, "storage": map[string]interface{}{"size": "10Gi"}},
+				map[string]interface{}{"name": "data", "replicas": 2, "javaOpts": "",
+					"resources": map[string]interface{}{}, "storage": map[string]interface{}{"size": "100Gi"}},
+			},
+		})["elasticsearch/templates/statefulset.yaml"]
+		Expect(statefulSets).To(ContainSubstring("value: \"-Xms512m -Xmx512m\""))
+		// Sans javaOpts, Elasticsearch dimensionne le heap lui-même
+		Expect(strings.Count(statefulSets, "ES_JAVA_OPTS")).To(Equal(1))
+	})
+
+	It("Should fail on an unknown chart", func() {
+		_, err := NewEmbeddedChartLoader().Load("logstash")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should load the charts from a directory", func() {
+		loader := NewDirChartLoader(filepath.Join("..", "..", "helm-charts", "efk-stack"))
+		c, err := loader.Load(ElasticsearchChart)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Name()).To(Equal(ElasticsearchChart))
+	})
+
+	It("Should select the loader from the flags", func() {
+		loader, err := NewChartLoader("", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader).To(BeAssignableToTypeOf(&EmbeddedChartLoader{}))
+
+		loader, err = NewChartLoader("/charts", "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader).To(BeAssignableToTypeOf(&DirChartLoader{}))
+
+		loader, err = NewChartLoader("/charts", "oci://registry.example.com/efk-charts/", "1.2.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader).To(BeAssignableToTypeOf(&OCIChartLoader{}))
+		Expect(loader.(*OCIChartLoader).Reference(KibanaChart)).To(Equal("registry.example.com/efk-charts/kibana:1.2.0"))
+	})
+
+	It("Should reject an invalid OCI repository", func() {
+		_, err := NewOCIChartLoader("registry.example.com/efk-charts", "1.2.0")
+		Expect(err).To(HaveOccurred())
+		_, err = NewOCIChartLoader("oci://registry.example.com/efk-charts", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
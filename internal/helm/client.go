@@ -18,12 +18,12 @@ package helm
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"time"
 
 	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/chart/loader"
-	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -37,16 +37,23 @@ type Client struct {
 	kubeClient   kubernetes.Interface
 	restConfig   *rest.Config
 	namespace    string
+	charts       ChartLoader
 }
 
-// NewClient creates a new Helm client
-func NewClient(restConfig *rest.Config, kubeClient kubernetes.Interface, namespace string) (*Client, error) {
-	actionConfig := new(action.Configuration)
+// NewClient creates a new Helm client bound to the given namespace, using the
+// operator's rest.Config to reach the API server. Charts are read from the embedded
+// charts when no loader is given
+func NewClient(restConfig *rest.Config, kubeClient kubernetes.Interface, namespace string, charts ChartLoader) (*Client, error) {
+	if restConfig == nil {
+		return nil, fmt.Errorf("a rest config is required to create a Helm client")
+	}
+	if charts == nil {
+		charts = NewEmbeddedChartLoader()
+	}
 
-	settings := cli.New()
-	settings.SetNamespace(namespace)
+	actionConfig := new(action.Configuration)
 
-	if err := actionConfig.Init(settings.RESTClientGetter(), namespace, "secret", func(format string, v ...interface{}) {
+	if err := actionConfig.Init(newRESTClientGetter(restConfig, namespace), namespace, "secret", func(format string, v ...interface{}) {
 		// Log function for Helm (can be customized)
 	}); err != nil {
 		return nil, fmt.Errorf("failed to initialize Helm action config: %w", err)
@@ -57,29 +64,36 @@ func NewClient(restConfig *rest.Config, kubeClient kubernetes.Interface, namespa
 		kubeClient:   kubeClient,
 		restConfig:   restConfig,
 		namespace:    namespace,
+		charts:       charts,
 	}, nil
 }
 
 // InstallOrUpgrade installs or upgrades a Helm chart
-func (c *Client) InstallOrUpgrade(ctx context.Context, releaseName, chartPath string, values map[string]interface{}) (*release.Release, error) {
+func (c *Client) InstallOrUpgrade(ctx context.Context, releaseName, chartName string, values map[string]interface{}) (*release.Release, error) {
+	// Load chart
+	chart, err := c.charts.Load(chartName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if release exists
 	histClient := action.NewHistory(c.actionConfig)
 	histClient.Max = 1
-	_, err := histClient.Run(releaseName)
+	_, err = histClient.Run(releaseName)
 
 	exists := err == nil
 
 	if exists {
 		// Upgrade existing release
-		return c.upgrade(ctx, releaseName, chartPath, values)
+		return c.upgrade(ctx, releaseName, chart, values)
 	}
 
 	// Install new release
-	return c.install(ctx, releaseName, chartPath, values)
+	return c.install(ctx, releaseName, chart, values)
 }
 
 // install installs a new Helm chart
-func (c *Client) install(ctx context.Context, releaseName, chartPath string, values map[string]interface{}) (*release.Release, error) {
+func (c *Client) install(ctx context.Context, releaseName string, chart *chart.Chart, values map[string]interface{}) (*release.Release, error) {
 	installAction := action.NewInstall(c.actionConfig)
 	installAction.ReleaseName = releaseName
 	installAction.Namespace = c.namespace
@@ -88,12 +102,6 @@ func (c *Client) install(ctx context.Context, releaseName, chartPath string, val
 	installAction.Wait = true
 	installAction.WaitForJobs = true
 
-	// Load chart
-	chart, err := loader.Load(chartPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load chart from path %s: %w", chartPath, err)
-	}
-
 	// Install
 	rel, err := installAction.RunWithContext(ctx, chart, values)
 	if err != nil {
@@ -105,19 +113,13 @@ func (c *Client) install(ctx context.Context, releaseName, chartPath string, val
 }
 
 // upgrade upgrades an existing Helm chart
-func (c *Client) upgrade(ctx context.Context, releaseName, chartPath string, values map[string]interface{}) (*release.Release, error) {
+func (c *Client) upgrade(ctx context.Context, releaseName string, chart *chart.Chart, values map[string]interface{}) (*release.Release, error) {
 	upgradeAction := action.NewUpgrade(c.actionConfig)
 	upgradeAction.Namespace = c.namespace
 	upgradeAction.Timeout = 10 * time.Minute // Augmenté pour Kibana qui peut prendre plus de temps
 	upgradeAction.Wait = true
 	upgradeAction.WaitForJobs = true
 
-	// Load chart
-	chart, err := loader.Load(chartPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load chart from path %s: %w", chartPath, err)
-	}
-
 	// Upgrade
 	rel, err := upgradeAction.RunWithContext(ctx, releaseName, chart, values)
 	if err != nil {
@@ -136,7 +138,7 @@ func (c *Client) Uninstall(ctx context.Context, releaseName string) error {
 
 	_, err := uninstallAction.Run(releaseName)
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if errors.IsNotFound(err) || stderrors.Is(err, driver.ErrReleaseNotFound) {
 			// Release not found, consider it already uninstalled
 			return nil
 		}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSpace is the space used by the API paths without /s/<space> prefix
+const DefaultSpace = "default"
+
+// Config holds the connection settings of a Kibana instance
+type Config struct {
+	// URL is the base URL of Kibana (e.g. http://efk-kibana.logging.svc:5601)
+	URL string
+	// Username and Password are used for basic authentication when set
+	Username string
+	Password string
+	// HTTPClient is used to send requests, a client with a default timeout is used when nil
+	HTTPClient *http.Client
+}
+
+// Client is a minimal Kibana REST API client used by the operator
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// Error is returned when Kibana answers with a non 2xx status code
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kibana %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if the error is a Kibana 404 response
+func IsNotFound(err error) bool {
+	var kbErr *Error
+	return errors.As(err, &kbErr) && kbErr.StatusCode == http.StatusNotFound
+}
+
+// NewClient creates a new Kibana client
+func NewClient(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		username:   cfg.Username,
+		password:   cfg.Password,
+		httpClient: httpClient,
+	}
+}
+
+// spacePath prefixes an API path with the space it applies to
+func spacePath(space, path string) string {
+	if space == "" || space == DefaultSpace {
+		return path
+	}
+	return "/s/" + url.PathEscape(space) + path
+}
+
+// do sends a JSON request and decodes the JSON response into out when it is not nil
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+		contentType = "application/json"
+	}
+	return c.send(ctx, method, path, contentType, reader, out)
+}
+
+// send sends a request with the given content type and decodes the JSON response into out
+// when it is not nil. Kibana rejects the requests changing its state without kbn-xsrf header
+func (c *Client) send(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("kbn-xsrf", "true")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("kibana %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &Error{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Path:       path,
+			Body:       string(respBody),
+		}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+		}
+	}
+
+	return nil
+}
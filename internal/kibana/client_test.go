@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibana
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestKibana(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Kibana Client Suite")
+}
+
+var _ = Describe("Kibana client", func() {
+	var (
+		ctx    context.Context
+		server *httptest.Server
+		mux    *http.ServeMux
+		client *Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		client = NewClient(Config{URL: server.URL, Username: "elastic", Password: "changeme"})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("When importing saved objects", func() {
+		It("Should upload the ndjson into the space", func() {
+			mux.HandleFunc("/s/team-a/api/saved_objects/_import", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				Expect(r.Header.Get("kbn-xsrf")).To(Equal("true"))
+				Expect(r.URL.Query().Get("overwrite")).To(Equal("true"))
+				user, _, _ := r.BasicAuth()
+				Expect(user).To(Equal("elastic"))
+
+				file, header, err := r.FormFile("file")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(header.Filename).To(Equal("export.ndjson"))
+				content, _ := io.ReadAll(file)
+				Expect(string(content)).To(Equal(`{"type":"index-pattern","id":"logs"}`))
+
+				_, _ = w.Write([]byte(`{"success":false,"successCount":1,
+					"successResults":[{"type":"index-pattern","id":"logs","meta":{"title":"logs-*"}}],
+					"errors":[{"type":"dashboard","id":"overview","meta":{"title":"Overview"},"error":{"type":"missing_references","references":[{"type":"search","id":"errors"}]}}]}`))
+			})
+
+			result, err := client.ImportSavedObjects(ctx, "team-a", []byte(`{"type":"index-pattern","id":"logs"}`), true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.SuccessResults).To(HaveLen(1))
+			Expect(result.SuccessResults[0].Meta.Title).To(Equal("logs-*"))
+			Expect(result.Errors[0].Error.Type).To(Equal("missing_references"))
+			Expect(result.Errors[0].Error.References).To(Equal([]SavedObjectReference{{Type: "search", ID: "errors"}}))
+		})
+
+		It("Should report the missing objects of the default space", func() {
+			mux.HandleFunc("/api/saved_objects/_bulk_get", func(w http.ResponseWriter, r *http.Request) {
+				var objects []SavedObjectReference
+				Expect(json.NewDecoder(r.Body).Decode(&objects)).To(Succeed())
+				Expect(objects).To(HaveLen(2))
+				_, _ = w.Write([]byte(`{"saved_objects":[{"type":"index-pattern","id":"logs","attributes":{}},
+					{"type":"dashboard","id":"overview","error":{"statusCode":404,"message":"Not found"}}]}`))
+			})
+
+			missing, err := client.MissingSavedObjects(ctx, DefaultSpace, []SavedObjectReference{
+				{Type: "index-pattern", ID: "logs"}, {Type: "dashboard", ID: "overview"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(missing).To(Equal([]SavedObjectReference{{Type: "dashboard", ID: "overview"}}))
+		})
+
+		It("Should return Kibana errors", func() {
+			mux.HandleFunc("/s/missing/api/saved_objects/_import", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			_, err := client.ImportSavedObjects(ctx, "missing", []byte("{}"), false)
+			Expect(IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When managing spaces", func() {
+		It("Should create, update and delete spaces", func() {
+			var bodies []map[string]interface{}
+			mux.HandleFunc("/api/spaces/space", func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodPost))
+				body := map[string]interface{}{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				bodies = append(bodies, body)
+			})
+			mux.HandleFunc("/api/spaces/space/team-a", func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					_, _ = w.Write([]byte(`{"id":"team-a","name":"Team A","disabledFeatures":["ml"]}`))
+				case http.MethodPut:
+					body := map[string]interface{}{}
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					bodies = append(bodies, body)
+				case http.MethodDelete:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			space, err := client.GetSpace(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(space.DisabledFeatures).To(Equal([]string{"ml"}))
+			Expect(client.CreateSpace(ctx, &Space{ID: "team-a", Name: "Team A", DisabledFeatures: []string{"ml"}})).To(Succeed())
+			Expect(client.UpdateSpace(ctx, &Space{ID: "team-a", Name: "Team A"})).To(Succeed())
+			Expect(bodies[0]["disabledFeatures"]).To(Equal([]interface{}{"ml"}))
+			// Les fonctionnalités désactivées auparavant sont réactivées
+			Expect(bodies[1]["disabledFeatures"]).To(Equal([]interface{}{}))
+			Expect(client.DeleteSpace(ctx, "team-a")).To(Succeed())
+		})
+
+		It("Should manage the default data view of a space", func() {
+			mux.HandleFunc("/s/team-a/api/data_views/data_view/team-a-logs", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"data_view":{"id":"team-a-logs","title":"team-a-*","timeFieldName":"@timestamp"}}`))
+			})
+			mux.HandleFunc("/s/team-a/api/data_views/data_view", func(w http.ResponseWriter, r *http.Request) {
+				body := map[string]interface{}{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body["override"]).To(BeTrue())
+				Expect(body["data_view"]).To(HaveKeyWithValue("title", "team-a-*"))
+			})
+			mux.HandleFunc("/s/team-a/api/data_views/default", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(`{"data_view_id":""}`))
+					return
+				}
+				body := map[string]interface{}{}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body).To(Equal(map[string]interface{}{"data_view_id": "team-a-logs", "force": true}))
+			})
+
+			dataView, err := client.GetDataView(ctx, "team-a", "team-a-logs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dataView.TimeFieldName).To(Equal("@timestamp"))
+			Expect(client.PutDataView(ctx, "team-a", &DataView{ID: "team-a-logs", Title: "team-a-*"})).To(Succeed())
+			current, err := client.GetDefaultDataView(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current).To(BeEmpty())
+			Expect(client.SetDefaultDataView(ctx, "team-a", "team-a-logs")).To(Succeed())
+		})
+	})
+})
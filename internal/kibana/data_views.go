@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibana
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// DataView is a Kibana data view (index pattern)
+type DataView struct {
+	ID            string `json:"id,omitempty"`
+	Title         string `json:"title"`
+	Name          string `json:"name,omitempty"`
+	TimeFieldName string `json:"timeFieldName,omitempty"`
+}
+
+// GetDataView returns the data view of a space with the given ID
+func (c *Client) GetDataView(ctx context.Context, space, id string) (*DataView, error) {
+	resp := struct {
+		DataView *DataView `json:"data_view"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, spacePath(space, "/api/data_views/data_view/"+url.PathEscape(id)), nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.DataView == nil {
+		return &DataView{}, nil
+	}
+	return resp.DataView, nil
+}
+
+// PutDataView creates a data view in a space, or replaces the data view with the same ID
+func (c *Client) PutDataView(ctx context.Context, space string, dataView *DataView) error {
+	body := map[string]interface{}{"data_view": dataView, "override": true}
+	return c.do(ctx, http.MethodPost, spacePath(space, "/api/data_views/data_view"), body, nil)
+}
+
+// GetDefaultDataView returns the ID of the default data view of a space, empty when unset
+func (c *Client) GetDefaultDataView(ctx context.Context, space string) (string, error) {
+	resp := struct {
+		DataViewID string `json:"data_view_id"`
+	}{}
+	if err := c.do(ctx, http.MethodGet, spacePath(space, "/api/data_views/default"), nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.DataViewID, nil
+}
+
+// SetDefaultDataView sets the default data view of a space, replacing the current one
+func (c *Client) SetDefaultDataView(ctx context.Context, space, id string) error {
+	body := map[string]interface{}{"data_view_id": id, "force": true}
+	return c.do(ctx, http.MethodPost, spacePath(space, "/api/data_views/default"), body, nil)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibana
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// SavedObjectReference identifies a saved object
+type SavedObjectReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// SavedObjectMeta holds the display information of a saved object
+type SavedObjectMeta struct {
+	Title string `json:"title,omitempty"`
+}
+
+// ImportResult is the answer of the saved objects import API
+type ImportResult struct {
+	Success        bool            `json:"success"`
+	SuccessCount   int             `json:"successCount"`
+	SuccessResults []ImportSuccess `json:"successResults,omitempty"`
+	Errors         []ImportError   `json:"errors,omitempty"`
+}
+
+// ImportSuccess is a saved object created or overwritten by an import
+type ImportSuccess struct {
+	Type          string          `json:"type"`
+	ID            string          `json:"id"`
+	DestinationID string          `json:"destinationId,omitempty"`
+	Meta          SavedObjectMeta `json:"meta"`
+}
+
+// ImportError is a saved object rejected by an import
+type ImportError struct {
+	Type  string            `json:"type"`
+	ID    string            `json:"id"`
+	Title string            `json:"title,omitempty"`
+	Meta  SavedObjectMeta   `json:"meta"`
+	Error ImportErrorDetail `json:"error"`
+}
+
+// ImportErrorDetail explains why a saved object was rejected
+type ImportErrorDetail struct {
+	// Type is conflict, ambiguous_conflict, unsupported_type, missing_references or unknown
+	Type       string                 `json:"type"`
+	Message    string                 `json:"message,omitempty"`
+	References []SavedObjectReference `json:"references,omitempty"`
+}
+
+// ImportSavedObjects imports the saved objects of an ndjson export into a space. With
+// overwrite, the existing objects with the same IDs are replaced
+func (c *Client) ImportSavedObjects(ctx context.Context, space string, ndjson []byte, overwrite bool) (*ImportResult, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "export.ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build import request: %w", err)
+	}
+	if _, err := part.Write(ndjson); err != nil {
+		return nil, fmt.Errorf("failed to build import request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build import request: %w", err)
+	}
+
+	result := &ImportResult{}
+	path := spacePath(space, "/api/saved_objects/_import?overwrite="+strconv.FormatBool(overwrite))
+	if err := c.send(ctx, http.MethodPost, path, writer.FormDataContentType(), body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MissingSavedObjects returns the saved objects of a space that do not exist
+func (c *Client) MissingSavedObjects(ctx context.Context, space string, objects []SavedObjectReference) ([]SavedObjectReference, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	resp := struct {
+		SavedObjects []struct {
+			SavedObjectReference
+			Error *struct {
+				StatusCode int `json:"statusCode"`
+			} `json:"error,omitempty"`
+		} `json:"saved_objects"`
+	}{}
+	if err := c.do(ctx, http.MethodPost, spacePath(space, "/api/saved_objects/_bulk_get"), objects, &resp); err != nil {
+		return nil, err
+	}
+
+	var missing []SavedObjectReference
+	for _, object := range resp.SavedObjects {
+		if object.Error != nil && object.Error.StatusCode == http.StatusNotFound {
+			missing = append(missing, object.SavedObjectReference)
+		}
+	}
+	return missing, nil
+}
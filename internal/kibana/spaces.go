@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kibana
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Space is a Kibana space
+type Space struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description,omitempty"`
+	Color            string   `json:"color,omitempty"`
+	Initials         string   `json:"initials,omitempty"`
+	DisabledFeatures []string `json:"disabledFeatures"`
+	// Reserved is set on the default space, which cannot be deleted
+	Reserved bool `json:"_reserved,omitempty"`
+}
+
+// GetSpace returns the space with the given ID
+func (c *Client) GetSpace(ctx context.Context, id string) (*Space, error) {
+	space := &Space{}
+	if err := c.do(ctx, http.MethodGet, "/api/spaces/space/"+url.PathEscape(id), nil, space); err != nil {
+		return nil, err
+	}
+	return space, nil
+}
+
+// CreateSpace creates a space
+func (c *Client) CreateSpace(ctx context.Context, space *Space) error {
+	return c.do(ctx, http.MethodPost, "/api/spaces/space", withDisabledFeatures(space), nil)
+}
+
+// UpdateSpace replaces the settings of an existing space
+func (c *Client) UpdateSpace(ctx context.Context, space *Space) error {
+	return c.do(ctx, http.MethodPut, "/api/spaces/space/"+url.PathEscape(space.ID), withDisabledFeatures(space), nil)
+}
+
+// DeleteSpace deletes a space and all its saved objects, a missing space is not an error
+func (c *Client) DeleteSpace(ctx context.Context, id string) error {
+	err := c.do(ctx, http.MethodDelete, "/api/spaces/space/"+url.PathEscape(id), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// withDisabledFeatures sends no disabled feature as an empty list, so that an update enables
+// the features disabled before
+func withDisabledFeatures(space *Space) *Space {
+	if space.DisabledFeatures != nil {
+		return space
+	}
+	out := *space
+	out.DisabledFeatures = []string{}
+	return &out
+}
@@ -35,6 +35,7 @@ import (
 
 	loggingv1 "github.com/zlorgoncho1/efk-operator/api/v1"
 	"github.com/zlorgoncho1/efk-operator/internal/controller"
+	"github.com/zlorgoncho1/efk-operator/internal/helm"
 	"k8s.io/client-go/kubernetes"
 	//+kubebuilder:scaffold:imports
 )
@@ -55,11 +56,21 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var chartDir string
+	var chartRepository string
+	var chartVersion string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&chartDir, "chart-dir", "",
+		"Directory containing the elasticsearch, fluentbit and kibana charts. "+
+			"Defaults to the charts embedded in the operator binary.")
+	flag.StringVar(&chartRepository, "chart-repository", "",
+		"OCI repository to pull the charts from (e.g. oci://registry.example.com/efk-charts). "+
+			"Takes precedence over --chart-dir.")
+	flag.StringVar(&chartVersion, "chart-version", "", "Version of the charts pulled from --chart-repository.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -68,7 +79,9 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	config := ctrl.GetConfigOrDie()
+
+	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
@@ -97,22 +110,100 @@ func main() {
 	}
 
 	// Create Kubernetes clientset for Helm
-	config := ctrl.GetConfigOrDie()
 	kubeClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		setupLog.Error(err, "unable to create kubernetes clientset")
 		os.Exit(1)
 	}
 
+	chartLoader, err := helm.NewChartLoader(chartDir, chartRepository, chartVersion)
+	if err != nil {
+		setupLog.Error(err, "unable to create chart loader")
+		os.Exit(1)
+	}
+
 	if err = (&controller.EFKStackReconciler{
-		Client:     mgr.GetClient(),
-		Scheme:     mgr.GetScheme(),
-		RestConfig: config,
-		KubeClient: kubeClient,
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		HelmClients: helm.NewClientCache(config, kubeClient, chartLoader),
+		RestConfig:  config,
+		KubeClient:  kubeClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EFKStack")
 		os.Exit(1)
 	}
+	if err = (&controller.FluentBitPipelineReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "FluentBitPipeline")
+		os.Exit(1)
+	}
+	if err = (&controller.SnapshotRestoreReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SnapshotRestore")
+		os.Exit(1)
+	}
+	if err = (&controller.ElasticsearchRoleReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchRole")
+		os.Exit(1)
+	}
+	if err = (&controller.ElasticsearchUserReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchUser")
+		os.Exit(1)
+	}
+	if err = (&controller.KibanaSavedObjectsReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KibanaSavedObjects")
+		os.Exit(1)
+	}
+	if err = (&controller.KibanaSpaceReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KibanaSpace")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&loggingv1.EFKStack{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EFKStack")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.FluentBitPipeline{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "FluentBitPipeline")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.SnapshotRestore{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SnapshotRestore")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.ElasticsearchRole{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ElasticsearchRole")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.ElasticsearchUser{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ElasticsearchUser")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.KibanaSavedObjects{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KibanaSavedObjects")
+			os.Exit(1)
+		}
+		if err = (&loggingv1.KibanaSpace{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KibanaSpace")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {